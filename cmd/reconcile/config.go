@@ -0,0 +1,32 @@
+// Package main ...
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/sethvargo/go-envconfig"
+)
+
+// Config ...
+type Config struct {
+	DatabaseURL string `env:"DATABASE_URL,required"`
+	LogLevel    string `env:"LOG_LEVEL,default=info"`
+}
+
+// parseConfig ...
+func parseConfig() (Config, error) {
+	if err := godotenv.Load("config.env"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, fmt.Errorf("load env variables from file: %w", err)
+	}
+
+	var c Config
+	if err := envconfig.Process(context.Background(), &c); err != nil {
+		return Config{}, fmt.Errorf("parse env variables to config: %w", err)
+	}
+
+	return c, nil
+}
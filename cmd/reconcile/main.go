@@ -0,0 +1,125 @@
+// Package main сверяет wallets.balance с журналом wallet_operations.
+//
+// Отчёт о расхождениях пишется в stdout (или в -out) в формате JSON Lines
+// или CSV. С флагом -fix для каждого расхождения записывается
+// корректирующая операция ADJUSTMENT. Код выхода 2 означает, что остались
+// неисправленные расхождения.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"wallet/internal/driver/sqlstore"
+	"wallet/internal/model"
+	"wallet/internal/repository"
+	"wallet/internal/usecase"
+	"wallet/pkg/logger"
+)
+
+func main() {
+	format := flag.String("format", "json", "report format: json or csv")
+	out := flag.String("out", "", "report file (default stdout)")
+	fix := flag.Bool("fix", false, "write ADJUSTMENT operations for mismatched wallets")
+	flag.Parse()
+
+	// --- Config ---
+	cfg, err := parseConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// --- Logger ---
+	log := logger.NewLogger(cfg.LogLevel)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	code, err := run(ctx, log, cfg, *format, *out, *fix)
+	if err != nil {
+		log.Error("reconcile failed", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	os.Exit(code)
+}
+
+// run ...
+func run(ctx context.Context, log *slog.Logger, cfg Config, format, out string, fix bool) (int, error) {
+	var w io.Writer = os.Stdout
+	if out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return 0, fmt.Errorf("create report file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	report, err := newReportWriter(format, w)
+	if err != nil {
+		return 0, err
+	}
+
+	// --- Database ---
+	store, err := sqlstore.New(ctx, cfg.DatabaseURL, sqlstore.DefaultPoolConfig())
+	if err != nil {
+		return 0, fmt.Errorf("connect to database: %w", err)
+	}
+	defer store.Close()
+
+	store.SetLogger(log)
+
+	uc := usecase.NewReconcile(repository.New(store.Pool()), store)
+
+	// Корректировки пишутся после сверки: внутри неё открыта read-only
+	// транзакция со снимком, и писать в ней нельзя.
+	var mismatches []model.LedgerBalance
+	summary, err := uc.Reconcile(ctx, func(b model.LedgerBalance) error {
+		mismatches = append(mismatches, b)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	unresolved := 0
+	for _, b := range mismatches {
+		rec := toRecord(b)
+		if fix {
+			op, err := uc.Adjust(ctx, b.WalletID)
+			if err != nil {
+				return 0, fmt.Errorf("adjust wallet %s: %w", b.WalletID, err)
+			}
+			if op.Amount != 0 {
+				rec.AdjustmentID = op.ID.String()
+				rec.Difference = op.Amount
+			}
+		} else {
+			unresolved++
+		}
+		if err := report.Write(rec); err != nil {
+			return 0, fmt.Errorf("write report: %w", err)
+		}
+	}
+	if err := report.Flush(); err != nil {
+		return 0, fmt.Errorf("write report: %w", err)
+	}
+
+	log.Info("reconciliation finished",
+		slog.Int("checked", summary.Checked),
+		slog.Int("mismatched", summary.Mismatched),
+		slog.Bool("fixed", fix),
+	)
+
+	if unresolved > 0 {
+		return 2, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,91 @@
+// Package main ...
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"wallet/internal/model"
+)
+
+// mismatchRecord одна строка отчёта.
+type mismatchRecord struct {
+	WalletID     string `json:"walletId"`
+	Balance      int64  `json:"balance"`
+	LedgerSum    int64  `json:"ledgerSum"`
+	Difference   int64  `json:"difference"`
+	AdjustmentID string `json:"adjustmentId,omitempty"`
+}
+
+// reportWriter ...
+type reportWriter interface {
+	Write(rec mismatchRecord) error
+	Flush() error
+}
+
+// newReportWriter ...
+func newReportWriter(format string, w io.Writer) (reportWriter, error) {
+	switch format {
+	case "json":
+		return &jsonReport{enc: json.NewEncoder(w)}, nil
+	case "csv":
+		return newCSVReport(w)
+	default:
+		return nil, fmt.Errorf("unknown format %q, want json or csv", format)
+	}
+}
+
+// toRecord ...
+func toRecord(b model.LedgerBalance) mismatchRecord {
+	return mismatchRecord{
+		WalletID:   b.WalletID.String(),
+		Balance:    b.Balance,
+		LedgerSum:  b.LedgerSum,
+		Difference: b.Difference(),
+	}
+}
+
+// jsonReport пишет по одному JSON-объекту на строку (JSON Lines), чтобы
+// отчёт можно было обрабатывать потоково.
+type jsonReport struct {
+	enc *json.Encoder
+}
+
+func (r *jsonReport) Write(rec mismatchRecord) error {
+	return r.enc.Encode(rec)
+}
+
+func (r *jsonReport) Flush() error {
+	return nil
+}
+
+// csvReport ...
+type csvReport struct {
+	w *csv.Writer
+}
+
+func newCSVReport(w io.Writer) (*csvReport, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"wallet_id", "balance", "ledger_sum", "difference", "adjustment_id"}); err != nil {
+		return nil, err
+	}
+	return &csvReport{w: cw}, nil
+}
+
+func (r *csvReport) Write(rec mismatchRecord) error {
+	return r.w.Write([]string{
+		rec.WalletID,
+		strconv.FormatInt(rec.Balance, 10),
+		strconv.FormatInt(rec.LedgerSum, 10),
+		strconv.FormatInt(rec.Difference, 10),
+		rec.AdjustmentID,
+	})
+}
+
+func (r *csvReport) Flush() error {
+	r.w.Flush()
+	return r.w.Error()
+}
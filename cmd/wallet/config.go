@@ -6,6 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"time"
+
+	"wallet/internal/usecase"
 
 	"github.com/joho/godotenv"
 	"github.com/sethvargo/go-envconfig"
@@ -13,9 +16,67 @@ import (
 
 // Config ...
 type Config struct {
-	DatabaseURL string `env:"DATABASE_URL,required"`
-	BindAddr    string `env:"BIND_ADDR,default=:8080"`
-	LogLevel    string `env:"LOG_LEVEL,default=info"`
+	// Storage где хранить данные: postgres, sqlite или memory. memory - для
+	// dev-режима и тестов, данные пропадают при остановке.
+	Storage     string `env:"STORAGE,default=postgres"`
+	DatabaseURL string `env:"DATABASE_URL"`
+	// SQLitePath файл базы для STORAGE=sqlite, создаётся при первом запуске.
+	SQLitePath string `env:"SQLITE_PATH,default=wallet.db"`
+
+	BindAddr string `env:"BIND_ADDR,default=:8080"`
+	LogLevel string `env:"LOG_LEVEL,default=info"`
+
+	IdempotencyTTL time.Duration `env:"IDEMPOTENCY_TTL,default=24h"`
+	MigrateOnStart bool          `env:"MIGRATE_ON_START,default=true"`
+	HoldTTL        time.Duration `env:"HOLD_TTL,default=15m"`
+
+	// IdempotencyCleanupInterval период удаления просроченных ключей
+	// идемпотентности, 0 - выключено.
+	IdempotencyCleanupInterval time.Duration `env:"IDEMPOTENCY_CLEANUP_INTERVAL,default=1m"`
+
+	// BalanceUpdate как менять баланс при пополнении и списании: locked или atomic.
+	BalanceUpdate string `env:"BALANCE_UPDATE,default=locked"`
+
+	// GroupCommit проводить одновременные операции одного кошелька общей транзакцией.
+	GroupCommit        bool          `env:"GROUP_COMMIT,default=false"`
+	GroupCommitWindow  time.Duration `env:"GROUP_COMMIT_WINDOW,default=2ms"`
+	GroupCommitMaxSize int           `env:"GROUP_COMMIT_MAX_SIZE,default=100"`
+
+	// ReconcileInterval период фоновой сверки балансов, 0 - выключена.
+	ReconcileInterval time.Duration `env:"RECONCILE_INTERVAL,default=0"`
+
+	// OutboxPublisher куда доставлять события: none, stdout, file или http.
+	OutboxPublisher string `env:"OUTBOX_PUBLISHER,default=none"`
+	// OutboxTarget путь к файлу для file или URL для http.
+	OutboxTarget       string        `env:"OUTBOX_TARGET"`
+	OutboxPollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL,default=1s"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE,default=100"`
+	OutboxMaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF,default=5m"`
+	// OutboxLease на сколько диспетчер забирает пачку событий.
+	OutboxLease time.Duration `env:"OUTBOX_LEASE,default=1m"`
+	// OutboxMaxAttempts после стольких неудач событие уходит в dead letter.
+	OutboxMaxAttempts int `env:"OUTBOX_MAX_ATTEMPTS,default=10"`
+	// OutboxRetention сколько хранить доставленные события.
+	OutboxRetention time.Duration `env:"OUTBOX_RETENTION,default=168h"`
+	// OutboxCleanupInterval период удаления доставленных событий старше
+	// OutboxRetention, 0 - выключено.
+	OutboxCleanupInterval time.Duration `env:"OUTBOX_CLEANUP_INTERVAL,default=1m"`
+
+	WebhookPollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL,default=1s"`
+	WebhookTimeout      time.Duration `env:"WEBHOOK_TIMEOUT,default=10s"`
+	// WebhookMaxAttempts после стольких неудач доставка уходит в DEAD.
+	WebhookMaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS,default=10"`
+	WebhookMaxBackoff  time.Duration `env:"WEBHOOK_MAX_BACKOFF,default=1h"`
+	// WebhookLease на сколько воркер забирает пачку доставок.
+	WebhookLease time.Duration `env:"WEBHOOK_LEASE,default=5m"`
+
+	// StreamHeartbeat как часто слать комментарий в открытый SSE-поток.
+	StreamHeartbeat time.Duration `env:"STREAM_HEARTBEAT,default=15s"`
+
+	// ShutdownDrainDelay сколько после SIGTERM ждать с проваленным /readyz,
+	// прежде чем останавливать сервер: за это время балансировщик выводит
+	// инстанс из ротации.
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY,default=5s"`
 }
 
 // parseConfig ...
@@ -33,8 +94,50 @@ func parseConfig() (Config, error) {
 		return Config{}, errors.New("var BIND_ADDRESS is required")
 	}
 
-	if c.DatabaseURL == "" {
-		return Config{}, errors.New("var DATABASE_URL is required")
+	switch c.Storage {
+	case storagePostgres:
+		if c.DatabaseURL == "" {
+			return Config{}, errors.New("var DATABASE_URL is required")
+		}
+	case storageSQLite:
+		if c.SQLitePath == "" {
+			return Config{}, errors.New("var SQLITE_PATH is required")
+		}
+	case storageMemory:
+	default:
+		return Config{}, fmt.Errorf("var STORAGE: unknown storage %q", c.Storage)
+	}
+
+	if _, err := usecase.ParseBalanceUpdateMode(c.BalanceUpdate); err != nil {
+		return Config{}, fmt.Errorf("var BALANCE_UPDATE: %w", err)
+	}
+
+	if c.GroupCommit && (c.GroupCommitWindow <= 0 || c.GroupCommitMaxSize <= 0) {
+		return Config{}, errors.New("vars GROUP_COMMIT_WINDOW and GROUP_COMMIT_MAX_SIZE must be positive")
+	}
+
+	if (c.OutboxPublisher == "file" || c.OutboxPublisher == "http") && c.OutboxTarget == "" {
+		return Config{}, fmt.Errorf("var OUTBOX_TARGET is required for OUTBOX_PUBLISHER=%s", c.OutboxPublisher)
+	}
+
+	if c.OutboxPollInterval <= 0 {
+		return Config{}, errors.New("var OUTBOX_POLL_INTERVAL must be positive")
+	}
+
+	if c.OutboxMaxAttempts <= 0 {
+		return Config{}, errors.New("var OUTBOX_MAX_ATTEMPTS must be positive")
+	}
+
+	if c.OutboxRetention <= 0 {
+		return Config{}, errors.New("var OUTBOX_RETENTION must be positive")
+	}
+
+	if c.WebhookPollInterval <= 0 {
+		return Config{}, errors.New("var WEBHOOK_POLL_INTERVAL must be positive")
+	}
+
+	if c.StreamHeartbeat <= 0 {
+		return Config{}, errors.New("var STREAM_HEARTBEAT must be positive")
 	}
 
 	return c, nil
@@ -9,10 +9,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
-	"wallet/internal/driver/sqlstore"
+	"wallet/internal/driver/metrics"
+	"wallet/internal/driver/notify"
+	"wallet/internal/driver/publisher"
+	"wallet/internal/driver/webhook"
 	"wallet/internal/port"
 	"wallet/internal/port/handler"
 	"wallet/internal/port/middleware"
@@ -35,33 +39,179 @@ func main() {
 
 	log.Info("starting service")
 
-	// --- Database ---
+	// --- Storage ---
 	ctx := context.Background()
 
-	pcfg := sqlstore.DefaultPoolConfig()
+	// Уведомления о коммитах раздаются SSE-потокам через hub.
+	hub := notify.NewHub()
 
-	store, err := sqlstore.New(ctx, cfg.DatabaseURL, pcfg)
+	st, err := openStorage(ctx, log, cfg, hub)
 	if err != nil {
-		log.Error("failed to connect to database", slog.String("err", err.Error()))
+		log.Error("failed to open storage", slog.String("storage", cfg.Storage), slog.String("err", err.Error()))
 		os.Exit(1)
 	}
-	defer store.Close()
+	defer st.Close()
 
-	log.Info("database connected")
+	log.Info("storage opened", slog.String("storage", cfg.Storage))
 
-	repo := repository.New(store.Pool())
-	uc := usecase.New(repo, store)
+	if len(os.Args) > 1 {
+		if os.Args[1] != "migrate" {
+			log.Error("unknown command", slog.String("command", os.Args[1]))
+			os.Exit(2)
+		}
+		if st.sql == nil {
+			log.Error("migrate requires STORAGE=postgres")
+			os.Exit(2)
+		}
+		if err := runMigrate(ctx, log, st.sql, os.Args[2:]); err != nil {
+			log.Error("migration failed", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.MigrateOnStart && st.sql != nil {
+		if err := runMigrate(ctx, log, st.sql, []string{"up"}); err != nil {
+			log.Error("migration failed", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	// --- Metrics ---
+	appMetrics := metrics.New()
+	if st.sql != nil {
+		st.sql.SetMetrics(appMetrics)
+		if err := appMetrics.RegisterPool(st.sql); err != nil {
+			log.Error("failed to register pool metrics", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	repo, txm := st.repo, st.txm
+	uc := usecase.New(repo, txm,
+		usecase.WithMetrics(appMetrics),
+		usecase.WithIdempotencyTTL(cfg.IdempotencyTTL),
+		usecase.WithHoldTTL(cfg.HoldTTL),
+		usecase.WithBalanceUpdate(usecase.BalanceUpdateMode(cfg.BalanceUpdate)),
+	)
+
+	var walletUC handler.WalletUsecase = uc
+	if cfg.GroupCommit {
+		walletUC = usecase.NewGroupCommit(uc,
+			usecase.WithGroupCommitWindow(cfg.GroupCommitWindow),
+			usecase.WithGroupCommitMaxSize(cfg.GroupCommitMaxSize),
+		)
+	}
+
+	jobCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+
+	var jobs sync.WaitGroup
+	if cfg.ReconcileInterval > 0 {
+		jobs.Go(func() {
+			runReconcileJob(jobCtx, log, usecase.NewReconcile(repo, txm), cfg.ReconcileInterval)
+		})
+	}
+
+	if cfg.IdempotencyCleanupInterval > 0 {
+		cleanup := usecase.NewIdempotencyCleanup(repo, usecase.DefaultIdempotencyCleanupBatchSize)
+		jobs.Go(func() {
+			runQueueJob(jobCtx, log, "idempotency-cleanup", cfg.IdempotencyCleanupInterval, cleanup.BatchSize(), cleanup.Purge)
+		})
+	}
+
+	webhooks := usecase.NewWebhook(repo, txm,
+		webhook.NewSender(&http.Client{Timeout: cfg.WebhookTimeout}),
+		usecase.WithWebhookMaxAttempts(cfg.WebhookMaxAttempts),
+		usecase.WithWebhookBackoff(0, cfg.WebhookMaxBackoff),
+		usecase.WithWebhookLease(cfg.WebhookLease),
+	)
+	jobs.Go(func() {
+		runQueueJob(jobCtx, log, "webhooks", cfg.WebhookPollInterval, webhooks.BatchSize(), webhooks.DeliverPending)
+	})
+	// Подписки вебхуков получают события своим курсором, а не через outbox:
+	// сбой внешнего publisher не задерживает их.
+	jobs.Go(func() {
+		runQueueJob(jobCtx, log, "webhook-fanout", cfg.WebhookPollInterval, webhooks.BatchSize(), webhooks.EnqueuePending)
+	})
+
+	// Без внешнего publisher outbox просто отмечает события доставленными,
+	// чтобы их могла удалить очистка.
+	var publishers []usecase.Publisher
+	pub, pubCloser, err := newPublisher(cfg)
+	if err != nil {
+		log.Error("failed to create outbox publisher", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+	if pubCloser != nil {
+		defer pubCloser.Close()
+	}
+	if pub != nil {
+		publishers = append(publishers, pub)
+	}
+
+	outbox := usecase.NewOutbox(repo, txm, publisher.NewMulti(publishers...),
+		usecase.WithOutboxBatchSize(cfg.OutboxBatchSize),
+		usecase.WithOutboxBackoff(0, cfg.OutboxMaxBackoff),
+		usecase.WithOutboxLease(cfg.OutboxLease),
+		usecase.WithOutboxMaxAttempts(cfg.OutboxMaxAttempts),
+	)
+	jobs.Go(func() {
+		runQueueJob(jobCtx, log, "outbox", cfg.OutboxPollInterval, outbox.BatchSize(), outbox.Dispatch)
+	})
+
+	if cfg.OutboxCleanupInterval > 0 {
+		cleanup := usecase.NewOutboxCleanup(repo, cfg.OutboxRetention, usecase.DefaultOutboxCleanupBatchSize)
+		jobs.Go(func() {
+			runQueueJob(jobCtx, log, "outbox-cleanup", cfg.OutboxCleanupInterval, cleanup.BatchSize(), cleanup.Purge)
+		})
+	}
+
+	// В postgres уведомления о коммитах приходят через LISTEN на отдельном
+	// соединении.
+	if st.sql != nil {
+		jobs.Go(func() {
+			notify.NewListener(st.sql.Pool(), repository.WalletEventsChannel, hub, log).Run(jobCtx)
+		})
+	}
+
+	// SSE-потоки не завершаются сами: Shutdown закрывает их через streamCtx,
+	// иначе он ждал бы их до таймаута.
+	streamCtx, stopStreams := context.WithCancel(ctx)
+	defer stopStreams()
 
 	serverAPI := port.NewServer(log)
-	walletHandler := handler.NewWalletHandler(uc, serverAPI)
+	walletHandler := handler.NewWalletHandler(walletUC, serverAPI)
+	webhookHandler := handler.NewWebhookHandler(webhooks, serverAPI)
+	streamHandler := handler.NewStreamHandler(usecase.NewStream(repo, hub), serverAPI, cfg.StreamHeartbeat, streamCtx.Done())
+	healthHandler := handler.NewHealthHandler(st, serverAPI)
 
 	mux := http.NewServeMux()
 
 	mux.Handle("POST /api/v1/wallet", walletHandler.HandleOperation())
+	mux.Handle("POST /api/v1/wallets", walletHandler.HandleCreateWallet())
+	mux.Handle("POST /api/v1/transfers", walletHandler.HandleTransfer())
 	mux.Handle("GET /api/v1/wallets/{id}", walletHandler.HandleGetBalance())
+	mux.Handle("GET /api/v1/wallets/{id}/operations", walletHandler.HandleListOperations())
+	mux.Handle("GET /api/v1/operations/{id}", walletHandler.HandleGetOperation())
+	mux.Handle("POST /api/v1/operations:batch", walletHandler.HandleBatch())
+	mux.Handle("GET /api/v1/wallets/{id}/events", streamHandler.HandleWalletEvents())
+	mux.Handle("POST /api/v1/wallets/{id}/holds", walletHandler.HandleCreateHold())
+	mux.Handle("POST /api/v1/wallets/{id}/holds/{holdId}/capture", walletHandler.HandleCaptureHold())
+	mux.Handle("POST /api/v1/wallets/{id}/holds/{holdId}/void", walletHandler.HandleVoidHold())
+	mux.Handle("POST /api/v1/webhooks", webhookHandler.HandleCreateWebhook())
+	mux.Handle("GET /api/v1/webhooks", webhookHandler.HandleListWebhooks())
+	mux.Handle("DELETE /api/v1/webhooks/{id}", webhookHandler.HandleDeleteWebhook())
+	mux.Handle("GET /api/v1/webhooks/{id}/attempts", webhookHandler.HandleListWebhookAttempts())
+	mux.Handle("GET /metrics", appMetrics.Handler())
+	mux.Handle("GET /healthz", healthHandler.HandleLiveness())
+	mux.Handle("GET /readyz", healthHandler.HandleReadiness())
 
 	middleware.Use(middleware.RequestID)
 	middleware.Use(middleware.CORS)
+	// Metrics последним: маршрут берётся из шаблона, который mux записывает
+	// в свой *http.Request.
+	middleware.Use(middleware.Metrics(appMetrics))
 	httpHandler := middleware.Apply(mux)
 
 	srv := &http.Server{
@@ -71,6 +221,7 @@ func main() {
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	srv.RegisterOnShutdown(stopStreams)
 
 	serverErr := make(chan error, 1)
 	go func() {
@@ -91,6 +242,19 @@ func main() {
 		log.Info("shutdown signal received", slog.String("signal", sig.String()))
 	}
 
+	// /readyz падает сразу, а сервер ещё ShutdownDrainDelay принимает
+	// запросы: балансировщик успевает вывести инстанс из ротации, и
+	// Shutdown не обрывает новые подключения. Повторный сигнал не ждёт.
+	healthHandler.Drain()
+	if cfg.ShutdownDrainDelay > 0 {
+		log.Info("draining traffic", slog.Duration("delay", cfg.ShutdownDrainDelay))
+		select {
+		case <-time.After(cfg.ShutdownDrainDelay):
+		case sig := <-quit:
+			log.Info("second shutdown signal received, skipping drain", slog.String("signal", sig.String()))
+		}
+	}
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -99,5 +263,8 @@ func main() {
 		os.Exit(1)
 	}
 
+	stopJobs()
+	jobs.Wait()
+
 	log.Info("service stopped")
 }
@@ -0,0 +1,109 @@
+// Package main ...
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"wallet/internal/driver/migrate"
+	"wallet/internal/driver/sqlstore"
+	"wallet/migration"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const migrateUsage = "usage: wallet migrate up|down|status|goto N|force N"
+
+// legacySchema проверки схемы, созданной скриптами init/ до появления
+// миграций: i-я проверка истинна, если миграция i+1 уже отражена в схеме.
+// Скрипты init/ создавали только wallets и wallet_operations.
+var legacySchema = []string{
+	`SELECT to_regclass('public.wallets') IS NOT NULL`,
+	`SELECT to_regclass('public.wallet_operations') IS NOT NULL`,
+}
+
+// detectLegacySchema версия, до которой доведена схема без истории
+// миграций: число подряд выполненных проверок legacySchema.
+func detectLegacySchema(ctx context.Context, conn *pgx.Conn) (int64, error) {
+	var version int64
+	for _, query := range legacySchema {
+		var ok bool
+		if err := conn.QueryRow(ctx, query).Scan(&ok); err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		version++
+	}
+	return version, nil
+}
+
+// runMigrate ...
+func runMigrate(ctx context.Context, log *slog.Logger, store *sqlstore.Store, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	m, err := migrate.New(store.Pool(), migration.FS, log)
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+
+	// База из init/ уже содержит часть схемы: без baseline 0001 упала бы на
+	// CREATE TABLE wallets.
+	if args[0] == "up" || args[0] == "goto" {
+		if err := m.Baseline(ctx, detectLegacySchema); err != nil {
+			return err
+		}
+	}
+
+	switch args[0] {
+	case "up":
+		return m.Up(ctx)
+	case "down":
+		return m.Down(ctx)
+	case "goto":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse version %q: %w", args[1], err)
+		}
+		return m.Goto(ctx, version)
+	case "force":
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse version %q: %w", args[1], err)
+		}
+		return m.Force(ctx, version)
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, st := range statuses {
+			status, appliedAt := "pending", "-"
+			if st.Applied {
+				status, appliedAt = "applied", st.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", st.Version, st.Name, status, appliedAt)
+		}
+		return tw.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
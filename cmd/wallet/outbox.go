@@ -0,0 +1,35 @@
+// Package main ...
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"wallet/internal/driver/publisher"
+	"wallet/internal/usecase"
+)
+
+const outboxHTTPTimeout = 10 * time.Second
+
+// newPublisher собирает внешний Publisher по конфигу. Для none возвращает
+// nil: события получают только подписчики вебхуков.
+func newPublisher(cfg Config) (usecase.Publisher, io.Closer, error) {
+	switch cfg.OutboxPublisher {
+	case "none":
+		return nil, nil, nil
+	case "stdout":
+		return publisher.NewWriter(os.Stdout), nil, nil
+	case "file":
+		f, err := os.OpenFile(cfg.OutboxTarget, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open outbox file: %w", err)
+		}
+		return publisher.NewWriter(f), f, nil
+	case "http":
+		return publisher.NewHTTP(cfg.OutboxTarget, outboxHTTPTimeout), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown outbox publisher %q", cfg.OutboxPublisher)
+	}
+}
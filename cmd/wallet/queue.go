@@ -0,0 +1,48 @@
+// Package main ...
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// runQueueJob разбирает очередь вызовами step, пока ctx не отменён. step
+// обрабатывает одну пачку и возвращает её размер. Полная пачка значит, что
+// за ней есть ещё работа, и следующая берётся сразу; иначе job ждёт interval.
+func runQueueJob(
+	ctx context.Context, log *slog.Logger, job string, interval time.Duration, batchSize int,
+	step func(context.Context) (int, error),
+) {
+	log = log.With(slog.String("job", job))
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		n, err := step(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn("batch failed",
+				slog.Int("processed", n),
+				slog.String("err", err.Error()),
+			)
+		} else if n > 0 {
+			log.Debug("batch processed", slog.Int("processed", n))
+		}
+
+		if err == nil && n == batchSize {
+			timer.Reset(0)
+			continue
+		}
+		timer.Reset(interval)
+	}
+}
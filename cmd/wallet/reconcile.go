@@ -0,0 +1,51 @@
+// Package main ...
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+)
+
+// runReconcileJob периодически сверяет балансы с журналом операций и пишет
+// расхождения в лог. Ничего не исправляет: для этого есть cmd/reconcile -fix.
+// Возвращается, когда ctx отменён.
+func runReconcileJob(ctx context.Context, log *slog.Logger, uc *usecase.ReconcileUsecase, interval time.Duration) {
+	log = log.With(slog.String("job", "reconcile"))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		summary, err := uc.Reconcile(ctx, func(b model.LedgerBalance) error {
+			log.Warn("balance mismatch",
+				slog.String("wallet_id", b.WalletID.String()),
+				slog.Int64("balance", b.Balance),
+				slog.Int64("ledger_sum", b.LedgerSum),
+				slog.Int64("difference", b.Difference()),
+			)
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Error("reconciliation failed", slog.String("err", err.Error()))
+			continue
+		}
+
+		log.Info("reconciliation finished",
+			slog.Int("checked", summary.Checked),
+			slog.Int("mismatched", summary.Mismatched),
+		)
+	}
+}
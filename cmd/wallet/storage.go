@@ -0,0 +1,151 @@
+// Package main ...
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"wallet/internal/driver/memstore"
+	"wallet/internal/driver/migrate"
+	"wallet/internal/driver/notify"
+	"wallet/internal/driver/sqlitestore"
+	"wallet/internal/driver/sqlstore"
+	"wallet/internal/model"
+	"wallet/internal/repository"
+	"wallet/internal/repository/memory"
+	"wallet/internal/repository/sqlite"
+	"wallet/internal/usecase"
+	"wallet/migration"
+)
+
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+	storageSQLite   = "sqlite"
+)
+
+// Repository все репозитории сервиса. Каждое хранилище реализует их одним типом.
+type Repository interface {
+	usecase.WalletRepository
+	usecase.IdempotencyRepository
+	usecase.ReconcileRepository
+	usecase.OutboxRepository
+	usecase.WebhookRepository
+	usecase.EventRepository
+}
+
+// storage хранилище, выбранное STORAGE.
+type storage struct {
+	name string
+	repo Repository
+	txm  usecase.TxManager
+	// sql задан только для postgres: миграции и LISTEN работают с ним.
+	sql      *sqlstore.Store
+	migrator *migrate.Migrator
+	sqlite   *sqlitestore.Store
+}
+
+// openStorage подключает хранилище. В memory и sqlite уведомления о
+// кошельках идут в hub напрямую, в postgres - через LISTEN, который
+// запускает main. Схема sqlite встроена в бинарь и применяется при открытии.
+func openStorage(ctx context.Context, log *slog.Logger, cfg Config, hub *notify.Hub) (*storage, error) {
+	switch cfg.Storage {
+	case storagePostgres:
+		store, err := sqlstore.New(ctx, cfg.DatabaseURL, sqlstore.DefaultPoolConfig())
+		if err != nil {
+			return nil, fmt.Errorf("connect to database: %w", err)
+		}
+		store.SetLogger(log)
+
+		migrator, err := migrate.New(store.Pool(), migration.FS, log)
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+
+		return &storage{name: cfg.Storage, repo: repository.New(store.Pool()), txm: store, sql: store, migrator: migrator}, nil
+	case storageMemory:
+		store := memstore.New(memstore.DefaultConfig())
+		store.SetLogger(log)
+
+		repo := memory.New(store)
+		repo.SetNotifier(hub)
+
+		return &storage{name: cfg.Storage, repo: repo, txm: store}, nil
+	case storageSQLite:
+		store, err := sqlitestore.New(ctx, cfg.SQLitePath, sqlitestore.DefaultConfig())
+		if err != nil {
+			return nil, fmt.Errorf("open sqlite database: %w", err)
+		}
+		store.SetLogger(log)
+
+		if err := sqlite.Migrate(ctx, store.DB()); err != nil {
+			store.Close()
+			return nil, fmt.Errorf("migrate sqlite database: %w", err)
+		}
+
+		repo := sqlite.New(store)
+		repo.SetNotifier(hub)
+
+		return &storage{name: cfg.Storage, repo: repo, txm: store, sqlite: store}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q", cfg.Storage)
+	}
+}
+
+// Readiness проверяет, что хранилище отвечает, и отдаёт версию схемы и
+// загрузку пула соединений. У memory проверять нечего.
+func (s *storage) Readiness(ctx context.Context) (model.Readiness, error) {
+	r := model.Readiness{Storage: s.name}
+
+	switch {
+	case s.sql != nil:
+		if err := s.sql.Ping(ctx); err != nil {
+			return model.Readiness{}, fmt.Errorf("ping database: %w", err)
+		}
+		version, err := s.migrator.Version(ctx)
+		if err != nil {
+			return model.Readiness{}, err
+		}
+		stat := s.sql.Stat()
+
+		r.MigrationVersion = version
+		r.Pool = &model.PoolStats{
+			AcquiredConns: stat.AcquiredConns(),
+			IdleConns:     stat.IdleConns(),
+			TotalConns:    stat.TotalConns(),
+			MaxConns:      stat.MaxConns(),
+		}
+	case s.sqlite != nil:
+		db := s.sqlite.DB()
+		if err := db.PingContext(ctx); err != nil {
+			return model.Readiness{}, fmt.Errorf("ping sqlite database: %w", err)
+		}
+		version, err := sqlite.SchemaVersion(ctx, db)
+		if err != nil {
+			return model.Readiness{}, err
+		}
+		stat := db.Stats()
+
+		r.MigrationVersion = version
+		r.Pool = &model.PoolStats{
+			AcquiredConns: int32(stat.InUse),
+			IdleConns:     int32(stat.Idle),
+			TotalConns:    int32(stat.OpenConnections),
+			MaxConns:      int32(stat.MaxOpenConnections),
+		}
+	}
+
+	return r, nil
+}
+
+// Close ...
+func (s *storage) Close() {
+	if s.sql != nil {
+		s.sql.Close()
+	}
+	if s.sqlite != nil {
+		s.sqlite.Close()
+	}
+}
@@ -1,6 +0,0 @@
-CREATE TABLE wallets (
-    id UUID PRIMARY KEY,
-    balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
-    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
-    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
-);
\ No newline at end of file
@@ -1,2 +0,0 @@
-INSERT INTO wallets (id, balance)
-VALUES ('11111111-1111-1111-1111-111111111111', 100000);
\ No newline at end of file
@@ -0,0 +1,331 @@
+// Package memstore ...
+package memstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	walleterror "wallet/internal/error"
+	"wallet/internal/usecase"
+)
+
+// ErrDeadlock транзакция ждала бы блокировку, которую держит транзакция,
+// ждущая её саму. RunInTx повторяет такие транзакции, как sqlstore - 40P01.
+var ErrDeadlock = errors.New("deadlock detected")
+
+// Config ...
+type Config struct {
+	// TxMaxAttempts ...
+	TxMaxAttempts int
+	// TxRetryBaseDelay ...
+	TxRetryBaseDelay time.Duration
+	// TxRetryMaxDelay ...
+	TxRetryMaxDelay time.Duration
+}
+
+// DefaultConfig ...
+func DefaultConfig() Config {
+	return Config{
+		TxMaxAttempts:    3,
+		TxRetryBaseDelay: 10 * time.Millisecond,
+		TxRetryMaxDelay:  500 * time.Millisecond,
+	}
+}
+
+// Store TxManager для хранилища в памяти. Данные держит репозиторий, а
+// Store - транзакции: блокировки строк до конца транзакции, журнал отмены
+// изменений и действия после коммита.
+//
+// Уровень изоляции один на все транзакции: записи берут блокировку строки,
+// чтения не блокируются. Чтобы чужие транзакции не видели незакоммиченные
+// изменения, данные хранятся в Table.
+type Store struct {
+	cfg    Config
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	locks map[any]*lock
+}
+
+type lock struct {
+	owner    *tx
+	released chan struct{}
+}
+
+// tx транзакция верхнего уровня. Вложенные вызовы RunInTx работают в ней
+// же, запоминая позицию в журналах как точку сохранения.
+type tx struct {
+	// waitsFor транзакция, чью блокировку сейчас ждёт tx. Защищено Store.mu.
+	waitsFor *tx
+	held     []any
+
+	undo []func()
+	// publish делают изменения видимыми другим транзакциям.
+	publish  []publishFunc
+	onCommit []func()
+}
+
+// publishFunc выполняется при коммите под mu вместе с остальными
+// publishFunc с тем же mu.
+type publishFunc struct {
+	mu sync.Locker
+	fn func()
+}
+
+type txKey struct{}
+
+func extractTx(ctx context.Context) (*tx, bool) {
+	t, ok := ctx.Value(txKey{}).(*tx)
+	return t, ok
+}
+
+// New ...
+func New(cfg Config) *Store {
+	return &Store{
+		cfg:    cfg,
+		logger: slog.Default(),
+		locks:  make(map[any]*lock),
+	}
+}
+
+// SetLogger ...
+func (s *Store) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// RunInTx выполняет fn в транзакции. Ошибка fn отменяет все изменения,
+// записанные через OnRollback. При дедлоке транзакция откатывается и fn
+// выполняется заново, поэтому fn не должна иметь побочных эффектов вне
+// хранилища.
+//
+// Если в ctx уже есть транзакция, fn выполняется внутри неё как под
+// SAVEPOINT: ошибка fn отменяет только изменения вложенного вызова.
+// Уровень изоляции и режим доступа из opts не учитываются.
+func (s *Store) RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts ...usecase.TxOption) error {
+	if t, ok := extractTx(ctx); ok {
+		return runNested(ctx, t, fn)
+	}
+
+	o := usecase.TxOptions{MaxAttempts: s.cfg.TxMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := s.runOnce(ctx, fn)
+		if err == nil {
+			if attempt > 1 {
+				s.logger.Info("transaction succeeded after retry",
+					slog.Int("attempt", attempt),
+				)
+			}
+			return nil
+		}
+
+		if !errors.Is(err, ErrDeadlock) {
+			return err
+		}
+
+		if attempt >= o.MaxAttempts {
+			s.logger.Warn("transaction retries exhausted",
+				slog.Int("attempt", attempt),
+				slog.String("err", err.Error()),
+			)
+			return fmt.Errorf("%w: %w", walleterror.ErrTxConflict, err)
+		}
+
+		delay := s.backoff(attempt)
+		s.logger.Warn("retrying transaction",
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", o.MaxAttempts),
+			slog.Duration("delay", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *Store) runOnce(ctx context.Context, fn func(ctx context.Context) error) error {
+	t := &tx{}
+	defer s.release(t)
+
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		rollback(t, 0)
+		return err
+	}
+
+	publish(t)
+
+	// Блокировки отпускаются после действий коммита: уведомление о
+	// кошельке уходит раньше, чем его сможет изменить следующая транзакция.
+	for _, f := range t.onCommit {
+		f()
+	}
+
+	return nil
+}
+
+// publish делает изменения t видимыми. Изменения под одним mu появляются
+// разом: читатель не увидит половину транзакции.
+func publish(t *tx) {
+	for len(t.publish) > 0 {
+		mu := t.publish[0].mu
+		rest := t.publish[:0]
+
+		mu.Lock()
+		for _, p := range t.publish {
+			if p.mu != mu {
+				rest = append(rest, p)
+				continue
+			}
+			p.fn()
+		}
+		mu.Unlock()
+
+		t.publish = rest
+	}
+}
+
+// runNested ...
+func runNested(ctx context.Context, t *tx, fn func(ctx context.Context) error) error {
+	undoMark, publishMark, commitMark := len(t.undo), len(t.publish), len(t.onCommit)
+
+	if err := fn(ctx); err != nil {
+		rollback(t, undoMark)
+		t.publish = t.publish[:publishMark]
+		t.onCommit = t.onCommit[:commitMark]
+		return err
+	}
+
+	return nil
+}
+
+// rollback отменяет изменения, записанные после mark, в обратном порядке.
+func rollback(t *tx, mark int) {
+	for i := len(t.undo) - 1; i >= mark; i-- {
+		t.undo[i]()
+	}
+	t.undo = t.undo[:mark]
+}
+
+// OnRollback запоминает, как отменить изменение, сделанное в транзакции ctx.
+// Вне транзакции изменение сразу окончательно, и undo не нужен.
+func OnRollback(ctx context.Context, undo func()) {
+	if t, ok := extractTx(ctx); ok {
+		t.undo = append(t.undo, undo)
+	}
+}
+
+// AfterCommit выполняет fn после коммита транзакции ctx, а вне транзакции -
+// сразу. При откате fn не выполняется.
+func AfterCommit(ctx context.Context, fn func()) {
+	if t, ok := extractTx(ctx); ok {
+		t.onCommit = append(t.onCommit, fn)
+		return
+	}
+	fn()
+}
+
+// Lock блокирует строку key до конца транзакции ctx, как SELECT ... FOR
+// UPDATE. Повторная блокировка той же транзакцией ничего не делает. Вне
+// транзакции Lock только дожидается, пока строку отпустят.
+func (s *Store) Lock(ctx context.Context, key any) error {
+	t, inTx := extractTx(ctx)
+
+	for {
+		s.mu.Lock()
+		l, locked := s.locks[key]
+		if !locked {
+			if inTx {
+				s.locks[key] = &lock{owner: t, released: make(chan struct{})}
+				t.held = append(t.held, key)
+			}
+			s.mu.Unlock()
+			return nil
+		}
+		if l.owner == t {
+			s.mu.Unlock()
+			return nil
+		}
+		if inTx {
+			// Цепочка ожиданий от владельца вернулась к нам - дедлок.
+			for w := l.owner; w != nil; w = w.waitsFor {
+				if w == t {
+					s.mu.Unlock()
+					return ErrDeadlock
+				}
+			}
+			t.waitsFor = l.owner
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-l.released:
+		case <-ctx.Done():
+		}
+
+		if inTx {
+			s.mu.Lock()
+			t.waitsFor = nil
+			s.mu.Unlock()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// TryLock как Lock, но занятую другой транзакцией строку не ждёт и
+// возвращает false, как FOR UPDATE SKIP LOCKED.
+func (s *Store) TryLock(ctx context.Context, key any) bool {
+	t, inTx := extractTx(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, locked := s.locks[key]
+	if locked {
+		return l.owner == t
+	}
+	if inTx {
+		s.locks[key] = &lock{owner: t, released: make(chan struct{})}
+		t.held = append(t.held, key)
+	}
+	return true
+}
+
+func (s *Store) release(t *tx) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range t.held {
+		close(s.locks[key].released)
+		delete(s.locks, key)
+	}
+	t.held = nil
+}
+
+// backoff экспоненциальная задержка с джиттером в диапазоне [d/2, d].
+func (s *Store) backoff(attempt int) time.Duration {
+	d := s.cfg.TxRetryBaseDelay << (attempt - 1)
+	if d <= 0 || d > s.cfg.TxRetryMaxDelay {
+		d = s.cfg.TxRetryMaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
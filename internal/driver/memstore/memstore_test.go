@@ -0,0 +1,219 @@
+// Package memstore_test ...
+package memstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"wallet/internal/driver/memstore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func newStore() *memstore.Store {
+	cfg := memstore.DefaultConfig()
+	cfg.TxRetryBaseDelay = time.Millisecond
+	cfg.TxRetryMaxDelay = 5 * time.Millisecond
+	return memstore.New(cfg)
+}
+
+func TestRunInTx_RollbackUndoesChanges(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	value := 0
+	committed := false
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		value = 1
+		memstore.OnRollback(ctx, func() { value = 0 })
+		memstore.AfterCommit(ctx, func() { committed = true })
+		return errTest
+	})
+
+	require.ErrorIs(t, err, errTest)
+	assert.Equal(t, 0, value)
+	assert.False(t, committed)
+}
+
+func TestRunInTx_CommitRunsAfterCommit(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	committed := false
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		memstore.AfterCommit(ctx, func() { committed = true })
+		assert.False(t, committed)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, committed)
+}
+
+func TestRunInTx_NestedRollsBackOnlyItself(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	var outer, inner int
+	var notified []string
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		outer = 1
+		memstore.OnRollback(ctx, func() { outer = 0 })
+		memstore.AfterCommit(ctx, func() { notified = append(notified, "outer") })
+
+		nestedErr := store.RunInTx(ctx, func(ctx context.Context) error {
+			inner = 1
+			memstore.OnRollback(ctx, func() { inner = 0 })
+			memstore.AfterCommit(ctx, func() { notified = append(notified, "inner") })
+			return errTest
+		})
+		assert.ErrorIs(t, nestedErr, errTest)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, outer)
+	assert.Equal(t, 0, inner)
+	assert.Equal(t, []string{"outer"}, notified)
+}
+
+func TestLock_HeldUntilTxEnds(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- store.RunInTx(ctx, func(ctx context.Context) error {
+			if err := store.Lock(ctx, "row"); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = store.RunInTx(ctx, func(ctx context.Context) error {
+			if err := store.Lock(ctx, "row"); err != nil {
+				return err
+			}
+			close(acquired)
+			return nil
+		})
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("lock acquired while held by another transaction")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		assert.False(t, store.TryLock(ctx, "row"))
+		return nil
+	})
+	require.NoError(t, err)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("lock not acquired after release")
+	}
+}
+
+func TestLock_ReentrantInSameTx(t *testing.T) {
+	store := newStore()
+
+	err := store.RunInTx(context.Background(), func(ctx context.Context) error {
+		require.NoError(t, store.Lock(ctx, "row"))
+		require.NoError(t, store.Lock(ctx, "row"))
+		assert.True(t, store.TryLock(ctx, "row"))
+		return nil
+	})
+
+	require.NoError(t, err)
+}
+
+func TestLock_WaitRespectsContext(t *testing.T) {
+	store := newStore()
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		_ = store.RunInTx(context.Background(), func(ctx context.Context) error {
+			_ = store.Lock(ctx, "row")
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		return store.Lock(ctx, "row")
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRunInTx_RetriesDeadlock(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	// Две транзакции блокируют a и b во встречном порядке: одна из них
+	// получает дедлок и выполняется заново после коммита другой.
+	bothLocked := make(chan struct{}, 2)
+	run := func(first, second string) error {
+		attempts := 0
+		return store.RunInTx(ctx, func(ctx context.Context) error {
+			attempts++
+			if err := store.Lock(ctx, first); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				bothLocked <- struct{}{}
+				for len(bothLocked) < 2 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			return store.Lock(ctx, second)
+		})
+	}
+
+	errs := make(chan error, 2)
+	go func() { errs <- run("a", "b") }()
+	go func() { errs <- run("b", "a") }()
+
+	require.NoError(t, <-errs)
+	require.NoError(t, <-errs)
+}
+
+func TestRunInTx_DeadlockRetriesExhausted(t *testing.T) {
+	store := newStore()
+
+	attempts := 0
+	err := store.RunInTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return memstore.ErrDeadlock
+	}, usecase.WithMaxAttempts(4))
+
+	require.ErrorIs(t, err, walleterror.ErrTxConflict)
+	assert.Equal(t, 4, attempts)
+}
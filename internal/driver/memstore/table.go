@@ -0,0 +1,132 @@
+package memstore
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// Table строки одного вида с изоляцией read committed: изменения открытой
+// транзакции видит только она сама, остальные читают закоммиченные версии
+// строк. Изменения пишутся сразу в rows, а закоммиченная версия изменённой
+// строки откладывается до коммита или отката транзакции.
+//
+// Методы вызываются под mu, переданным в NewTable. Таблицы одного
+// репозитория должны делить mu: тогда изменения транзакции во всех таблицах
+// становятся видны разом. Писать строку, изменённую другой открытой
+// транзакцией, нельзя - такие записи защищаются блокировками Store.Lock.
+type Table[K comparable, V any] struct {
+	mu   sync.Locker
+	rows map[K]V
+	// committed закоммиченные версии строк, изменённых открытыми транзакциями.
+	committed map[K]version[V]
+}
+
+type version[V any] struct {
+	writer *tx
+	row    V
+	ok     bool
+}
+
+// NewTable ...
+func NewTable[K comparable, V any](mu sync.Locker) *Table[K, V] {
+	return &Table[K, V]{
+		mu:        mu,
+		rows:      make(map[K]V),
+		committed: make(map[K]version[V]),
+	}
+}
+
+// Get строка key, как её видит транзакция ctx.
+func (t *Table[K, V]) Get(ctx context.Context, key K) (V, bool) {
+	if v, dirty := t.committed[key]; dirty && !v.visibleTo(ctx) {
+		return v.row, v.ok
+	}
+
+	row, ok := t.rows[key]
+	return row, ok
+}
+
+// All строки, которые видит транзакция ctx, в произвольном порядке. Менять
+// таблицу во время обхода нельзя.
+func (t *Table[K, V]) All(ctx context.Context) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for key, row := range t.rows {
+			if v, dirty := t.committed[key]; dirty && !v.visibleTo(ctx) {
+				if !v.ok {
+					continue
+				}
+				row = v.row
+			}
+			if !yield(key, row) {
+				return
+			}
+		}
+
+		// Строки, удалённые чужими открытыми транзакциями.
+		for key, v := range t.committed {
+			if _, ok := t.rows[key]; ok || !v.ok || v.visibleTo(ctx) {
+				continue
+			}
+			if !yield(key, v.row) {
+				return
+			}
+		}
+	}
+}
+
+// Put записывает строку key. Вне транзакции запись сразу окончательна.
+func (t *Table[K, V]) Put(ctx context.Context, key K, row V) {
+	t.set(ctx, key, row, true)
+}
+
+// Delete удаляет строку key.
+func (t *Table[K, V]) Delete(ctx context.Context, key K) {
+	var zero V
+	t.set(ctx, key, zero, false)
+}
+
+func (t *Table[K, V]) set(ctx context.Context, key K, row V, ok bool) {
+	prev, prevOK := t.rows[key]
+	t.store(key, row, ok)
+
+	tx, inTx := extractTx(ctx)
+	if !inTx {
+		return
+	}
+
+	// Закоммиченная версия запоминается при первой записи строки в
+	// транзакции и убирается при коммите.
+	_, dirty := t.committed[key]
+	if !dirty {
+		t.committed[key] = version[V]{writer: tx, row: prev, ok: prevOK}
+		tx.publish = append(tx.publish, publishFunc{mu: t.mu, fn: func() {
+			if v := t.committed[key]; v.writer == tx {
+				delete(t.committed, key)
+			}
+		}})
+	}
+
+	tx.undo = append(tx.undo, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.store(key, prev, prevOK)
+		if !dirty {
+			delete(t.committed, key)
+		}
+	})
+}
+
+func (t *Table[K, V]) store(key K, row V, ok bool) {
+	if ok {
+		t.rows[key] = row
+		return
+	}
+	delete(t.rows, key)
+}
+
+func (v version[V]) visibleTo(ctx context.Context) bool {
+	tx, ok := extractTx(ctx)
+	return ok && v.writer == tx
+}
@@ -0,0 +1,69 @@
+package memstore_test
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"wallet/internal/driver/memstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTable_HidesUncommittedWrites(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	table := memstore.NewTable[string, int](&mu)
+	table.Put(ctx, "a", 1)
+	table.Put(ctx, "b", 2)
+
+	err := store.RunInTx(ctx, func(txCtx context.Context) error {
+		table.Put(txCtx, "a", 10)
+		table.Delete(txCtx, "b")
+		table.Put(txCtx, "c", 3)
+
+		// Транзакция видит свои изменения.
+		assert.Equal(t, map[string]int{"a": 10, "c": 3}, maps.Collect(table.All(txCtx)))
+
+		// Остальные - закоммиченные версии.
+		row, ok := table.Get(ctx, "a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, row)
+		_, ok = table.Get(ctx, "c")
+		assert.False(t, ok)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, maps.Collect(table.All(ctx)))
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"a": 10, "c": 3}, maps.Collect(table.All(ctx)))
+}
+
+func TestTable_RollbackRestoresRows(t *testing.T) {
+	store := newStore()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	table := memstore.NewTable[string, int](&mu)
+	table.Put(ctx, "a", 1)
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		table.Put(ctx, "a", 2)
+
+		nestedErr := store.RunInTx(ctx, func(ctx context.Context) error {
+			table.Put(ctx, "a", 3)
+			table.Put(ctx, "b", 1)
+			return errTest
+		})
+		assert.ErrorIs(t, nestedErr, errTest)
+
+		assert.Equal(t, map[string]int{"a": 2}, maps.Collect(table.All(ctx)))
+		return errTest
+	})
+	require.ErrorIs(t, err, errTest)
+
+	assert.Equal(t, map[string]int{"a": 1}, maps.Collect(table.All(ctx)))
+}
@@ -0,0 +1,175 @@
+// Package metrics метрики сервиса в формате Prometheus. Metrics реализует
+// интерфейсы наблюдателей из middleware, usecase и sqlstore, поэтому
+// остальные слои не зависят от клиента Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wallet"
+
+// Metrics ...
+type Metrics struct {
+	reg *prometheus.Registry
+
+	httpDuration *prometheus.HistogramVec
+	operations   *prometheus.CounterVec
+	txDuration   *prometheus.HistogramVec
+	txRetries    *prometheus.CounterVec
+}
+
+// New создаёт метрики в собственном реестре вместе с метриками рантайма Go
+// и процесса.
+func New() *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of HTTP requests by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operations_total",
+			Help:      "Deposits and withdrawals by outcome.",
+		}, []string{"type", "outcome"}),
+		txDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "tx_duration_seconds",
+			Help:      "Duration of database transactions including retries, by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		txRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "tx_retries_total",
+			Help:      "Database transaction retries by SQLSTATE.",
+		}, []string{"code"}),
+	}
+
+	m.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.operations,
+		m.txDuration,
+		m.txRetries,
+	)
+
+	return m
+}
+
+// Handler отдаёт метрики для /metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+// ObserveHTTPRequest ...
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, d time.Duration) {
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// ObserveOperation ...
+func (m *Metrics) ObserveOperation(opType, outcome string) {
+	m.operations.WithLabelValues(opType, outcome).Inc()
+}
+
+// ObserveTx ...
+func (m *Metrics) ObserveTx(d time.Duration, outcome string) {
+	m.txDuration.WithLabelValues(outcome).Observe(d.Seconds())
+}
+
+// IncTxRetry ...
+func (m *Metrics) IncTxRetry(code string) {
+	m.txRetries.WithLabelValues(code).Inc()
+}
+
+// PoolStater источник статистики пула соединений, например sqlstore.Store.
+type PoolStater interface {
+	Stat() *pgxpool.Stat
+}
+
+// RegisterPool добавляет метрики пула, снимаемые с pool при каждом запросе
+// /metrics.
+func (m *Metrics) RegisterPool(pool PoolStater) error {
+	return m.reg.Register(newPoolCollector(pool))
+}
+
+// poolCollector отдаёт pgxpool.Stat как gauge для текущего состояния пула и
+// counter для накопительных счётчиков.
+type poolCollector struct {
+	pool PoolStater
+
+	acquiredConns     *prometheus.Desc
+	idleConns         *prometheus.Desc
+	constructingConns *prometheus.Desc
+	totalConns        *prometheus.Desc
+	maxConns          *prometheus.Desc
+	acquires          *prometheus.Desc
+	emptyAcquires     *prometheus.Desc
+	canceledAcquires  *prometheus.Desc
+	acquireDuration   *prometheus.Desc
+}
+
+func newPoolCollector(pool PoolStater) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_conns", "Connections currently acquired from the pool."),
+		idleConns:         desc("idle_conns", "Idle connections in the pool."),
+		constructingConns: desc("constructing_conns", "Connections currently being established."),
+		totalConns:        desc("total_conns", "Total connections in the pool."),
+		maxConns:          desc("max_conns", "Maximum size of the pool."),
+		acquires:          desc("acquires_total", "Successful connection acquires."),
+		emptyAcquires:     desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquires:  desc("canceled_acquires_total", "Acquires canceled by context."),
+		acquireDuration:   desc("acquire_duration_seconds_total", "Total time spent acquiring connections."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.constructingConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.canceledAcquires
+	ch <- c.acquireDuration
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+
+	gauge := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v)
+	}
+	counter := func(d *prometheus.Desc, v float64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v)
+	}
+
+	gauge(c.acquiredConns, float64(s.AcquiredConns()))
+	gauge(c.idleConns, float64(s.IdleConns()))
+	gauge(c.constructingConns, float64(s.ConstructingConns()))
+	gauge(c.totalConns, float64(s.TotalConns()))
+	gauge(c.maxConns, float64(s.MaxConns()))
+	counter(c.acquires, float64(s.AcquireCount()))
+	counter(c.emptyAcquires, float64(s.EmptyAcquireCount()))
+	counter(c.canceledAcquires, float64(s.CanceledAcquireCount()))
+	counter(c.acquireDuration, s.AcquireDuration().Seconds())
+}
@@ -0,0 +1,44 @@
+// Package metrics_test ...
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet/internal/driver/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	m := metrics.New()
+
+	m.ObserveHTTPRequest(http.MethodGet, "/api/v1/wallets/{id}", http.StatusOK, 5*time.Millisecond)
+	m.ObserveOperation("WITHDRAW", "insufficient_funds")
+	m.ObserveOperation("WITHDRAW", "insufficient_funds")
+	m.ObserveTx(time.Millisecond, "committed")
+	m.IncTxRetry("40001")
+
+	body := scrape(t, m)
+
+	assert.Contains(t, body, `wallet_http_request_duration_seconds_count{method="GET",route="/api/v1/wallets/{id}",status="200"} 1`)
+	assert.Contains(t, body, `wallet_operations_total{outcome="insufficient_funds",type="WITHDRAW"} 2`)
+	assert.Contains(t, body, `wallet_db_tx_duration_seconds_count{outcome="committed"} 1`)
+	assert.Contains(t, body, `wallet_db_tx_retries_total{code="40001"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
@@ -0,0 +1,450 @@
+// Package migrate ...
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey ключ advisory lock, под которым выполняются миграции. Значение
+// произвольное, главное чтобы оно было одинаковым у всех реплик.
+const lockKey int64 = 7_311_280_117
+
+var fileRe = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// ErrUnknownVersion ...
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+// Migration ...
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status ...
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator ...
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	log        *slog.Logger
+}
+
+// New ...
+func New(pool *pgxpool.Pool, fsys fs.FS, log *slog.Logger) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		pool:       pool,
+		migrations: migrations,
+		log:        log,
+	}, nil
+}
+
+// Load ...
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations dir: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse version of %s: %w", e.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(".", e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", e.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Latest ...
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up ...
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down откатывает одну последнюю применённую миграцию.
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		current := currentVersion(applied)
+		if current == 0 {
+			m.log.Info("no migrations to roll back")
+			return nil
+		}
+
+		mig, ok := m.find(current)
+		if !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, current)
+		}
+
+		return m.down(ctx, conn, mig)
+	})
+}
+
+// Goto приводит схему к версии version: применяет недостающие миграции
+// или откатывает лишние.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if _, ok := m.find(version); !ok && version != 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for v := range applied {
+			if v > version {
+				if _, ok := m.find(v); !ok {
+					return fmt.Errorf("%w: %d is applied but not embedded", ErrUnknownVersion, v)
+				}
+			}
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if mig.Version <= version {
+				break
+			}
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.down(ctx, conn, mig); err != nil {
+				return err
+			}
+		}
+
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.up(ctx, conn, mig); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Force отмечает миграции до version включительно применёнными, а более
+// поздние - неприменёнными, не выполняя их SQL. Нужен, чтобы поставить под
+// управление базу, схема которой создана в обход миграций.
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if _, ok := m.find(version); !ok && version != 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version > $1`, version); err != nil {
+				return fmt.Errorf("unmark migrations: %w", err)
+			}
+			if err := m.mark(ctx, tx, version); err != nil {
+				return err
+			}
+
+			m.log.Info("migration version forced", slog.Int64("version", version))
+			return nil
+		})
+	})
+}
+
+// Baseline ставит под управление базу без истории миграций: если
+// schema_migrations пуста, detect определяет по схеме, до какой версии она
+// уже доведена, и эти версии отмечаются применёнными. detect возвращает 0
+// для пустой базы. База с историей не трогается.
+func (m *Migrator) Baseline(ctx context.Context, detect func(ctx context.Context, conn *pgx.Conn) (int64, error)) error {
+	return m.withLock(ctx, func(conn *pgx.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return nil
+		}
+
+		version, err := detect(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("detect schema version: %w", err)
+		}
+		if version == 0 {
+			return nil
+		}
+
+		err = pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			return m.mark(ctx, tx, version)
+		})
+		if err != nil {
+			return err
+		}
+
+		m.log.Info("existing schema baselined", slog.Int64("version", version))
+		return nil
+	})
+}
+
+// mark отмечает применёнными миграции до version включительно.
+func (m *Migrator) mark(ctx context.Context, tx pgx.Tx, version int64) error {
+	for _, mig := range m.migrations {
+		if mig.Version > version {
+			break
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT (version) DO NOTHING`,
+			mig.Version, mig.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("mark migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return nil
+}
+
+// Status ...
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if err := ensureTable(ctx, conn.Conn()); err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, conn.Conn())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		st := Status{Version: mig.Version, Name: mig.Name}
+		if at, ok := applied[mig.Version]; ok {
+			st.Applied = true
+			st.AppliedAt = at
+		}
+		statuses = append(statuses, st)
+	}
+
+	return statuses, nil
+}
+
+// Version ...
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	var version int64
+	err := m.pool.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("get schema version: %w", err)
+	}
+	return version, nil
+}
+
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
+
+// withLock выполняет fn на выделенном соединении под advisory lock, чтобы
+// несколько одновременно стартующих реплик не применяли миграции наперегонки.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			m.log.Warn("release migration lock", slog.String("err", err.Error()))
+		}
+	}()
+
+	if err := ensureTable(ctx, conn.Conn()); err != nil {
+		return err
+	}
+
+	return fn(conn.Conn())
+}
+
+func (m *Migrator) up(ctx context.Context, conn *pgx.Conn, mig Migration) error {
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+			mig.Version, mig.Name,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("apply migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	m.log.Info("migration applied",
+		slog.Int64("version", mig.Version),
+		slog.String("name", mig.Name),
+	)
+	return nil
+}
+
+func (m *Migrator) down(ctx context.Context, conn *pgx.Conn, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+	}
+
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("roll back migration %d_%s: %w", mig.Version, mig.Name, err)
+	}
+
+	m.log.Info("migration rolled back",
+		slog.Int64("version", mig.Version),
+		slog.String("name", mig.Name),
+	)
+	return nil
+}
+
+func ensureTable(ctx context.Context, conn *pgx.Conn) error {
+	_, err := conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	return nil
+}
+
+func appliedVersions(ctx context.Context, conn *pgx.Conn) (map[int64]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var (
+			version int64
+			at      time.Time
+		)
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, fmt.Errorf("scan applied migration: %w", err)
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list applied migrations: %w", err)
+	}
+
+	return applied, nil
+}
+
+func currentVersion(applied map[int64]time.Time) int64 {
+	var current int64
+	for v := range applied {
+		if v > current {
+			current = v
+		}
+	}
+	return current
+}
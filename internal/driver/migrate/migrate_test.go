@@ -0,0 +1,64 @@
+// Package migrate_test ...
+package migrate_test
+
+import (
+	"testing"
+	"testing/fstest"
+	"wallet/internal/driver/migrate"
+	"wallet/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad_SortsAndPairs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("CREATE TABLE b ();")},
+		"0002_second.down.sql": {Data: []byte("DROP TABLE b;")},
+		"0001_first.up.sql":    {Data: []byte("CREATE TABLE a ();")},
+		"0001_first.down.sql":  {Data: []byte("DROP TABLE a;")},
+		"README.md":            {Data: []byte("ignored")},
+	}
+
+	migrations, err := migrate.Load(fsys)
+
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "first", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE a ();", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE a;", migrations[0].Down)
+	assert.Equal(t, int64(2), migrations[1].Version)
+}
+
+func TestLoad_MissingUp(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_first.down.sql": {Data: []byte("DROP TABLE a;")},
+	}
+
+	_, err := migrate.Load(fsys)
+
+	assert.Error(t, err)
+}
+
+func TestLoad_ConflictingNames(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_first.up.sql": {Data: []byte("CREATE TABLE a ();")},
+		"0001_other.up.sql": {Data: []byte("CREATE TABLE b ();")},
+	}
+
+	_, err := migrate.Load(fsys)
+
+	assert.Error(t, err)
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	migrations, err := migrate.Load(migration.FS)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version, "migrations must be numbered without gaps")
+		assert.NotEmpty(t, m.Down, "migration %d_%s has no down file", m.Version, m.Name)
+	}
+}
@@ -0,0 +1,75 @@
+// Package notify ...
+package notify
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// Hub раздаёт сигналы об изменении кошелька его подписчикам. У каждого
+// подписчика буфер на один сигнал: пока он не прочитан, новые схлопываются
+// в него, поэтому медленный подписчик не тормозит остальных.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan struct{}]struct{}
+}
+
+// NewHub ...
+func NewHub() *Hub {
+	return &Hub{subs: make(map[uuid.UUID]map[chan struct{}]struct{})}
+}
+
+// Subscribe ...
+func (h *Hub) Subscribe(walletID uuid.UUID) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	if h.subs[walletID] == nil {
+		h.subs[walletID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[walletID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subs[walletID], ch)
+			if len(h.subs[walletID]) == 0 {
+				delete(h.subs, walletID)
+			}
+		})
+	}
+}
+
+// Notify будит подписчиков кошелька.
+func (h *Hub) Notify(walletID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs[walletID] {
+		wake(ch)
+	}
+}
+
+// NotifyAll будит всех подписчиков. Нужен, когда уведомления могли
+// потеряться, например при переподключении к базе.
+func (h *Hub) NotifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subs := range h.subs {
+		for ch := range subs {
+			wake(ch)
+		}
+	}
+}
+
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
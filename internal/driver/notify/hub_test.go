@@ -0,0 +1,80 @@
+package notify_test
+
+import (
+	"testing"
+	"wallet/internal/driver/notify"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func signaled(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestHub_NotifyWakesOnlyWalletSubscribers(t *testing.T) {
+	hub := notify.NewHub()
+	walletID := uuid.New()
+
+	a, unsubA := hub.Subscribe(walletID)
+	defer unsubA()
+	b, unsubB := hub.Subscribe(walletID)
+	defer unsubB()
+	other, unsubOther := hub.Subscribe(uuid.New())
+	defer unsubOther()
+
+	hub.Notify(walletID)
+
+	assert.True(t, signaled(a))
+	assert.True(t, signaled(b))
+	assert.False(t, signaled(other))
+}
+
+func TestHub_SignalsCoalesce(t *testing.T) {
+	hub := notify.NewHub()
+	walletID := uuid.New()
+
+	ch, unsubscribe := hub.Subscribe(walletID)
+	defer unsubscribe()
+
+	// Непрочитанный подписчик не блокирует Notify.
+	for range 10 {
+		hub.Notify(walletID)
+	}
+
+	assert.True(t, signaled(ch))
+	assert.False(t, signaled(ch))
+}
+
+func TestHub_Unsubscribe(t *testing.T) {
+	hub := notify.NewHub()
+	walletID := uuid.New()
+
+	ch, unsubscribe := hub.Subscribe(walletID)
+	unsubscribe()
+	unsubscribe()
+
+	hub.Notify(walletID)
+	hub.NotifyAll()
+
+	assert.False(t, signaled(ch))
+}
+
+func TestHub_NotifyAll(t *testing.T) {
+	hub := notify.NewHub()
+
+	a, unsubA := hub.Subscribe(uuid.New())
+	defer unsubA()
+	b, unsubB := hub.Subscribe(uuid.New())
+	defer unsubB()
+
+	hub.NotifyAll()
+
+	assert.True(t, signaled(a))
+	assert.True(t, signaled(b))
+}
@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 10 * time.Second
+)
+
+// Listener слушает канал Postgres через LISTEN и будит подписчиков Hub
+// по ID кошелька из payload уведомления.
+type Listener struct {
+	pool    *pgxpool.Pool
+	channel string
+	hub     *Hub
+	logger  *slog.Logger
+}
+
+// NewListener ...
+func NewListener(pool *pgxpool.Pool, channel string, hub *Hub, logger *slog.Logger) *Listener {
+	return &Listener{
+		pool:    pool,
+		channel: channel,
+		hub:     hub,
+		logger:  logger,
+	}
+}
+
+// Run слушает канал, пока ctx не отменён. После обрыва соединения
+// переподключается с нарастающей задержкой.
+func (l *Listener) Run(ctx context.Context) {
+	delay := minReconnectDelay
+	for {
+		started := time.Now()
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Соединение, которое успело поработать, - не повод копить задержку.
+		if time.Since(started) > maxReconnectDelay {
+			delay = minReconnectDelay
+		}
+		l.logger.Warn("listener disconnected",
+			slog.String("channel", l.channel),
+			slog.Duration("retry_in", delay),
+			slog.String("err", err.Error()),
+		)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	pooled, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	// Соединение с LISTEN нельзя возвращать в пул: забираем его насовсем.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	// Пока соединения не было, уведомления могли потеряться: пусть
+	// подписчики перечитают события сами.
+	l.hub.NotifyAll()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("wait for notification: %w", err)
+		}
+
+		walletID, err := uuid.Parse(n.Payload)
+		if err != nil {
+			l.logger.Warn("unexpected notification payload",
+				slog.String("channel", l.channel),
+				slog.String("payload", n.Payload),
+			)
+			continue
+		}
+		l.hub.Notify(walletID)
+	}
+}
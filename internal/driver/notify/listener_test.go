@@ -0,0 +1,81 @@
+package notify_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+	"wallet/internal/driver/notify"
+	"wallet/internal/driver/sqlstore"
+	"wallet/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener_DeliversCommittedNotifications(t *testing.T) {
+	if err := godotenv.Load("../../../config.env"); err != nil {
+		t.Skip("env not load")
+	}
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration tests")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err := sqlstore.New(ctx, dsn, sqlstore.DefaultPoolConfig())
+	require.NoError(t, err)
+	defer store.Close()
+
+	hub := notify.NewHub()
+	walletID := uuid.New()
+	ch, unsubscribe := hub.Subscribe(walletID)
+	defer unsubscribe()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		notify.NewListener(store.Pool(), repository.WalletEventsChannel, hub, slog.New(slog.NewTextHandler(io.Discard, nil))).Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Первый сигнал - от подключения слушателя.
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not connect")
+	}
+
+	repo := repository.New(store.Pool())
+
+	// Откаченная транзакция уведомления не шлёт.
+	_ = store.RunInTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, repo.NotifyWalletChanged(ctx, walletID))
+		return context.Canceled
+	})
+	require.NoError(t, store.RunInTx(ctx, func(ctx context.Context) error {
+		return repo.NotifyWalletChanged(ctx, uuid.New())
+	}))
+	select {
+	case <-ch:
+		t.Fatal("unexpected notification")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, store.RunInTx(ctx, func(ctx context.Context) error {
+		return repo.NotifyWalletChanged(ctx, walletID)
+	}))
+	select {
+	case <-ch:
+	case <-time.After(5 * time.Second):
+		t.Fatal("notification not delivered")
+	}
+}
@@ -0,0 +1,112 @@
+// Package publisher ...
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+)
+
+// WriterPublisher пишет события в w как JSON Lines: в stdout или файл.
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter ...
+func NewWriter(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// Publish ...
+func (p *WriterPublisher) Publish(_ context.Context, event model.Event) error {
+	line, err := json.Marshal(event.Message())
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.w.Write(line); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+
+	return nil
+}
+
+// HTTPPublisher отправляет каждое событие POST-запросом на url. Доставленным
+// считается событие, на которое получатель ответил 2xx.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTP ...
+func NewHTTP(url string, timeout time.Duration) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Publish ...
+func (p *HTTPPublisher) Publish(ctx context.Context, event model.Event) error {
+	body, err := json.Marshal(event.Message())
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID.String())
+	req.Header.Set("X-Event-Sequence", strconv.FormatInt(event.Sequence, 10))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("send event: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// MultiPublisher публикует событие во все publishers по очереди. Первая
+// ошибка прерывает публикацию, и событие будет отправлено повторно всем,
+// поэтому publishers должны переносить дубли.
+type MultiPublisher struct {
+	publishers []usecase.Publisher
+}
+
+// NewMulti ...
+func NewMulti(publishers ...usecase.Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+// Publish ...
+func (p *MultiPublisher) Publish(ctx context.Context, event model.Event) error {
+	for _, pub := range p.publishers {
+		if err := pub.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Package publisher_test ...
+package publisher_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wallet/internal/driver/publisher"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() model.Event {
+	return model.Event{
+		Sequence:  7,
+		ID:        uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Type:      "DEPOSIT",
+		WalletID:  uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		Payload:   json.RawMessage(`{"amount":100}`),
+		CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+// --- WriterPublisher ---
+
+func TestWriterPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	p := publisher.NewWriter(&buf)
+
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var msg model.EventMessage
+	require.NoError(t, json.Unmarshal(lines[0], &msg))
+	assert.Equal(t, int64(7), msg.Sequence)
+	assert.Equal(t, "DEPOSIT", msg.Type)
+	assert.JSONEq(t, `{"amount":100}`, string(msg.Data))
+}
+
+// --- HTTPPublisher ---
+
+func TestHTTPPublisher_Publish(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := publisher.NewHTTP(srv.URL, time.Second)
+	require.NoError(t, p.Publish(context.Background(), testEvent()))
+
+	require.NotNil(t, got)
+	assert.Equal(t, http.MethodPost, got.Method)
+	assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+	assert.Equal(t, "11111111-1111-1111-1111-111111111111", got.Header.Get("X-Event-ID"))
+	assert.Equal(t, "7", got.Header.Get("X-Event-Sequence"))
+
+	var msg model.EventMessage
+	require.NoError(t, json.Unmarshal(body, &msg))
+	assert.Equal(t, testEvent().WalletID, msg.WalletID)
+}
+
+func TestHTTPPublisher_Publish_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := publisher.NewHTTP(srv.URL, time.Second)
+	err := p.Publish(context.Background(), testEvent())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+// --- MultiPublisher ---
+
+type funcPublisher func(context.Context, model.Event) error
+
+func (f funcPublisher) Publish(ctx context.Context, event model.Event) error {
+	return f(ctx, event)
+}
+
+func TestMultiPublisher_StopsOnError(t *testing.T) {
+	var calls []string
+	pubErr := errors.New("unavailable")
+	p := publisher.NewMulti(
+		funcPublisher(func(context.Context, model.Event) error {
+			calls = append(calls, "first")
+			return nil
+		}),
+		funcPublisher(func(context.Context, model.Event) error {
+			calls = append(calls, "second")
+			return pubErr
+		}),
+		funcPublisher(func(context.Context, model.Event) error {
+			calls = append(calls, "third")
+			return nil
+		}),
+	)
+
+	err := p.Publish(context.Background(), testEvent())
+
+	assert.ErrorIs(t, err, pubErr)
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
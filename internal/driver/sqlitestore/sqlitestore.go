@@ -0,0 +1,280 @@
+// Package sqlitestore ...
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/url"
+	"strconv"
+	"time"
+
+	walleterror "wallet/internal/error"
+	"wallet/internal/usecase"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Config ...
+type Config struct {
+	// MaxOpenConns ...
+	MaxOpenConns int
+	// BusyTimeout сколько транзакция ждёт блокировку записи, прежде чем
+	// получить SQLITE_BUSY.
+	BusyTimeout time.Duration
+
+	// TxMaxAttempts ...
+	TxMaxAttempts int
+	// TxRetryBaseDelay ...
+	TxRetryBaseDelay time.Duration
+	// TxRetryMaxDelay ...
+	TxRetryMaxDelay time.Duration
+}
+
+// DefaultConfig ...
+func DefaultConfig() Config {
+	return Config{
+		MaxOpenConns: 8,
+		BusyTimeout:  5 * time.Second,
+
+		TxMaxAttempts:    3,
+		TxRetryBaseDelay: 10 * time.Millisecond,
+		TxRetryMaxDelay:  500 * time.Millisecond,
+	}
+}
+
+// Querier общий интерфейс *sql.DB и *sql.Tx.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Store TxManager поверх файла SQLite.
+//
+// Все транзакции начинаются с BEGIN IMMEDIATE: блокировка записи берётся
+// сразу, поэтому в SQLite пишет одна транзакция за раз, и SELECT внутри
+// транзакции равносилен SELECT ... FOR UPDATE. Читатели вне транзакций
+// (WAL) видят последнее закоммиченное состояние и не ждут писателя.
+type Store struct {
+	db     *sql.DB
+	cfg    Config
+	logger *slog.Logger
+}
+
+// tx транзакция в контексте. depth - глубина вложенных RunInTx, по ней
+// именуются точки сохранения.
+type tx struct {
+	tx       *sql.Tx
+	depth    int
+	onCommit []func()
+}
+
+type txKey struct{}
+
+func extractTx(ctx context.Context) (*tx, bool) {
+	t, ok := ctx.Value(txKey{}).(*tx)
+	return t, ok
+}
+
+// New открывает (и при необходимости создаёт) файл базы path.
+func New(ctx context.Context, path string, cfg Config) (*Store, error) {
+	q := url.Values{}
+	q.Set("_txlock", "immediate")
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", "foreign_keys(1)")
+	q.Add("_pragma", "busy_timeout("+strconv.FormatInt(cfg.BusyTimeout.Milliseconds(), 10)+")")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+
+	return &Store{
+		db:     db,
+		cfg:    cfg,
+		logger: slog.Default(),
+	}, nil
+}
+
+// SetLogger ...
+func (s *Store) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// Close ...
+func (s *Store) Close() {
+	_ = s.db.Close()
+}
+
+// DB ...
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Q возвращает транзакцию из ctx или, если её нет, саму базу.
+func (s *Store) Q(ctx context.Context) Querier {
+	if t, ok := extractTx(ctx); ok {
+		return t.tx
+	}
+	return s.db
+}
+
+// AfterCommit выполняет fn после коммита транзакции ctx, а вне транзакции -
+// сразу. При откате fn не выполняется.
+func AfterCommit(ctx context.Context, fn func()) {
+	if t, ok := extractTx(ctx); ok {
+		t.onCommit = append(t.onCommit, fn)
+		return
+	}
+	fn()
+}
+
+// RunInTx выполняет fn в транзакции. Если блокировку записи не удалось
+// получить за BusyTimeout (SQLITE_BUSY), транзакция откатывается и fn
+// выполняется заново, поэтому fn не должна иметь побочных эффектов вне
+// базы.
+//
+// Если в ctx уже есть транзакция, fn выполняется внутри неё под SAVEPOINT:
+// ошибка fn откатывает только изменения вложенного вызова. Уровень изоляции
+// из opts не учитывается: транзакции SQLite всегда сериализуемы.
+func (s *Store) RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts ...usecase.TxOption) error {
+	if t, ok := extractTx(ctx); ok {
+		return runNested(ctx, t, fn)
+	}
+
+	o := usecase.TxOptions{MaxAttempts: s.cfg.TxMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := s.runOnce(ctx, fn)
+		if err == nil {
+			if attempt > 1 {
+				s.logger.Info("transaction succeeded after retry",
+					slog.Int("attempt", attempt),
+				)
+			}
+			return nil
+		}
+
+		if !IsBusy(err) {
+			return err
+		}
+
+		if attempt >= o.MaxAttempts {
+			s.logger.Warn("transaction retries exhausted",
+				slog.Int("attempt", attempt),
+				slog.String("err", err.Error()),
+			)
+			return fmt.Errorf("%w: %w", walleterror.ErrTxConflict, err)
+		}
+
+		delay := s.backoff(attempt)
+		s.logger.Warn("retrying transaction",
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", o.MaxAttempts),
+			slog.Duration("delay", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *Store) runOnce(ctx context.Context, fn func(ctx context.Context) error) error {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	t := &tx{tx: sqlTx}
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		if rbErr := sqlTx.Rollback(); rbErr != nil {
+			return fmt.Errorf("rollback failed: %w (original: %w)", rbErr, err)
+		}
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	for _, f := range t.onCommit {
+		f()
+	}
+
+	return nil
+}
+
+// runNested ...
+func runNested(ctx context.Context, t *tx, fn func(ctx context.Context) error) error {
+	t.depth++
+	defer func() { t.depth-- }()
+
+	sp := "sp_" + strconv.Itoa(t.depth)
+	if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+sp); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	commitMark := len(t.onCommit)
+	if err := fn(ctx); err != nil {
+		// ROLLBACK TO оставляет точку сохранения, RELEASE снимает её.
+		if _, rbErr := t.tx.ExecContext(ctx, "ROLLBACK TO "+sp+"; RELEASE "+sp); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint failed: %w (original: %w)", rbErr, err)
+		}
+		t.onCommit = t.onCommit[:commitMark]
+		return err
+	}
+
+	if _, err := t.tx.ExecContext(ctx, "RELEASE "+sp); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// backoff экспоненциальная задержка с джиттером в диапазоне [d/2, d].
+func (s *Store) backoff(attempt int) time.Duration {
+	d := s.cfg.TxRetryBaseDelay << (attempt - 1)
+	if d <= 0 || d > s.cfg.TxRetryMaxDelay {
+		d = s.cfg.TxRetryMaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// IsBusy сообщает, что база была занята другой транзакцией.
+func IsBusy(err error) bool {
+	code, ok := Code(err)
+	return ok && (code&0xff == sqlite3.SQLITE_BUSY || code&0xff == sqlite3.SQLITE_LOCKED)
+}
+
+// Code расширенный код ошибки SQLite.
+func Code(err error) (int, bool) {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return 0, false
+	}
+	return sqliteErr.Code(), true
+}
@@ -0,0 +1,117 @@
+// Package sqlitestore_test ...
+package sqlitestore_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+	"wallet/internal/driver/sqlitestore"
+	walleterror "wallet/internal/error"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func newStore(t *testing.T, cfg sqlitestore.Config) *sqlitestore.Store {
+	t.Helper()
+
+	ctx := context.Background()
+	store, err := sqlitestore.New(ctx, filepath.Join(t.TempDir(), "test.db"), cfg)
+	require.NoError(t, err)
+	t.Cleanup(store.Close)
+
+	_, err = store.DB().ExecContext(ctx, `CREATE TABLE items (id INTEGER PRIMARY KEY)`)
+	require.NoError(t, err)
+
+	return store
+}
+
+func countItems(t *testing.T, store *sqlitestore.Store) int {
+	t.Helper()
+
+	var n int
+	require.NoError(t, store.DB().QueryRow(`SELECT COUNT(*) FROM items`).Scan(&n))
+	return n
+}
+
+func insertItem(ctx context.Context, store *sqlitestore.Store, id int) error {
+	_, err := store.Q(ctx).ExecContext(ctx, `INSERT INTO items (id) VALUES (?1)`, id)
+	return err
+}
+
+func TestRunInTx_RollbackSkipsAfterCommit(t *testing.T) {
+	store := newStore(t, sqlitestore.DefaultConfig())
+	ctx := context.Background()
+
+	committed := false
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, insertItem(ctx, store, 1))
+		sqlitestore.AfterCommit(ctx, func() { committed = true })
+		return errTest
+	})
+
+	require.ErrorIs(t, err, errTest)
+	assert.Zero(t, countItems(t, store))
+	assert.False(t, committed)
+}
+
+func TestRunInTx_NestedRollsBackOnlyItself(t *testing.T) {
+	store := newStore(t, sqlitestore.DefaultConfig())
+	ctx := context.Background()
+
+	var committed []int
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, insertItem(ctx, store, 1))
+		sqlitestore.AfterCommit(ctx, func() { committed = append(committed, 1) })
+
+		err := store.RunInTx(ctx, func(ctx context.Context) error {
+			require.NoError(t, insertItem(ctx, store, 2))
+			sqlitestore.AfterCommit(ctx, func() { committed = append(committed, 2) })
+			return errTest
+		})
+		require.ErrorIs(t, err, errTest)
+
+		return store.RunInTx(ctx, func(ctx context.Context) error {
+			return insertItem(ctx, store, 3)
+		})
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, countItems(t, store))
+	assert.Equal(t, []int{1}, committed)
+}
+
+func TestRunInTx_BusyExhaustsAttempts(t *testing.T) {
+	cfg := sqlitestore.DefaultConfig()
+	cfg.BusyTimeout = 10 * time.Millisecond
+	cfg.TxMaxAttempts = 2
+	cfg.TxRetryBaseDelay = time.Millisecond
+	cfg.TxRetryMaxDelay = time.Millisecond
+	store := newStore(t, cfg)
+	ctx := context.Background()
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- store.RunInTx(ctx, func(ctx context.Context) error {
+			close(locked)
+			<-release
+			return nil
+		})
+	}()
+	<-locked
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		return insertItem(ctx, store, 1)
+	})
+	close(release)
+
+	require.ErrorIs(t, err, walleterror.ErrTxConflict)
+	assert.True(t, sqlitestore.IsBusy(err))
+	require.NoError(t, <-done)
+}
@@ -3,21 +3,39 @@ package sqlstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math/rand/v2"
 	"time"
 
 	txctx "wallet/internal/driver"
+	walleterror "wallet/internal/error"
+	"wallet/internal/usecase"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
 // PoolConfig ...
 type PoolConfig struct {
 	MaxConns        int32
 	MinConns        int32
 	MaxConnLifetime time.Duration
 	MaxConnIdleTime time.Duration
+
+	// TxMaxAttempts ...
+	TxMaxAttempts int
+	// TxRetryBaseDelay ...
+	TxRetryBaseDelay time.Duration
+	// TxRetryMaxDelay ...
+	TxRetryMaxDelay time.Duration
 }
 
 // DefaultPoolConfig ...
@@ -27,12 +45,38 @@ func DefaultPoolConfig() PoolConfig {
 		MinConns:        5,
 		MaxConnLifetime: 1 * time.Hour,
 		MaxConnIdleTime: 30 * time.Minute,
+
+		TxMaxAttempts:    3,
+		TxRetryBaseDelay: 10 * time.Millisecond,
+		TxRetryMaxDelay:  500 * time.Millisecond,
 	}
 }
 
+// Исходы транзакций для TxMetrics.
+const (
+	TxCommitted = "committed"
+	TxFailed    = "failed"
+	TxConflict  = "conflict"
+)
+
+// TxMetrics получает длительность транзакций RunInTx вместе со всеми
+// повторами и число повторов по коду ошибки.
+type TxMetrics interface {
+	ObserveTx(d time.Duration, outcome string)
+	IncTxRetry(code string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveTx(time.Duration, string) {}
+func (nopMetrics) IncTxRetry(string)               {}
+
 // Store ...
 type Store struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	cfg     PoolConfig
+	logger  *slog.Logger
+	metrics TxMetrics
 }
 
 // New ...
@@ -56,7 +100,22 @@ func New(ctx context.Context, connString string, cfg PoolConfig) (*Store, error)
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
-	return &Store{pool: pool}, nil
+	return &Store{
+		pool:    pool,
+		cfg:     cfg,
+		logger:  slog.Default(),
+		metrics: nopMetrics{},
+	}, nil
+}
+
+// SetLogger ...
+func (s *Store) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetMetrics ...
+func (s *Store) SetMetrics(m TxMetrics) {
+	s.metrics = m
 }
 
 // Close ...
@@ -69,11 +128,94 @@ func (s *Store) Pool() *pgxpool.Pool {
 	return s.pool
 }
 
-// RunInTx ...
-func (s *Store) RunInTx(ctx context.Context, fn func(ctx context.Context) error) error {
-	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{
-		IsoLevel: pgx.ReadCommitted,
-	})
+// Ping проверяет, что база отвечает.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// Stat снимок состояния пула соединений.
+func (s *Store) Stat() *pgxpool.Stat {
+	return s.pool.Stat()
+}
+
+// RunInTx выполняет fn в транзакции. При ошибках сериализации (40001) и
+// дедлоках (40P01) транзакция откатывается и fn выполняется заново, поэтому
+// fn не должна иметь побочных эффектов вне базы.
+//
+// Если в ctx уже есть транзакция, fn выполняется внутри неё под SAVEPOINT:
+// ошибка fn откатывает только изменения вложенного вызова, а опции и повторы
+// определяет внешняя транзакция.
+func (s *Store) RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts ...usecase.TxOption) error {
+	if tx, ok := txctx.ExtractTx(ctx); ok {
+		return runNested(ctx, tx, fn)
+	}
+
+	o := usecase.TxOptions{
+		Isolation:   usecase.ReadCommitted,
+		MaxAttempts: s.cfg.TxMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 1
+	}
+
+	txOpts, err := pgxTxOptions(o)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	outcome := TxFailed
+	defer func() { s.metrics.ObserveTx(time.Since(start), outcome) }()
+
+	for attempt := 1; ; attempt++ {
+		err := s.runOnce(ctx, txOpts, fn)
+		if err == nil {
+			outcome = TxCommitted
+			if attempt > 1 {
+				s.logger.Info("transaction succeeded after retry",
+					slog.Int("attempt", attempt),
+				)
+			}
+			return nil
+		}
+
+		code, retryable := retryableCode(err)
+		if !retryable {
+			return err
+		}
+
+		if attempt >= o.MaxAttempts {
+			outcome = TxConflict
+			s.logger.Warn("transaction retries exhausted",
+				slog.Int("attempt", attempt),
+				slog.String("code", code),
+				slog.String("err", err.Error()),
+			)
+			return fmt.Errorf("%w: %w", walleterror.ErrTxConflict, err)
+		}
+
+		s.metrics.IncTxRetry(code)
+		delay := s.backoff(attempt)
+		s.logger.Warn("retrying transaction",
+			slog.Int("attempt", attempt),
+			slog.Int("max_attempts", o.MaxAttempts),
+			slog.String("code", code),
+			slog.Duration("delay", delay),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (s *Store) runOnce(ctx context.Context, txOpts pgx.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := s.pool.BeginTx(ctx, txOpts)
 	if err != nil {
 		return fmt.Errorf("begin transaction: %w", err)
 	}
@@ -93,3 +235,77 @@ func (s *Store) RunInTx(ctx context.Context, fn func(ctx context.Context) error)
 
 	return nil
 }
+
+// runNested ...
+func runNested(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context) error) error {
+	// pgx.Tx.Begin внутри транзакции создаёт SAVEPOINT.
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	ctx = context.WithValue(ctx, txctx.TxKey{}, sp)
+
+	if err := fn(ctx); err != nil {
+		if rbErr := sp.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("rollback to savepoint failed: %w (original: %w)", rbErr, err)
+		}
+		return err
+	}
+
+	if err := sp.Commit(ctx); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return nil
+}
+
+// backoff экспоненциальная задержка с джиттером в диапазоне [d/2, d].
+func (s *Store) backoff(attempt int) time.Duration {
+	d := s.cfg.TxRetryBaseDelay << (attempt - 1)
+	if d <= 0 || d > s.cfg.TxRetryMaxDelay {
+		d = s.cfg.TxRetryMaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+func pgxTxOptions(o usecase.TxOptions) (pgx.TxOptions, error) {
+	var txOpts pgx.TxOptions
+
+	switch o.Isolation {
+	case usecase.ReadCommitted, "":
+		txOpts.IsoLevel = pgx.ReadCommitted
+	case usecase.RepeatableRead:
+		txOpts.IsoLevel = pgx.RepeatableRead
+	case usecase.Serializable:
+		txOpts.IsoLevel = pgx.Serializable
+	default:
+		return pgx.TxOptions{}, fmt.Errorf("unsupported isolation level %q", o.Isolation)
+	}
+
+	if o.ReadOnly {
+		txOpts.AccessMode = pgx.ReadOnly
+	}
+	if o.Deferrable {
+		txOpts.DeferrableMode = pgx.Deferrable
+	}
+
+	return txOpts, nil
+}
+
+func retryableCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+	switch pgErr.Code {
+	case serializationFailure, deadlockDetected:
+		return pgErr.Code, true
+	default:
+		return pgErr.Code, false
+	}
+}
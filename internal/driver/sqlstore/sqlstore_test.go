@@ -0,0 +1,202 @@
+// Package sqlstore_test ...
+package sqlstore_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+	txctx "wallet/internal/driver"
+	"wallet/internal/driver/sqlstore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/usecase"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupStore(t *testing.T) *sqlstore.Store {
+	t.Helper()
+
+	err := godotenv.Load("../../../config.env")
+	if err != nil {
+		t.Skip("env not load")
+	}
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration tests")
+	}
+
+	cfg := sqlstore.DefaultPoolConfig()
+	cfg.TxRetryBaseDelay = time.Millisecond
+	cfg.TxRetryMaxDelay = 5 * time.Millisecond
+
+	store, err := sqlstore.New(context.Background(), dsn, cfg)
+	require.NoError(t, err, "failed to connect to test database")
+
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func TestRunInTx_RetriesSerializationFailure(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	attempts := 0
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	}, usecase.WithIsolation(usecase.Serializable))
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRunInTx_RetriesExhausted(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	attempts := 0
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	}, usecase.WithMaxAttempts(4))
+
+	require.ErrorIs(t, err, walleterror.ErrTxConflict)
+	assert.Equal(t, 4, attempts)
+}
+
+type recordingMetrics struct {
+	outcomes []string
+	retries  []string
+}
+
+func (m *recordingMetrics) ObserveTx(_ time.Duration, outcome string) {
+	m.outcomes = append(m.outcomes, outcome)
+}
+
+func (m *recordingMetrics) IncTxRetry(code string) {
+	m.retries = append(m.retries, code)
+}
+
+func TestRunInTx_Metrics(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	m := &recordingMetrics{}
+	store.SetMetrics(m)
+
+	attempts := 0
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		// Вложенный вызов не считается отдельной транзакцией.
+		return store.RunInTx(ctx, func(context.Context) error { return nil })
+	})
+	require.NoError(t, err)
+
+	err = store.RunInTx(ctx, func(context.Context) error {
+		return &pgconn.PgError{Code: "40P01"}
+	}, usecase.WithMaxAttempts(2))
+	require.ErrorIs(t, err, walleterror.ErrTxConflict)
+
+	err = store.RunInTx(ctx, func(context.Context) error { return assert.AnError })
+	require.ErrorIs(t, err, assert.AnError)
+
+	assert.Equal(t, []string{sqlstore.TxCommitted, sqlstore.TxConflict, sqlstore.TxFailed}, m.outcomes)
+	assert.Equal(t, []string{"40001", "40P01"}, m.retries)
+}
+
+func TestRunInTx_DoesNotRetryOtherErrors(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	attempts := 0
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		attempts++
+		return assert.AnError
+	})
+
+	require.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRunInTx_Options(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	var readOnly, isolation string
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		tx, ok := txctx.ExtractTx(ctx)
+		require.True(t, ok)
+		if err := tx.QueryRow(ctx, `SHOW transaction_read_only`).Scan(&readOnly); err != nil {
+			return err
+		}
+		return tx.QueryRow(ctx, `SHOW transaction_isolation`).Scan(&isolation)
+	}, usecase.ReadOnly(), usecase.WithIsolation(usecase.RepeatableRead))
+
+	require.NoError(t, err)
+	assert.Equal(t, "on", readOnly)
+	assert.Equal(t, "repeatable read", isolation)
+}
+
+func TestRunInTx_UnsupportedIsolation(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		return nil
+	}, usecase.WithIsolation("bogus"))
+
+	assert.Error(t, err)
+}
+
+func TestRunInTx_NestedUsesSavepoint(t *testing.T) {
+	store := setupStore(t)
+	ctx := context.Background()
+
+	exec := func(ctx context.Context, sql string) error {
+		tx, ok := txctx.ExtractTx(ctx)
+		require.True(t, ok)
+		_, err := tx.Exec(ctx, sql)
+		return err
+	}
+
+	var count int
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		if err := exec(ctx, `CREATE TEMP TABLE nested_test (v INT) ON COMMIT DROP`); err != nil {
+			return err
+		}
+		if err := exec(ctx, `INSERT INTO nested_test VALUES (1)`); err != nil {
+			return err
+		}
+
+		// Вложенный вызов с ошибкой откатывает только свои изменения.
+		err := store.RunInTx(ctx, func(ctx context.Context) error {
+			if err := exec(ctx, `INSERT INTO nested_test VALUES (2)`); err != nil {
+				return err
+			}
+			return assert.AnError
+		})
+		require.ErrorIs(t, err, assert.AnError)
+
+		err = store.RunInTx(ctx, func(ctx context.Context) error {
+			return exec(ctx, `INSERT INTO nested_test VALUES (3)`)
+		})
+		require.NoError(t, err)
+
+		tx, _ := txctx.ExtractTx(ctx)
+		return tx.QueryRow(ctx, `SELECT COUNT(*) FROM nested_test`).Scan(&count)
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -0,0 +1,73 @@
+// Package webhook ...
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader ...
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader ...
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// Sender отправляет подписанные вебхуки по HTTP.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender ...
+func NewSender(client *http.Client) *Sender {
+	return &Sender{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Sign подпись тела вебхука: hex(HMAC-SHA256(secret, timestamp + "." + body)).
+// Метка времени входит в подпись, чтобы получатель мог отбрасывать
+// перехваченные и повторённые запросы.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send ...
+func (s *Sender) Send(ctx context.Context, url, secret string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("build request: %w", err)
+	}
+
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, "sha256="+Sign(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("send webhook: unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
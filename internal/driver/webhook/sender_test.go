@@ -0,0 +1,72 @@
+// Package webhook_test ...
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"wallet/internal/driver/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// Эталон: echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac secret
+	assert.Equal(t,
+		"49f24e537407743fa4a0242bb63b94b9a47ee99cbbe071ccd8a22550ae411686",
+		webhook.Sign("secret", 1700000000, []byte(`{"a":1}`)),
+	)
+}
+
+func TestSender_Send(t *testing.T) {
+	var signature, timestamp string
+	var body []byte
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhook.SignatureHeader)
+		timestamp = r.Header.Get(webhook.TimestampHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	s := webhook.NewSender(srv.Client())
+	code, err := s.Send(context.Background(), srv.URL, "secret", []byte(`{"a":1}`))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, `{"a":1}`, string(body))
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256="+webhook.Sign("secret", ts, body), signature)
+}
+
+func TestSender_Send_ErrorStatus(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	s := webhook.NewSender(srv.Client())
+	code, err := s.Send(context.Background(), srv.URL, "secret", []byte(`{}`))
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestSender_Send_Unreachable(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	url := srv.URL
+	client := srv.Client()
+	srv.Close()
+
+	s := webhook.NewSender(client)
+	code, err := s.Send(context.Background(), url, "secret", []byte(`{}`))
+
+	require.Error(t, err)
+	assert.Zero(t, code)
+}
@@ -1,19 +1,84 @@
 // Package error ...
 package walleterror
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrInsufficientFunds ...
 	ErrInsufficientFunds = errors.New("insufficient funds")
 	// ErrWalletNotFound ...
 	ErrWalletNotFound = errors.New("wallet not found")
+	// ErrOperationNotFound ...
+	ErrOperationNotFound = errors.New("operation not found")
+	// ErrHoldNotFound ...
+	ErrHoldNotFound = errors.New("hold not found")
+	// ErrHoldNotActive ...
+	ErrHoldNotActive = errors.New("hold is not active")
+	// ErrCaptureExceedsHold ...
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds hold")
+	// ErrWebhookNotFound ...
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWalletAlreadyExists ...
+	ErrWalletAlreadyExists = errors.New("wallet already exists")
+	// ErrUnbalancedEntry ...
+	ErrUnbalancedEntry = errors.New("journal entry does not balance")
+	// ErrTxConflict ...
+	ErrTxConflict = errors.New("transaction conflict")
 	// ErrInvalidOperationType ...
 	ErrInvalidOperationType = errors.New("invalid operation type")
 	// ErrTypeNotSpecified ...
 	ErrTypeNotSpecified = errors.New("type not specified")
 	// ErrInvalidAmount ...
 	ErrInvalidAmount = errors.New("invalid amount")
+	// ErrSameWallet ...
+	ErrSameWallet = errors.New("source and destination wallets are the same")
+	// ErrInvalidCursor ...
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidLimit ...
+	ErrInvalidLimit = errors.New("invalid limit")
+	// ErrInvalidTimeRange ...
+	ErrInvalidTimeRange = errors.New("invalid time range")
+	// ErrInvalidIdempotencyKey ...
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrIdempotencyKeyReused ...
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different request")
+	// ErrInvalidOperationID ...
+	ErrInvalidOperationID = errors.New("invalid operation id")
+	// ErrInvalidHoldID ...
+	ErrInvalidHoldID = errors.New("invalid hold id")
+	// ErrInvalidHoldTTL ...
+	ErrInvalidHoldTTL = errors.New("invalid hold ttl")
+	// ErrInvalidAsOf ...
+	ErrInvalidAsOf = errors.New("invalid asOf")
+	// ErrAsOfBeforeCreation ...
+	ErrAsOfBeforeCreation = errors.New("asOf is before wallet creation")
+	// ErrInvalidWebhookID ...
+	ErrInvalidWebhookID = errors.New("invalid webhook id")
+	// ErrInvalidWebhookURL ...
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+	// ErrInvalidEventType ...
+	ErrInvalidEventType = errors.New("invalid event type")
+	// ErrInvalidBatchSize ...
+	ErrInvalidBatchSize = errors.New("invalid batch size")
+	// ErrInvalidLastEventID ...
+	ErrInvalidLastEventID = errors.New("invalid last event id")
 	// ErrInvalidValletID ...
 	ErrInvalidValletID = errors.New("invalid wallet id")
 )
+
+// BatchItemError ошибка операции пакета с её номером в запросе.
+type BatchItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("batch operation %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
@@ -4,6 +4,7 @@ package mocks
 
 import (
 	context "context"
+	usecase "wallet/internal/usecase"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -13,17 +14,24 @@ type TxManager struct {
 	mock.Mock
 }
 
-// RunInTx provides a mock function with given fields: ctx, fn
-func (_m *TxManager) RunInTx(ctx context.Context, fn func(context.Context) error) error {
-	ret := _m.Called(ctx, fn)
+// RunInTx provides a mock function with given fields: ctx, fn, opts
+func (_m *TxManager) RunInTx(ctx context.Context, fn func(context.Context) error, opts ...usecase.TxOption) error {
+	_va := make([]interface{}, len(opts))
+	for _i := range opts {
+		_va[_i] = opts[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx, fn)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RunInTx")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error) error); ok {
-		r0 = rf(ctx, fn)
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error, ...usecase.TxOption) error); ok {
+		r0 = rf(ctx, fn, opts...)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -4,10 +4,12 @@ package mocks
 
 import (
 	context "context"
-	usecase "wallet/internal/usecase"
+	model "wallet/internal/model"
 
 	mock "github.com/stretchr/testify/mock"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -16,6 +18,115 @@ type WalletRepository struct {
 	mock.Mock
 }
 
+// ApplyOperation provides a mock function with given fields: ctx, op, entry
+func (_m *WalletRepository) ApplyOperation(ctx context.Context, op model.Operation, entry model.JournalEntry) (model.Operation, error) {
+	ret := _m.Called(ctx, op, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ApplyOperation")
+	}
+
+	var r0 model.Operation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Operation, model.JournalEntry) (model.Operation, error)); ok {
+		return rf(ctx, op, entry)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Operation, model.JournalEntry) model.Operation); ok {
+		r0 = rf(ctx, op, entry)
+	} else {
+		r0 = ret.Get(0).(model.Operation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Operation, model.JournalEntry) error); ok {
+		r1 = rf(ctx, op, entry)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimIdempotencyKey provides a mock function with given fields: ctx, key, fingerprint, ttl
+func (_m *WalletRepository) ClaimIdempotencyKey(ctx context.Context, key string, fingerprint string, ttl time.Duration) (model.IdempotencyRecord, bool, error) {
+	ret := _m.Called(ctx, key, fingerprint, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimIdempotencyKey")
+	}
+
+	var r0 model.IdempotencyRecord
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) (model.IdempotencyRecord, bool, error)); ok {
+		return rf(ctx, key, fingerprint, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) model.IdempotencyRecord); ok {
+		r0 = rf(ctx, key, fingerprint, ttl)
+	} else {
+		r0 = ret.Get(0).(model.IdempotencyRecord)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration) bool); ok {
+		r1 = rf(ctx, key, fingerprint, ttl)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, time.Duration) error); ok {
+		r2 = rf(ctx, key, fingerprint, ttl)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CreateHold provides a mock function with given fields: ctx, hold, ttl
+func (_m *WalletRepository) CreateHold(ctx context.Context, hold model.Hold, ttl time.Duration) (model.Hold, error) {
+	ret := _m.Called(ctx, hold, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateHold")
+	}
+
+	var r0 model.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Hold, time.Duration) (model.Hold, error)); ok {
+		return rf(ctx, hold, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Hold, time.Duration) model.Hold); ok {
+		r0 = rf(ctx, hold, ttl)
+	} else {
+		r0 = ret.Get(0).(model.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.Hold, time.Duration) error); ok {
+		r1 = rf(ctx, hold, ttl)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWallet provides a mock function with given fields: ctx, walletID
+func (_m *WalletRepository) CreateWallet(ctx context.Context, walletID uuid.UUID) error {
+	ret := _m.Called(ctx, walletID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWallet")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, walletID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetBalance provides a mock function with given fields: ctx, walletID
 func (_m *WalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
 	ret := _m.Called(ctx, walletID)
@@ -44,6 +155,41 @@ func (_m *WalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID)
 	return r0, r1
 }
 
+// GetBalanceAt provides a mock function with given fields: ctx, walletID, at
+func (_m *WalletRepository) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, time.Time, error) {
+	ret := _m.Called(ctx, walletID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBalanceAt")
+	}
+
+	var r0 int64
+	var r1 time.Time
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) (int64, time.Time, error)); ok {
+		return rf(ctx, walletID, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) int64); ok {
+		r0 = rf(ctx, walletID, at)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, time.Time) time.Time); ok {
+		r1 = rf(ctx, walletID, at)
+	} else {
+		r1 = ret.Get(1).(time.Time)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r2 = rf(ctx, walletID, at)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetBalanceForUpdate provides a mock function with given fields: ctx, walletID
 func (_m *WalletRepository) GetBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (int64, error) {
 	ret := _m.Called(ctx, walletID)
@@ -72,35 +218,259 @@ func (_m *WalletRepository) GetBalanceForUpdate(ctx context.Context, walletID uu
 	return r0, r1
 }
 
+// GetHeldAmount provides a mock function with given fields: ctx, walletID
+func (_m *WalletRepository) GetHeldAmount(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	ret := _m.Called(ctx, walletID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHeldAmount")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (int64, error)); ok {
+		return rf(ctx, walletID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) int64); ok {
+		r0 = rf(ctx, walletID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHoldForUpdate provides a mock function with given fields: ctx, holdID
+func (_m *WalletRepository) GetHoldForUpdate(ctx context.Context, holdID uuid.UUID) (model.Hold, error) {
+	ret := _m.Called(ctx, holdID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetHoldForUpdate")
+	}
+
+	var r0 model.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (model.Hold, error)); ok {
+		return rf(ctx, holdID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) model.Hold); ok {
+		r0 = rf(ctx, holdID)
+	} else {
+		r0 = ret.Get(0).(model.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, holdID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOperation provides a mock function with given fields: ctx, operationID
+func (_m *WalletRepository) GetOperation(ctx context.Context, operationID uuid.UUID) (model.Operation, error) {
+	ret := _m.Called(ctx, operationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOperation")
+	}
+
+	var r0 model.Operation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (model.Operation, error)); ok {
+		return rf(ctx, operationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) model.Operation); ok {
+		r0 = rf(ctx, operationID)
+	} else {
+		r0 = ret.Get(0).(model.Operation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, operationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWalletBalance provides a mock function with given fields: ctx, walletID
+func (_m *WalletRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error) {
+	ret := _m.Called(ctx, walletID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetWalletBalance")
+	}
+
+	var r0 model.WalletBalance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (model.WalletBalance, error)); ok {
+		return rf(ctx, walletID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) model.WalletBalance); ok {
+		r0 = rf(ctx, walletID)
+	} else {
+		r0 = ret.Get(0).(model.WalletBalance)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, walletID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOperations provides a mock function with given fields: ctx, filter
+func (_m *WalletRepository) ListOperations(ctx context.Context, filter model.OperationFilter) ([]model.Operation, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOperations")
+	}
+
+	var r0 []model.Operation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.OperationFilter) ([]model.Operation, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.OperationFilter) []model.Operation); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.Operation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.OperationFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NotifyWalletChanged provides a mock function with given fields: ctx, walletID
+func (_m *WalletRepository) NotifyWalletChanged(ctx context.Context, walletID uuid.UUID) error {
+	ret := _m.Called(ctx, walletID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for NotifyWalletChanged")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) error); ok {
+		r0 = rf(ctx, walletID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PostEntry provides a mock function with given fields: ctx, entry
+func (_m *WalletRepository) PostEntry(ctx context.Context, entry model.JournalEntry) error {
+	ret := _m.Called(ctx, entry)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PostEntry")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.JournalEntry) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveEvent provides a mock function with given fields: ctx, event
+func (_m *WalletRepository) SaveEvent(ctx context.Context, event model.Event) error {
+	ret := _m.Called(ctx, event)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveEvent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Event) error); ok {
+		r0 = rf(ctx, event)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveIdempotencyResponse provides a mock function with given fields: ctx, key, response
+func (_m *WalletRepository) SaveIdempotencyResponse(ctx context.Context, key string, response []byte) error {
+	ret := _m.Called(ctx, key, response)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveIdempotencyResponse")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) error); ok {
+		r0 = rf(ctx, key, response)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SaveOperation provides a mock function with given fields: ctx, op
-func (_m *WalletRepository) SaveOperation(ctx context.Context, op usecase.Operation) error {
+func (_m *WalletRepository) SaveOperation(ctx context.Context, op model.Operation) (time.Time, error) {
 	ret := _m.Called(ctx, op)
 
 	if len(ret) == 0 {
 		panic("no return value specified for SaveOperation")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, usecase.Operation) error); ok {
+	var r0 time.Time
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.Operation) (time.Time, error)); ok {
+		return rf(ctx, op)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.Operation) time.Time); ok {
 		r0 = rf(ctx, op)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(time.Time)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, model.Operation) error); ok {
+		r1 = rf(ctx, op)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// UpdateBalance provides a mock function with given fields: ctx, walletID, newBalance
-func (_m *WalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, newBalance int64) error {
-	ret := _m.Called(ctx, walletID, newBalance)
+// UpdateHold provides a mock function with given fields: ctx, hold
+func (_m *WalletRepository) UpdateHold(ctx context.Context, hold model.Hold) error {
+	ret := _m.Called(ctx, hold)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateBalance")
+		panic("no return value specified for UpdateHold")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, int64) error); ok {
-		r0 = rf(ctx, walletID, newBalance)
+	if rf, ok := ret.Get(0).(func(context.Context, model.Hold) error); ok {
+		r0 = rf(ctx, hold)
 	} else {
 		r0 = ret.Error(0)
 	}
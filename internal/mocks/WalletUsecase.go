@@ -9,6 +9,8 @@ import (
 
 	model "wallet/internal/model"
 
+	time "time"
+
 	uuid "github.com/google/uuid"
 )
 
@@ -18,22 +20,22 @@ type WalletUsecase struct {
 }
 
 // Balance provides a mock function with given fields: ctx, walletID
-func (_m *WalletUsecase) Balance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+func (_m *WalletUsecase) Balance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error) {
 	ret := _m.Called(ctx, walletID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Balance")
 	}
 
-	var r0 int64
+	var r0 model.WalletBalance
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (int64, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (model.WalletBalance, error)); ok {
 		return rf(ctx, walletID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) int64); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) model.WalletBalance); ok {
 		r0 = rf(ctx, walletID)
 	} else {
-		r0 = ret.Get(0).(int64)
+		r0 = ret.Get(0).(model.WalletBalance)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
@@ -45,40 +47,318 @@ func (_m *WalletUsecase) Balance(ctx context.Context, walletID uuid.UUID) (int64
 	return r0, r1
 }
 
+// BalanceAt provides a mock function with given fields: ctx, walletID, at
+func (_m *WalletUsecase) BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, error) {
+	ret := _m.Called(ctx, walletID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BalanceAt")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) (int64, error)); ok {
+		return rf(ctx, walletID, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, time.Time) int64); ok {
+		r0 = rf(ctx, walletID, at)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, time.Time) error); ok {
+		r1 = rf(ctx, walletID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CaptureHold provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) CaptureHold(ctx context.Context, in model.CaptureHoldInput) (model.Receipt, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CaptureHold")
+	}
+
+	var r0 model.Receipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CaptureHoldInput) (model.Receipt, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.CaptureHoldInput) model.Receipt); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Get(0).(model.Receipt)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.CaptureHoldInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateHold provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) CreateHold(ctx context.Context, in model.CreateHoldInput) (model.Hold, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateHold")
+	}
+
+	var r0 model.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CreateHoldInput) (model.Hold, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.CreateHoldInput) model.Hold); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Get(0).(model.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.CreateHoldInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateWallet provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) CreateWallet(ctx context.Context, in model.CreateWalletInput) (uuid.UUID, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateWallet")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.CreateWalletInput) (uuid.UUID, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.CreateWalletInput) uuid.UUID); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.CreateWalletInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Deposit provides a mock function with given fields: ctx, in
-func (_m *WalletUsecase) Deposit(ctx context.Context, in model.DepositInput) error {
+func (_m *WalletUsecase) Deposit(ctx context.Context, in model.DepositInput) (model.Receipt, error) {
 	ret := _m.Called(ctx, in)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Deposit")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, model.DepositInput) error); ok {
+	var r0 model.Receipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.DepositInput) (model.Receipt, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.DepositInput) model.Receipt); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Get(0).(model.Receipt)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.DepositInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExecuteBatch provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) ExecuteBatch(ctx context.Context, in model.BatchInput) ([]model.BatchResult, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExecuteBatch")
+	}
+
+	var r0 []model.BatchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.BatchInput) ([]model.BatchResult, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.BatchInput) []model.BatchResult); ok {
 		r0 = rf(ctx, in)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]model.BatchResult)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, model.BatchInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOperations provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) ListOperations(ctx context.Context, in model.ListOperationsInput) (model.OperationsPage, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOperations")
+	}
+
+	var r0 model.OperationsPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListOperationsInput) (model.OperationsPage, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.ListOperationsInput) model.OperationsPage); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Get(0).(model.OperationsPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.ListOperationsInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Operation provides a mock function with given fields: ctx, operationID
+func (_m *WalletUsecase) Operation(ctx context.Context, operationID uuid.UUID) (model.Operation, error) {
+	ret := _m.Called(ctx, operationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Operation")
+	}
+
+	var r0 model.Operation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) (model.Operation, error)); ok {
+		return rf(ctx, operationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) model.Operation); ok {
+		r0 = rf(ctx, operationID)
+	} else {
+		r0 = ret.Get(0).(model.Operation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, operationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Transfer provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) Transfer(ctx context.Context, in model.TransferInput) (uuid.UUID, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transfer")
+	}
+
+	var r0 uuid.UUID
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.TransferInput) (uuid.UUID, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.TransferInput) uuid.UUID); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(uuid.UUID)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.TransferInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VoidHold provides a mock function with given fields: ctx, in
+func (_m *WalletUsecase) VoidHold(ctx context.Context, in model.VoidHoldInput) (model.Hold, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoidHold")
+	}
+
+	var r0 model.Hold
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.VoidHoldInput) (model.Hold, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.VoidHoldInput) model.Hold); ok {
+		r0 = rf(ctx, in)
+	} else {
+		r0 = ret.Get(0).(model.Hold)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, model.VoidHoldInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Withdraw provides a mock function with given fields: ctx, in
-func (_m *WalletUsecase) Withdraw(ctx context.Context, in model.WithdrawInput) error {
+func (_m *WalletUsecase) Withdraw(ctx context.Context, in model.WithdrawInput) (model.Receipt, error) {
 	ret := _m.Called(ctx, in)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Withdraw")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, model.WithdrawInput) error); ok {
+	var r0 model.Receipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, model.WithdrawInput) (model.Receipt, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, model.WithdrawInput) model.Receipt); ok {
 		r0 = rf(ctx, in)
 	} else {
-		r0 = ret.Error(0)
+		r0 = ret.Get(0).(model.Receipt)
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, model.WithdrawInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewWalletUsecase creates a new instance of WalletUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
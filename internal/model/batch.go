@@ -0,0 +1,43 @@
+package model
+
+import "github.com/google/uuid"
+
+// BatchOperation одна операция пакета: DEPOSIT или WITHDRAW.
+type BatchOperation struct {
+	WalletID uuid.UUID
+	Type     string
+	Amount   int64
+}
+
+// BatchInput ...
+type BatchInput struct {
+	// Atomic - все операции в одной транзакции: первая ошибка откатывает пакет.
+	Atomic     bool
+	Operations []BatchOperation
+}
+
+// BatchResult результат операции пакета: квитанция или ошибка.
+type BatchResult struct {
+	Receipt Receipt
+	Err     error
+}
+
+// BatchItemError ...
+type BatchItemError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// BatchItemResponse ...
+type BatchItemResponse struct {
+	Index   int              `json:"index"`
+	Status  int              `json:"status"`
+	Receipt *ReceiptResponse `json:"receipt,omitempty"`
+	Error   *BatchItemError  `json:"error,omitempty"`
+}
+
+// BatchResponse ...
+type BatchResponse struct {
+	Atomic  bool                `json:"atomic"`
+	Results []BatchItemResponse `json:"results"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event событие об изменении баланса. Пишется в outbox в одной транзакции
+// с операцией и доставляется подписчикам в порядке Sequence.
+type Event struct {
+	// Sequence порядковый номер в outbox, задаёт порядок доставки.
+	Sequence int64
+	// ID совпадает с ID операции и служит ключом дедупликации у получателя:
+	// доставка гарантируется только «хотя бы один раз».
+	ID        uuid.UUID
+	Type      string // тип операции: "DEPOSIT", "WITHDRAW", ...
+	WalletID  uuid.UUID
+	Payload   json.RawMessage
+	Attempts  int
+	CreatedAt time.Time
+}
+
+// EventMessage ...
+type EventMessage struct {
+	ID        uuid.UUID       `json:"id"`
+	Sequence  int64           `json:"sequence"`
+	Type      string          `json:"type"`
+	WalletID  uuid.UUID       `json:"walletId"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// NewOperationEvent ...
+func NewOperationEvent(op Operation) (Event, error) {
+	payload, err := json.Marshal(NewOperationResponse(op))
+	if err != nil {
+		return Event{}, fmt.Errorf("marshal operation event: %w", err)
+	}
+
+	return Event{
+		ID:       op.ID,
+		Type:     op.Type,
+		WalletID: op.WalletID,
+		Payload:  payload,
+	}, nil
+}
+
+// Message ...
+func (e Event) Message() EventMessage {
+	return EventMessage{
+		ID:        e.ID,
+		Sequence:  e.Sequence,
+		Type:      e.Type,
+		WalletID:  e.WalletID,
+		Data:      e.Payload,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -0,0 +1,55 @@
+package model
+
+// Readiness состояние хранилища для проверки готовности.
+type Readiness struct {
+	Storage string
+	// MigrationVersion последняя применённая миграция схемы.
+	MigrationVersion int64
+	// Pool nil, если у хранилища нет пула соединений.
+	Pool *PoolStats
+}
+
+// PoolStats ...
+type PoolStats struct {
+	AcquiredConns int32
+	IdleConns     int32
+	TotalConns    int32
+	MaxConns      int32
+}
+
+// Saturation доля занятых соединений от размера пула, от 0 до 1.
+func (p PoolStats) Saturation() float64 {
+	if p.MaxConns <= 0 {
+		return 0
+	}
+	return float64(p.AcquiredConns) / float64(p.MaxConns)
+}
+
+// Статусы проверок здоровья.
+const (
+	HealthOK          = "ok"
+	HealthDraining    = "draining"
+	HealthUnavailable = "unavailable"
+)
+
+// HealthResponse ответ /healthz.
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// ReadinessResponse ответ /readyz.
+type ReadinessResponse struct {
+	Status           string        `json:"status"`
+	Storage          string        `json:"storage,omitempty"`
+	MigrationVersion int64         `json:"migrationVersion,omitempty"`
+	Pool             *PoolResponse `json:"pool,omitempty"`
+}
+
+// PoolResponse ...
+type PoolResponse struct {
+	AcquiredConns int32   `json:"acquiredConns"`
+	IdleConns     int32   `json:"idleConns"`
+	TotalConns    int32   `json:"totalConns"`
+	MaxConns      int32   `json:"maxConns"`
+	Saturation    float64 `json:"saturation"`
+}
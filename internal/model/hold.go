@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldStatus ...
+type HoldStatus string
+
+const (
+	// HoldActive ...
+	HoldActive HoldStatus = "ACTIVE"
+	// HoldCaptured ...
+	HoldCaptured HoldStatus = "CAPTURED"
+	// HoldVoided ...
+	HoldVoided HoldStatus = "VOIDED"
+	// HoldExpired активный холд с истёкшим expires_at. В базе не хранится,
+	// вычисляется при чтении.
+	HoldExpired HoldStatus = "EXPIRED"
+)
+
+// Hold резервирует часть баланса кошелька без движения денег.
+type Hold struct {
+	ID             uuid.UUID
+	WalletID       uuid.UUID
+	Amount         int64
+	Status         HoldStatus
+	CapturedAmount int64
+	OperationID    uuid.NullUUID
+	ExpiresAt      time.Time
+	CreatedAt      time.Time
+}
+
+// CreateHoldInput ...
+type CreateHoldInput struct {
+	WalletID uuid.UUID
+	Amount   int64
+	// TTL время жизни холда, 0 - значение по умолчанию.
+	TTL time.Duration
+}
+
+// CaptureHoldInput ...
+type CaptureHoldInput struct {
+	WalletID uuid.UUID
+	HoldID   uuid.UUID
+	// Amount сумма списания, 0 - весь холд.
+	Amount int64
+}
+
+// VoidHoldInput ...
+type VoidHoldInput struct {
+	WalletID uuid.UUID
+	HoldID   uuid.UUID
+}
+
+// HoldResponse ...
+type HoldResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	WalletID       uuid.UUID  `json:"walletId"`
+	Amount         int64      `json:"amount"`
+	Status         HoldStatus `json:"status"`
+	CapturedAmount int64      `json:"capturedAmount"`
+	OperationID    *uuid.UUID `json:"operationId,omitempty"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
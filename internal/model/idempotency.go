@@ -0,0 +1,8 @@
+package model
+
+// IdempotencyRecord ...
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	Response    []byte
+}
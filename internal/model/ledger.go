@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Системные счета главной книги. Создаются миграцией 0008.
+var (
+	// ExternalFundingAccountID счёт внешних поступлений и выводов.
+	ExternalFundingAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	// FeesAccountID ...
+	FeesAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+	// AdjustmentsAccountID счёт корректировок и входящих остатков.
+	AdjustmentsAccountID = uuid.MustParse("00000000-0000-0000-0000-000000000003")
+)
+
+// Posting проводка по одному счёту. Положительная сумма увеличивает баланс
+// счёта, отрицательная - уменьшает.
+type Posting struct {
+	AccountID uuid.UUID
+	Amount    int64
+}
+
+// JournalEntry ...
+type JournalEntry struct {
+	ID          uuid.UUID
+	Description string
+	Postings    []Posting
+	CreatedAt   time.Time
+}
+
+// Balanced сообщает, что в записи не меньше двух ненулевых проводок и их
+// сумма равна нулю.
+func (e JournalEntry) Balanced() bool {
+	if len(e.Postings) < 2 {
+		return false
+	}
+
+	var sum int64
+	for _, p := range e.Postings {
+		if p.Amount == 0 {
+			return false
+		}
+		sum += p.Amount
+	}
+	return sum == 0
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation ...
+type Operation struct {
+	ID         uuid.UUID
+	WalletID   uuid.UUID
+	Type       string // "DEPOSIT", "WITHDRAW", "TRANSFER_OUT" или "TRANSFER_IN"
+	Amount     int64
+	TransferID uuid.NullUUID
+	// BalanceBefore и BalanceAfter — баланс кошелька до и после операции.
+	BalanceBefore int64
+	BalanceAfter  int64
+	CreatedAt     time.Time
+	// Seq порядковый номер записи. Операции одной транзакции получают общий
+	// CreatedAt, и между собой они упорядочены по Seq так, как применялись.
+	Seq int64
+}
+
+// Receipt ...
+type Receipt struct {
+	Operation    Operation
+	BalanceAfter int64
+}
+
+// OperationCursor ...
+type OperationCursor struct {
+	CreatedAt time.Time
+	Seq       int64
+}
+
+// OperationFilter ...
+type OperationFilter struct {
+	WalletID uuid.UUID
+	Types    []string
+	From     time.Time
+	To       time.Time
+	After    *OperationCursor
+	Limit    int
+}
+
+// ListOperationsInput ...
+type ListOperationsInput struct {
+	WalletID uuid.UUID
+	Types    []string
+	From     time.Time
+	To       time.Time
+	Cursor   string
+	Limit    int
+}
+
+// OperationsPage ...
+type OperationsPage struct {
+	Operations []Operation
+	NextCursor string
+}
+
+// OperationResponse ...
+type OperationResponse struct {
+	ID            uuid.UUID  `json:"id"`
+	WalletID      uuid.UUID  `json:"walletId"`
+	Type          string     `json:"operationType"`
+	Amount        int64      `json:"amount"`
+	TransferID    *uuid.UUID `json:"transferId,omitempty"`
+	BalanceBefore int64      `json:"balanceBefore"`
+	BalanceAfter  int64      `json:"balanceAfter"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// NewOperationResponse ...
+func NewOperationResponse(o Operation) OperationResponse {
+	resp := OperationResponse{
+		ID:        o.ID,
+		WalletID:  o.WalletID,
+		Type:      o.Type,
+		Amount:    o.Amount,
+		CreatedAt: o.CreatedAt,
+
+		BalanceBefore: o.BalanceBefore,
+		BalanceAfter:  o.BalanceAfter,
+	}
+	if o.TransferID.Valid {
+		transferID := o.TransferID.UUID
+		resp.TransferID = &transferID
+	}
+	return resp
+}
+
+// ReceiptResponse ...
+type ReceiptResponse struct {
+	OperationID  uuid.UUID `json:"operationId"`
+	WalletID     uuid.UUID `json:"walletId"`
+	Type         string    `json:"operationType"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balanceAfter"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// OperationsPageResponse ...
+type OperationsPageResponse struct {
+	Operations []OperationResponse `json:"operations"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
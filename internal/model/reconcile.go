@@ -0,0 +1,23 @@
+package model
+
+import "github.com/google/uuid"
+
+// LedgerBalance ...
+type LedgerBalance struct {
+	WalletID uuid.UUID
+	// Balance значение wallets.balance.
+	Balance int64
+	// LedgerSum сумма операций кошелька со знаком.
+	LedgerSum int64
+}
+
+// Difference ...
+func (b LedgerBalance) Difference() int64 {
+	return b.Balance - b.LedgerSum
+}
+
+// ReconcileSummary ...
+type ReconcileSummary struct {
+	Checked    int
+	Mismatched int
+}
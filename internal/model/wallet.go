@@ -1,21 +1,70 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // BalanceResponse ...
 type BalanceResponse struct {
+	WalletID         uuid.UUID `json:"walletId"`
+	Balance          int64     `json:"balance"`
+	AvailableBalance int64     `json:"availableBalance"`
+}
+
+// HistoricalBalanceResponse ...
+type HistoricalBalanceResponse struct {
 	WalletID uuid.UUID `json:"walletId"`
 	Balance  int64     `json:"balance"`
+	AsOf     time.Time `json:"asOf"`
+}
+
+// WalletBalance ...
+type WalletBalance struct {
+	Balance int64
+	// AvailableBalance баланс за вычетом активных холдов.
+	AvailableBalance int64
 }
 
 // DepositInput ...
 type DepositInput struct {
-	WalletID uuid.UUID
-	Amount   int64
+	WalletID       uuid.UUID
+	Amount         int64
+	IdempotencyKey string
 }
 
 // WithdrawInput ...
 type WithdrawInput struct {
+	WalletID       uuid.UUID
+	Amount         int64
+	IdempotencyKey string
+}
+
+// CreateWalletInput ...
+type CreateWalletInput struct {
 	WalletID uuid.UUID
-	Amount   int64
+	Balance  int64
+}
+
+// TransferInput ...
+type TransferInput struct {
+	FromWalletID uuid.UUID
+	ToWalletID   uuid.UUID
+	Amount       int64
+}
+
+// TransferResponse ...
+type TransferResponse struct {
+	TransferID   uuid.UUID `json:"transferId"`
+	FromWalletID uuid.UUID `json:"fromWalletId"`
+	ToWalletID   uuid.UUID `json:"toWalletId"`
+	Amount       int64     `json:"amount"`
+}
+
+// WalletSnapshot баланс кошелька и номер последнего события в outbox,
+// прочитанные одним запросом: события после Sequence в баланс не вошли.
+type WalletSnapshot struct {
+	WalletBalance
+	Sequence int64
 }
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDeliveryStatus ...
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending ...
+	WebhookDeliveryPending WebhookDeliveryStatus = "PENDING"
+	// WebhookDeliveryDelivered ...
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// WebhookDeliveryDead попытки исчерпаны, доставка больше не повторяется.
+	WebhookDeliveryDead WebhookDeliveryStatus = "DEAD"
+)
+
+// Webhook подписка на события. Пустые EventTypes и WalletIDs означают
+// «все типы» и «все кошельки».
+type Webhook struct {
+	ID         uuid.UUID
+	URL        string
+	EventTypes []string
+	WalletIDs  []uuid.UUID
+	Secret     string
+	CreatedAt  time.Time
+}
+
+// CreateWebhookInput ...
+type CreateWebhookInput struct {
+	URL        string
+	EventTypes []string
+	WalletIDs  []uuid.UUID
+	// Secret ключ подписи. Пустой - сервер сгенерирует его сам.
+	Secret string
+}
+
+// WebhookDelivery доставка одного события одной подписке.
+type WebhookDelivery struct {
+	ID        int64
+	WebhookID uuid.UUID
+	URL       string
+	Secret    string
+	EventID   uuid.UUID
+	EventType string
+	Payload   json.RawMessage
+	Attempts  int
+}
+
+// WebhookAttempt одна попытка доставки.
+type WebhookAttempt struct {
+	ID         int64
+	DeliveryID int64
+	WebhookID  uuid.UUID
+	EventID    uuid.UUID
+	EventType  string
+	Attempt    int
+	// StatusCode ответ получателя, 0 - ответа не было.
+	StatusCode int
+	Error      string
+	Duration   time.Duration
+	// Status состояние доставки после этой попытки.
+	Status    WebhookDeliveryStatus
+	CreatedAt time.Time
+}
+
+// WebhookResponse ...
+type WebhookResponse struct {
+	ID         uuid.UUID   `json:"id"`
+	URL        string      `json:"url"`
+	EventTypes []string    `json:"eventTypes"`
+	WalletIDs  []uuid.UUID `json:"walletIds"`
+	// Secret отдаётся только при создании подписки.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhooksResponse ...
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookAttemptResponse ...
+type WebhookAttemptResponse struct {
+	ID         int64                 `json:"id"`
+	DeliveryID int64                 `json:"deliveryId"`
+	EventID    uuid.UUID             `json:"eventId"`
+	EventType  string                `json:"eventType"`
+	Attempt    int                   `json:"attempt"`
+	StatusCode int                   `json:"statusCode,omitempty"`
+	Error      string                `json:"error,omitempty"`
+	DurationMs int64                 `json:"durationMs"`
+	Status     WebhookDeliveryStatus `json:"status"`
+	CreatedAt  time.Time             `json:"createdAt"`
+}
+
+// WebhookAttemptsResponse ...
+type WebhookAttemptsResponse struct {
+	Attempts []WebhookAttemptResponse `json:"attempts"`
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/port/middleware"
+	"wallet/internal/validation"
+
+	"github.com/google/uuid"
+)
+
+func (h *walletHandler) HandleBatch() http.HandlerFunc {
+	const op = "walletHandler.HandleBatch"
+	type item struct {
+		WalletID      uuid.UUID `json:"walletId"`
+		OperationType string    `json:"operationType"`
+		Amount        int64     `json:"amount"`
+	}
+	type req struct {
+		Atomic     bool   `json:"atomic"`
+		Operations []item `json:"operations"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+		)
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.With(
+					slog.String("err", err.Error()),
+				).Warn("body close with error")
+			}
+		}()
+
+		req := &req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		log.Info("processing batch",
+			slog.Bool("atomic", req.Atomic),
+			slog.Int("operations", len(req.Operations)),
+		)
+
+		// Невалидная операция отклоняет весь пакет в любом режиме: это ошибка
+		// запроса, а не результата выполнения.
+		in := model.BatchInput{
+			Atomic:     req.Atomic,
+			Operations: make([]model.BatchOperation, 0, len(req.Operations)),
+		}
+		for i, item := range req.Operations {
+			t, err := validation.ValidationOperationType(item.OperationType)
+			if err == nil && item.Amount <= 0 {
+				err = walleterror.ErrInvalidAmount
+			}
+			if err == nil && item.WalletID == uuid.Nil {
+				err = walleterror.ErrInvalidValletID
+			}
+			if err != nil {
+				h.batchError(w, r, op, req.Atomic, &walleterror.BatchItemError{Index: i, Err: err})
+				return
+			}
+
+			in.Operations = append(in.Operations, model.BatchOperation{
+				WalletID: item.WalletID,
+				Type:     t,
+				Amount:   item.Amount,
+			})
+		}
+
+		ctx := r.Context()
+
+		results, err := h.walletUsecase.ExecuteBatch(ctx, in)
+		if err != nil {
+			h.batchError(w, r, op, req.Atomic, err)
+			return
+		}
+
+		resp := model.BatchResponse{
+			Atomic:  req.Atomic,
+			Results: make([]model.BatchItemResponse, 0, len(results)),
+		}
+		for i, res := range results {
+			if res.Err != nil {
+				resp.Results = append(resp.Results, h.batchItemError(i, res.Err))
+				continue
+			}
+
+			receipt := toReceiptResponse(res.Receipt)
+			resp.Results = append(resp.Results, model.BatchItemResponse{
+				Index:   i,
+				Status:  http.StatusOK,
+				Receipt: &receipt,
+			})
+		}
+
+		h.server.Respond(w, r, http.StatusOK, resp)
+	}
+}
+
+// batchError отвечает ошибкой, из-за которой пакет не выполнен. Если она
+// относится к конкретной операции, код ответа - код этой операции, а в
+// results только она.
+func (h *walletHandler) batchError(w http.ResponseWriter, r *http.Request, op string, atomic bool, err error) {
+	var itemErr *walleterror.BatchItemError
+	if !errors.As(err, &itemErr) {
+		h.server.Error(w, r, op, err)
+		return
+	}
+
+	h.server.Logger().With(
+		slog.String("op", op),
+		slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+	).Warn(err.Error())
+
+	item := h.batchItemError(itemErr.Index, itemErr.Err)
+	h.server.Respond(w, r, item.Status, model.BatchResponse{
+		Atomic:  atomic,
+		Results: []model.BatchItemResponse{item},
+	})
+}
+
+func (h *walletHandler) batchItemError(index int, err error) model.BatchItemResponse {
+	code, resp := h.server.MapError(err)
+	return model.BatchItemResponse{
+		Index:  index,
+		Status: code,
+		Error: &model.BatchItemError{
+			Code:    resp.Code,
+			Message: resp.Message,
+		},
+	}
+}
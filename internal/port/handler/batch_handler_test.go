@@ -0,0 +1,178 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/port/handler"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const batchURL = "/api/v1/operations:batch"
+
+func batchReceipt(walletID uuid.UUID, opType string, amount, balanceAfter int64) model.Receipt {
+	return model.Receipt{
+		Operation: model.Operation{
+			ID:       uuid.New(),
+			WalletID: walletID,
+			Type:     opType,
+			Amount:   amount,
+		},
+		BalanceAfter: balanceAfter,
+	}
+}
+
+// --- HandleBatch ---
+
+func TestHandleBatch_AtomicSuccess(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.New()
+
+	uc.
+		On("ExecuteBatch", mock.Anything, model.BatchInput{
+			Atomic: true,
+			Operations: []model.BatchOperation{
+				{WalletID: walletID, Type: "DEPOSIT", Amount: 100},
+				{WalletID: walletID, Type: "WITHDRAW", Amount: 40},
+			},
+		}).
+		Return([]model.BatchResult{
+			{Receipt: batchReceipt(walletID, "DEPOSIT", 100, 100)},
+			{Receipt: batchReceipt(walletID, "WITHDRAW", 40, 60)},
+		}, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleBatch(), http.MethodPost, batchURL, map[string]any{
+		"atomic": true,
+		"operations": []map[string]any{
+			{"walletId": walletID, "operationType": "DEPOSIT", "amount": 100},
+			{"walletId": walletID, "operationType": "WITHDRAW", "amount": 40},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.BatchResponse
+	decodeBody(t, rr, &resp)
+	assert.True(t, resp.Atomic)
+	require.Len(t, resp.Results, 2)
+	assert.Equal(t, 1, resp.Results[1].Index)
+	assert.Equal(t, http.StatusOK, resp.Results[1].Status)
+	require.NotNil(t, resp.Results[1].Receipt)
+	assert.Equal(t, int64(60), resp.Results[1].Receipt.BalanceAfter)
+	assert.Nil(t, resp.Results[1].Error)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleBatch_AtomicFailure(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.New()
+
+	uc.
+		On("ExecuteBatch", mock.Anything, mock.Anything).
+		Return(nil, &walleterror.BatchItemError{Index: 1, Err: walleterror.ErrInsufficientFunds})
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleBatch(), http.MethodPost, batchURL, map[string]any{
+		"atomic": true,
+		"operations": []map[string]any{
+			{"walletId": walletID, "operationType": "DEPOSIT", "amount": 100},
+			{"walletId": walletID, "operationType": "WITHDRAW", "amount": 1000},
+		},
+	})
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	var resp model.BatchResponse
+	decodeBody(t, rr, &resp)
+	require.Len(t, resp.Results, 1)
+	assert.Equal(t, 1, resp.Results[0].Index)
+	assert.Equal(t, http.StatusConflict, resp.Results[0].Status)
+	require.NotNil(t, resp.Results[0].Error)
+	assert.Equal(t, "insufficient funds", resp.Results[0].Error.Message)
+}
+
+func TestHandleBatch_NonAtomicPerItemStatus(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.New()
+
+	uc.
+		On("ExecuteBatch", mock.Anything, mock.MatchedBy(func(in model.BatchInput) bool {
+			return !in.Atomic && len(in.Operations) == 3
+		})).
+		Return([]model.BatchResult{
+			{Receipt: batchReceipt(walletID, "DEPOSIT", 100, 100)},
+			{Err: walleterror.ErrWalletNotFound},
+			{Err: walleterror.ErrInsufficientFunds},
+		}, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleBatch(), http.MethodPost, batchURL, map[string]any{
+		"operations": []map[string]any{
+			{"walletId": walletID, "operationType": "DEPOSIT", "amount": 100},
+			{"walletId": uuid.New(), "operationType": "DEPOSIT", "amount": 100},
+			{"walletId": walletID, "operationType": "WITHDRAW", "amount": 1000},
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.BatchResponse
+	decodeBody(t, rr, &resp)
+	assert.False(t, resp.Atomic)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, http.StatusOK, resp.Results[0].Status)
+	assert.Equal(t, http.StatusNotFound, resp.Results[1].Status)
+	assert.Equal(t, "NOT_FOUND", resp.Results[1].Error.Code)
+	assert.Nil(t, resp.Results[1].Receipt)
+	assert.Equal(t, http.StatusConflict, resp.Results[2].Status)
+}
+
+func TestHandleBatch_InvalidOperation(t *testing.T) {
+	walletID := uuid.New()
+	tests := []struct {
+		name string
+		item map[string]any
+	}{
+		{"transfer type", map[string]any{"walletId": walletID, "operationType": "TRANSFER_OUT", "amount": 1}},
+		{"zero amount", map[string]any{"walletId": walletID, "operationType": "DEPOSIT", "amount": 0}},
+		{"nil wallet", map[string]any{"walletId": uuid.Nil, "operationType": "DEPOSIT", "amount": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(mocks.WalletUsecase)
+
+			h := handler.NewWalletHandler(uc, newTestServer())
+			rr := sendRequest(t, h.HandleBatch(), http.MethodPost, batchURL, map[string]any{
+				"operations": []map[string]any{
+					{"walletId": walletID, "operationType": "DEPOSIT", "amount": 1},
+					tt.item,
+				},
+			})
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+			var resp model.BatchResponse
+			decodeBody(t, rr, &resp)
+			require.Len(t, resp.Results, 1)
+			assert.Equal(t, 1, resp.Results[0].Index)
+			uc.AssertNotCalled(t, "ExecuteBatch")
+		})
+	}
+}
+
+func TestHandleBatch_InvalidSize(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	uc.On("ExecuteBatch", mock.Anything, mock.Anything).Return(nil, walleterror.ErrInvalidBatchSize)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleBatch(), http.MethodPost, batchURL, map[string]any{"atomic": true})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
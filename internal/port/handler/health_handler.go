@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+	"wallet/internal/model"
+	"wallet/internal/port"
+	"wallet/internal/port/middleware"
+)
+
+// readinessTimeout сколько ждать ответа хранилища в /readyz.
+const readinessTimeout = 2 * time.Second
+
+type ReadinessProbe interface {
+	// Readiness ...
+	Readiness(ctx context.Context) (model.Readiness, error)
+}
+
+type healthHandler struct {
+	probe    ReadinessProbe
+	server   *port.ServerAPI
+	draining atomic.Bool
+}
+
+// NewHealthHandler ...
+func NewHealthHandler(probe ReadinessProbe, server *port.ServerAPI) *healthHandler {
+	return &healthHandler{
+		probe:  probe,
+		server: server,
+	}
+}
+
+// Drain переводит /readyz в 503, чтобы балансировщик перестал слать
+// трафик до остановки сервера. Отменить нельзя.
+func (h *healthHandler) Drain() {
+	h.draining.Store(true)
+}
+
+// HandleLiveness отвечает 200, пока процесс обслуживает запросы. Хранилище
+// не проверяется: его недоступность не лечится перезапуском.
+func (h *healthHandler) HandleLiveness() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.server.Respond(w, r, http.StatusOK, model.HealthResponse{Status: model.HealthOK})
+	}
+}
+
+// HandleReadiness проверяет хранилище и отдаёт версию схемы и загрузку пула
+// соединений. 503 - если хранилище не отвечает или сервис останавливается.
+func (h *healthHandler) HandleReadiness() http.HandlerFunc {
+	const op = "healthHandler.HandleReadiness"
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.draining.Load() {
+			h.server.Respond(w, r, http.StatusServiceUnavailable, model.ReadinessResponse{Status: model.HealthDraining})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+		defer cancel()
+
+		readiness, err := h.probe.Readiness(ctx)
+		if err != nil {
+			h.server.Logger().With(
+				slog.String("op", op),
+				slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			).Warn("storage is not ready", slog.String("err", err.Error()))
+
+			// Текст ошибки драйвера только в логе: в нём бывают адрес и имя базы.
+			h.server.Respond(w, r, http.StatusServiceUnavailable, model.ReadinessResponse{Status: model.HealthUnavailable})
+			return
+		}
+
+		resp := model.ReadinessResponse{
+			Status:           model.HealthOK,
+			Storage:          readiness.Storage,
+			MigrationVersion: readiness.MigrationVersion,
+		}
+		if p := readiness.Pool; p != nil {
+			resp.Pool = &model.PoolResponse{
+				AcquiredConns: p.AcquiredConns,
+				IdleConns:     p.IdleConns,
+				TotalConns:    p.TotalConns,
+				MaxConns:      p.MaxConns,
+				Saturation:    p.Saturation(),
+			}
+		}
+
+		h.server.Respond(w, r, http.StatusOK, resp)
+	}
+}
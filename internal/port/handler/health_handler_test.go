@@ -0,0 +1,94 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/port/handler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeReadiness(t *testing.T, rr *httptest.ResponseRecorder) model.ReadinessResponse {
+	t.Helper()
+
+	var resp model.ReadinessResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&resp))
+	return resp
+}
+
+// --- Health ---
+
+func TestHandleLiveness(t *testing.T) {
+	probe := new(mocks.ReadinessProbe)
+	h := handler.NewHealthHandler(probe, newTestServer())
+
+	rr := sendRequest(t, h.HandleLiveness(), http.MethodGet, "/healthz", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rr.Body.String())
+	probe.AssertNotCalled(t, "Readiness", mock.Anything)
+}
+
+func TestHandleReadiness_Ready(t *testing.T) {
+	probe := new(mocks.ReadinessProbe)
+	probe.On("Readiness", mock.Anything).Return(model.Readiness{
+		Storage:          "postgres",
+		MigrationVersion: 7,
+		Pool:             &model.PoolStats{AcquiredConns: 5, IdleConns: 15, TotalConns: 20, MaxConns: 25},
+	}, nil)
+	h := handler.NewHealthHandler(probe, newTestServer())
+
+	rr := sendRequest(t, h.HandleReadiness(), http.MethodGet, "/readyz", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, model.ReadinessResponse{
+		Status:           model.HealthOK,
+		Storage:          "postgres",
+		MigrationVersion: 7,
+		Pool: &model.PoolResponse{
+			AcquiredConns: 5,
+			IdleConns:     15,
+			TotalConns:    20,
+			MaxConns:      25,
+			Saturation:    0.2,
+		},
+	}, decodeReadiness(t, rr))
+}
+
+func TestHandleReadiness_StorageUnavailable(t *testing.T) {
+	probe := new(mocks.ReadinessProbe)
+	probe.On("Readiness", mock.Anything).Return(model.Readiness{}, errors.New("ping database: connection refused"))
+	h := handler.NewHealthHandler(probe, newTestServer())
+
+	rr := sendRequest(t, h.HandleReadiness(), http.MethodGet, "/readyz", nil)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.JSONEq(t, `{"status":"unavailable"}`, rr.Body.String())
+}
+
+func TestHandleReadiness_Draining(t *testing.T) {
+	probe := new(mocks.ReadinessProbe)
+	probe.On("Readiness", mock.Anything).Return(model.Readiness{Storage: "memory"}, nil)
+	h := handler.NewHealthHandler(probe, newTestServer())
+
+	rr := sendRequest(t, h.HandleReadiness(), http.MethodGet, "/readyz", nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	h.Drain()
+
+	rr = sendRequest(t, h.HandleReadiness(), http.MethodGet, "/readyz", nil)
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, model.HealthDraining, decodeReadiness(t, rr).Status)
+	probe.AssertNumberOfCalls(t, "Readiness", 1)
+
+	// Живость при остановке не меняется.
+	rr = sendRequest(t, h.HandleLiveness(), http.MethodGet, "/healthz", nil)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/port/middleware"
+
+	"github.com/google/uuid"
+)
+
+func (h *walletHandler) HandleCreateHold() http.HandlerFunc {
+	const op = "walletHandler.HandleCreateHold"
+	type req struct {
+		Amount     int64 `json:"amount"`
+		TTLSeconds int64 `json:"ttlSeconds"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || walletID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidValletID)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("walletID", walletID.String()),
+		)
+		log.Info("creating hold")
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.With(
+					slog.String("err", err.Error()),
+				).Warn("body close with error")
+			}
+		}()
+
+		req := &req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		if req.Amount <= 0 {
+			h.server.Error(w, r, op, walleterror.ErrInvalidAmount)
+			return
+		}
+		if req.TTLSeconds < 0 {
+			h.server.Error(w, r, op, walleterror.ErrInvalidHoldTTL)
+			return
+		}
+
+		ctx := r.Context()
+
+		hold, err := h.walletUsecase.CreateHold(ctx, model.CreateHoldInput{
+			WalletID: walletID,
+			Amount:   req.Amount,
+			TTL:      time.Duration(req.TTLSeconds) * time.Second,
+		})
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		h.server.Respond(w, r, http.StatusCreated, toHoldResponse(hold))
+	}
+}
+
+func (h *walletHandler) HandleCaptureHold() http.HandlerFunc {
+	const op = "walletHandler.HandleCaptureHold"
+	type req struct {
+		Amount int64 `json:"amount"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, holdID, err := holdPathValues(r)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("holdID", holdID.String()),
+		)
+		log.Info("capturing hold")
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.With(
+					slog.String("err", err.Error()),
+				).Warn("body close with error")
+			}
+		}()
+
+		// Пустое тело означает списание всего холда.
+		req := &req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		if req.Amount < 0 {
+			h.server.Error(w, r, op, walleterror.ErrInvalidAmount)
+			return
+		}
+
+		ctx := r.Context()
+
+		receipt, err := h.walletUsecase.CaptureHold(ctx, model.CaptureHoldInput{
+			WalletID: walletID,
+			HoldID:   holdID,
+			Amount:   req.Amount,
+		})
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		h.respondReceipt(w, r, receipt)
+	}
+}
+
+func (h *walletHandler) HandleVoidHold() http.HandlerFunc {
+	const op = "walletHandler.HandleVoidHold"
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, holdID, err := holdPathValues(r)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("holdID", holdID.String()),
+		)
+		log.Info("voiding hold")
+
+		ctx := r.Context()
+
+		hold, err := h.walletUsecase.VoidHold(ctx, model.VoidHoldInput{
+			WalletID: walletID,
+			HoldID:   holdID,
+		})
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		h.server.Respond(w, r, http.StatusOK, toHoldResponse(hold))
+	}
+}
+
+func holdPathValues(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	walletID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil || walletID == uuid.Nil {
+		return uuid.Nil, uuid.Nil, walleterror.ErrInvalidValletID
+	}
+
+	holdID, err := uuid.Parse(r.PathValue("holdId"))
+	if err != nil || holdID == uuid.Nil {
+		return uuid.Nil, uuid.Nil, walleterror.ErrInvalidHoldID
+	}
+
+	return walletID, holdID, nil
+}
+
+func toHoldResponse(hold model.Hold) model.HoldResponse {
+	resp := model.HoldResponse{
+		ID:             hold.ID,
+		WalletID:       hold.WalletID,
+		Amount:         hold.Amount,
+		Status:         hold.Status,
+		CapturedAmount: hold.CapturedAmount,
+		ExpiresAt:      hold.ExpiresAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+	if hold.OperationID.Valid {
+		operationID := hold.OperationID.UUID
+		resp.OperationID = &operationID
+	}
+	return resp
+}
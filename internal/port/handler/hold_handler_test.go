@@ -0,0 +1,217 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/port/handler"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newHoldMux(uc handler.WalletUsecase) *http.ServeMux {
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/v1/wallets/{id}/holds", h.HandleCreateHold())
+	mux.Handle("POST /api/v1/wallets/{id}/holds/{holdId}/capture", h.HandleCaptureHold())
+	mux.Handle("POST /api/v1/wallets/{id}/holds/{holdId}/void", h.HandleVoidHold())
+	return mux
+}
+
+func serveJSON(t *testing.T, mux http.Handler, url string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, url, &reqBody)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+// --- HandleCreateHold ---
+
+func TestHandleCreateHold_Success(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	holdID := uuid.New()
+	expiresAt := time.Date(2025, 1, 1, 12, 5, 0, 0, time.UTC)
+
+	uc.
+		On("CreateHold", mock.Anything, model.CreateHoldInput{
+			WalletID: walletID,
+			Amount:   300,
+			TTL:      5 * time.Minute,
+		}).
+		Return(model.Hold{
+			ID:        holdID,
+			WalletID:  walletID,
+			Amount:    300,
+			Status:    model.HoldActive,
+			ExpiresAt: expiresAt,
+		}, nil)
+
+	rr := serveJSON(t, newHoldMux(uc), "/api/v1/wallets/"+walletID.String()+"/holds", map[string]any{
+		"amount":     300,
+		"ttlSeconds": 300,
+	})
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var resp model.HoldResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, holdID, resp.ID)
+	assert.Equal(t, model.HoldActive, resp.Status)
+	assert.Equal(t, expiresAt, resp.ExpiresAt)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCreateHold_InvalidAmount(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	rr := serveJSON(t, newHoldMux(uc), "/api/v1/wallets/"+walletID.String()+"/holds", map[string]any{
+		"amount": 0,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "CreateHold")
+}
+
+func TestHandleCreateHold_InsufficientFunds(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	uc.
+		On("CreateHold", mock.Anything, mock.Anything).
+		Return(model.Hold{}, walleterror.ErrInsufficientFunds)
+
+	rr := serveJSON(t, newHoldMux(uc), "/api/v1/wallets/"+walletID.String()+"/holds", map[string]any{
+		"amount": 300,
+	})
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+// --- HandleCaptureHold ---
+
+func TestHandleCaptureHold_Partial(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	holdID := uuid.New()
+	opID := uuid.New()
+
+	uc.
+		On("CaptureHold", mock.Anything, model.CaptureHoldInput{
+			WalletID: walletID,
+			HoldID:   holdID,
+			Amount:   100,
+		}).
+		Return(model.Receipt{
+			Operation:    model.Operation{ID: opID, WalletID: walletID, Type: "WITHDRAW", Amount: 100},
+			BalanceAfter: 900,
+		}, nil)
+
+	rr := serveJSON(t, newHoldMux(uc),
+		"/api/v1/wallets/"+walletID.String()+"/holds/"+holdID.String()+"/capture",
+		map[string]any{"amount": 100},
+	)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "/api/v1/operations/"+opID.String(), rr.Header().Get("Location"))
+
+	var resp model.ReceiptResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, "WITHDRAW", resp.Type)
+	assert.Equal(t, int64(900), resp.BalanceAfter)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCaptureHold_EmptyBodyCapturesAll(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	holdID := uuid.New()
+
+	uc.
+		On("CaptureHold", mock.Anything, model.CaptureHoldInput{WalletID: walletID, HoldID: holdID}).
+		Return(model.Receipt{}, nil)
+
+	rr := serveJSON(t, newHoldMux(uc),
+		"/api/v1/wallets/"+walletID.String()+"/holds/"+holdID.String()+"/capture", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCaptureHold_Errors(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{name: "not found", err: walleterror.ErrHoldNotFound, code: http.StatusNotFound},
+		{name: "not active", err: walleterror.ErrHoldNotActive, code: http.StatusConflict},
+		{name: "exceeds hold", err: walleterror.ErrCaptureExceedsHold, code: http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc := new(mocks.WalletUsecase)
+			walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+			uc.
+				On("CaptureHold", mock.Anything, mock.Anything).
+				Return(model.Receipt{}, tc.err)
+
+			rr := serveJSON(t, newHoldMux(uc),
+				"/api/v1/wallets/"+walletID.String()+"/holds/"+uuid.NewString()+"/capture", nil)
+
+			assert.Equal(t, tc.code, rr.Code)
+		})
+	}
+}
+
+func TestHandleCaptureHold_InvalidHoldID(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	rr := serveJSON(t, newHoldMux(uc), "/api/v1/wallets/"+walletID.String()+"/holds/bad/capture", nil)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "CaptureHold")
+}
+
+// --- HandleVoidHold ---
+
+func TestHandleVoidHold_Success(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	holdID := uuid.New()
+
+	uc.
+		On("VoidHold", mock.Anything, model.VoidHoldInput{WalletID: walletID, HoldID: holdID}).
+		Return(model.Hold{ID: holdID, WalletID: walletID, Amount: 300, Status: model.HoldVoided}, nil)
+
+	rr := serveJSON(t, newHoldMux(uc),
+		"/api/v1/wallets/"+walletID.String()+"/holds/"+holdID.String()+"/void", nil)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.HoldResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, model.HoldVoided, resp.Status)
+	uc.AssertExpectations(t)
+}
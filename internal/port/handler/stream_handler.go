@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/port"
+	"wallet/internal/port/middleware"
+
+	"github.com/google/uuid"
+)
+
+// LastEventIDHeader ...
+const LastEventIDHeader = "Last-Event-ID"
+
+const (
+	// streamRetry через сколько миллисекунд клиент переподключается после обрыва.
+	streamRetry = 3000
+	// streamWriteTimeout сколько может длиться одна запись в поток.
+	streamWriteTimeout = 10 * time.Second
+)
+
+type StreamUsecase interface {
+	// Subscribe ...
+	Subscribe(ctx context.Context, walletID uuid.UUID) (model.WalletSnapshot, <-chan struct{}, func(), error)
+	// EventsAfter ...
+	EventsAfter(ctx context.Context, walletID uuid.UUID, afterSequence int64) ([]model.Event, error)
+}
+
+type streamHandler struct {
+	streamUsecase StreamUsecase
+	server        *port.ServerAPI
+	heartbeat     time.Duration
+	done          <-chan struct{}
+}
+
+// NewStreamHandler ... Открытые потоки закрываются, когда закрыт done.
+func NewStreamHandler(streamUsecase StreamUsecase, server *port.ServerAPI, heartbeat time.Duration, done <-chan struct{}) *streamHandler {
+	return &streamHandler{
+		streamUsecase: streamUsecase,
+		server:        server,
+		heartbeat:     heartbeat,
+		done:          done,
+	}
+}
+
+// HandleWalletEvents отдаёт поток Server-Sent Events по кошельку. Новый
+// клиент получает событие balance со снимком баланса, дальше - события
+// operation. id события - его номер в outbox: при переподключении с
+// Last-Event-ID поток продолжается с места обрыва без снимка.
+func (h *streamHandler) HandleWalletEvents() http.HandlerFunc {
+	const op = "streamHandler.HandleWalletEvents"
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || walletID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidValletID)
+			return
+		}
+
+		var (
+			lastEventID int64
+			resume      bool
+		)
+		if v := r.Header.Get(LastEventIDHeader); v != "" {
+			lastEventID, err = strconv.ParseInt(v, 10, 64)
+			if err != nil || lastEventID < 0 {
+				h.server.Error(w, r, op, walleterror.ErrInvalidLastEventID)
+				return
+			}
+			resume = true
+		}
+
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("walletID", walletID.String()),
+		)
+
+		ctx := r.Context()
+
+		snapshot, notify, unsubscribe, err := h.streamUsecase.Subscribe(ctx, walletID)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+		defer unsubscribe()
+
+		log.Info("stream opened", slog.Bool("resume", resume), slog.Int64("lastEventID", lastEventID))
+		defer log.Info("stream closed")
+
+		stream := newEventStream(w)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if err := stream.send(fmt.Sprintf("retry: %d\n\n", streamRetry)); err != nil {
+			log.Warn("stream write failed", slog.String("err", err.Error()))
+			return
+		}
+
+		cursor := lastEventID
+		if !resume {
+			data, err := json.Marshal(model.BalanceResponse{
+				WalletID:         walletID,
+				Balance:          snapshot.Balance,
+				AvailableBalance: snapshot.AvailableBalance,
+			})
+			if err != nil {
+				log.Warn("marshal snapshot", slog.String("err", err.Error()))
+				return
+			}
+			if err := stream.event(snapshot.Sequence, "balance", data); err != nil {
+				log.Warn("stream write failed", slog.String("err", err.Error()))
+				return
+			}
+			cursor = snapshot.Sequence
+		}
+
+		heartbeat := time.NewTicker(h.heartbeat)
+		defer heartbeat.Stop()
+
+		// Сразу после подписки дочитываем то, что могло появиться после
+		// снимка или Last-Event-ID.
+		pending := true
+		for {
+			if pending {
+				cursor, err = h.sendEventsAfter(ctx, stream, walletID, cursor)
+				if err != nil {
+					if ctx.Err() == nil {
+						log.Warn("stream events failed", slog.String("err", err.Error()))
+					}
+					return
+				}
+				pending = false
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-h.done:
+				return
+			case <-notify:
+				pending = true
+			case <-heartbeat.C:
+				if err := stream.send(": heartbeat\n\n"); err != nil {
+					log.Warn("stream write failed", slog.String("err", err.Error()))
+					return
+				}
+			}
+		}
+	}
+}
+
+// sendEventsAfter отправляет все события после cursor и возвращает номер
+// последнего отправленного.
+func (h *streamHandler) sendEventsAfter(ctx context.Context, stream *eventStream, walletID uuid.UUID, cursor int64) (int64, error) {
+	for {
+		events, err := h.streamUsecase.EventsAfter(ctx, walletID, cursor)
+		if err != nil {
+			return cursor, err
+		}
+		if len(events) == 0 {
+			return cursor, nil
+		}
+
+		for _, e := range events {
+			data, err := json.Marshal(e.Message())
+			if err != nil {
+				return cursor, err
+			}
+			if err := stream.event(e.Sequence, "operation", data); err != nil {
+				return cursor, err
+			}
+			cursor = e.Sequence
+		}
+	}
+}
+
+// eventStream пишет в ответ сообщения SSE и сразу отправляет их клиенту.
+// WriteTimeout сервера рассчитан на обычные запросы, поэтому дедлайн
+// сдвигается перед каждой записью: поток живёт сколько угодно, но зависшая
+// запись всё равно обрывается.
+type eventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newEventStream(w http.ResponseWriter) *eventStream {
+	return &eventStream{w: w, rc: http.NewResponseController(w)}
+}
+
+func (s *eventStream) event(id int64, name string, data []byte) error {
+	return s.send(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", id, name, data))
+}
+
+func (s *eventStream) send(msg string) error {
+	err := s.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
@@ -0,0 +1,274 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/port/handler"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamServer поднимает настоящий сервер: потоку нужны Flush и дедлайны
+// соединения, которых нет у httptest.ResponseRecorder.
+func newStreamServer(t *testing.T, uc handler.StreamUsecase, heartbeat, timeout time.Duration, done <-chan struct{}) *httptest.Server {
+	t.Helper()
+
+	h := handler.NewStreamHandler(uc, newTestServer(), heartbeat, done)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/wallets/{id}/events", h.HandleWalletEvents())
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Config.ReadTimeout = timeout
+	srv.Config.WriteTimeout = timeout
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// openStream открывает поток и отдаёт его сообщения по одному в канал.
+func openStream(t *testing.T, srv *httptest.Server, walletID uuid.UUID, lastEventID string) (*http.Response, <-chan string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/v1/wallets/"+walletID.String()+"/events", nil)
+	require.NoError(t, err)
+	if lastEventID != "" {
+		req.Header.Set(handler.LastEventIDHeader, lastEventID)
+	}
+
+	resp, err := srv.Client().Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	frames := make(chan string)
+	go func() {
+		defer close(frames)
+		sc := bufio.NewScanner(resp.Body)
+		var frame []string
+		for sc.Scan() {
+			if sc.Text() != "" {
+				frame = append(frame, sc.Text())
+				continue
+			}
+			frames <- strings.Join(frame, "\n")
+			frame = nil
+		}
+	}()
+
+	return resp, frames
+}
+
+func nextFrame(t *testing.T, frames <-chan string) string {
+	t.Helper()
+
+	select {
+	case f, ok := <-frames:
+		require.True(t, ok, "stream closed")
+		return f
+	case <-time.After(2 * time.Second):
+		t.Fatal("no frame from stream")
+		return ""
+	}
+}
+
+// nextEvent пропускает heartbeat и возвращает следующее событие.
+func nextEvent(t *testing.T, frames <-chan string) string {
+	t.Helper()
+
+	for {
+		if f := nextFrame(t, frames); !strings.HasPrefix(f, ":") {
+			return f
+		}
+	}
+}
+
+func streamEvent(seq int64, walletID uuid.UUID) model.Event {
+	return model.Event{
+		Sequence: seq,
+		ID:       uuid.New(),
+		Type:     "DEPOSIT",
+		WalletID: walletID,
+		Payload:  json.RawMessage(`{"amount":100}`),
+	}
+}
+
+// --- HandleWalletEvents ---
+
+func TestHandleWalletEvents_SnapshotThenEvents(t *testing.T) {
+	uc := new(mocks.StreamUsecase)
+	walletID := uuid.New()
+	notify := make(chan struct{}, 1)
+
+	uc.
+		On("Subscribe", mock.Anything, walletID).
+		Return(model.WalletSnapshot{
+			WalletBalance: model.WalletBalance{Balance: 500, AvailableBalance: 400},
+			Sequence:      5,
+		}, (<-chan struct{})(notify), func() {}, nil)
+	uc.On("EventsAfter", mock.Anything, walletID, int64(5)).Return([]model.Event{}, nil).Once()
+	uc.On("EventsAfter", mock.Anything, walletID, int64(5)).Return([]model.Event{streamEvent(6, walletID)}, nil).Once()
+	uc.On("EventsAfter", mock.Anything, walletID, int64(6)).Return([]model.Event{}, nil)
+
+	srv := newStreamServer(t, uc, time.Minute, time.Minute, nil)
+	resp, frames := openStream(t, srv, walletID, "")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+	assert.Equal(t, "retry: 3000", nextFrame(t, frames))
+	assert.Equal(t,
+		`id: 5`+"\n"+`event: balance`+"\n"+
+			`data: {"walletId":"`+walletID.String()+`","balance":500,"availableBalance":400}`,
+		nextFrame(t, frames),
+	)
+
+	notify <- struct{}{}
+
+	frame := nextFrame(t, frames)
+	lines := strings.Split(frame, "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id: 6", lines[0])
+	assert.Equal(t, "event: operation", lines[1])
+
+	var msg model.EventMessage
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &msg))
+	assert.Equal(t, int64(6), msg.Sequence)
+	assert.Equal(t, "DEPOSIT", msg.Type)
+	assert.JSONEq(t, `{"amount":100}`, string(msg.Data))
+}
+
+func TestHandleWalletEvents_ResumeFromLastEventID(t *testing.T) {
+	uc := new(mocks.StreamUsecase)
+	walletID := uuid.New()
+
+	uc.
+		On("Subscribe", mock.Anything, walletID).
+		Return(model.WalletSnapshot{Sequence: 9}, (<-chan struct{})(make(chan struct{})), func() {}, nil)
+	uc.
+		On("EventsAfter", mock.Anything, walletID, int64(3)).
+		Return([]model.Event{streamEvent(4, walletID), streamEvent(7, walletID)}, nil)
+	uc.On("EventsAfter", mock.Anything, walletID, int64(7)).Return([]model.Event{}, nil)
+
+	srv := newStreamServer(t, uc, time.Minute, time.Minute, nil)
+	_, frames := openStream(t, srv, walletID, "3")
+
+	assert.Equal(t, "retry: 3000", nextFrame(t, frames))
+	// Снимка нет: клиент продолжает с места обрыва.
+	assert.True(t, strings.HasPrefix(nextFrame(t, frames), "id: 4\nevent: operation\n"))
+	assert.True(t, strings.HasPrefix(nextFrame(t, frames), "id: 7\nevent: operation\n"))
+}
+
+func TestHandleWalletEvents_OutlivesWriteTimeout(t *testing.T) {
+	uc := new(mocks.StreamUsecase)
+	walletID := uuid.New()
+	notify := make(chan struct{}, 1)
+
+	uc.
+		On("Subscribe", mock.Anything, walletID).
+		Return(model.WalletSnapshot{Sequence: 1}, (<-chan struct{})(notify), func() {}, nil)
+	uc.On("EventsAfter", mock.Anything, walletID, int64(1)).Return([]model.Event{}, nil).Once()
+	uc.On("EventsAfter", mock.Anything, walletID, int64(1)).Return([]model.Event{streamEvent(2, walletID)}, nil).Once()
+	uc.On("EventsAfter", mock.Anything, walletID, int64(2)).Return([]model.Event{}, nil)
+
+	srv := newStreamServer(t, uc, 20*time.Millisecond, 100*time.Millisecond, nil)
+	_, frames := openStream(t, srv, walletID, "")
+
+	nextFrame(t, frames)
+	nextFrame(t, frames)
+
+	// Поток переживает WriteTimeout сервера.
+	deadline := time.After(300 * time.Millisecond)
+	for heartbeats := 0; ; heartbeats++ {
+		select {
+		case <-deadline:
+			assert.Positive(t, heartbeats)
+			notify <- struct{}{}
+			assert.True(t, strings.HasPrefix(nextEvent(t, frames), "id: 2\n"))
+			return
+		default:
+			assert.Equal(t, ": heartbeat", nextFrame(t, frames))
+		}
+	}
+}
+
+func TestHandleWalletEvents_ClosedOnShutdown(t *testing.T) {
+	uc := new(mocks.StreamUsecase)
+	walletID := uuid.New()
+	done := make(chan struct{})
+
+	unsubscribed := make(chan struct{})
+	uc.
+		On("Subscribe", mock.Anything, walletID).
+		Return(model.WalletSnapshot{}, (<-chan struct{})(make(chan struct{})), func() { close(unsubscribed) }, nil)
+	uc.On("EventsAfter", mock.Anything, walletID, int64(0)).Return([]model.Event{}, nil)
+
+	srv := newStreamServer(t, uc, time.Minute, time.Minute, done)
+	_, frames := openStream(t, srv, walletID, "")
+
+	nextFrame(t, frames)
+	nextFrame(t, frames)
+
+	close(done)
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not closed")
+	}
+	for range frames {
+	}
+}
+
+func TestHandleWalletEvents_Errors(t *testing.T) {
+	walletID := uuid.New()
+	tests := []struct {
+		name        string
+		walletID    string
+		lastEventID string
+		err         error
+		code        int
+	}{
+		{"invalid wallet id", "not-a-uuid", "", nil, http.StatusBadRequest},
+		{"invalid last event id", walletID.String(), "abc", nil, http.StatusBadRequest},
+		{"negative last event id", walletID.String(), "-1", nil, http.StatusBadRequest},
+		{"not found", walletID.String(), "", walleterror.ErrWalletNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(mocks.StreamUsecase)
+			if tt.err != nil {
+				uc.
+					On("Subscribe", mock.Anything, walletID).
+					Return(model.WalletSnapshot{}, (<-chan struct{})(nil), (func())(nil), tt.err)
+			}
+
+			h := handler.NewStreamHandler(uc, newTestServer(), time.Minute, nil)
+			mux := http.NewServeMux()
+			mux.Handle("GET /api/v1/wallets/{id}/events", h.HandleWalletEvents())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+tt.walletID+"/events", nil)
+			if tt.lastEventID != "" {
+				req.Header.Set(handler.LastEventIDHeader, tt.lastEventID)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			if tt.err == nil {
+				uc.AssertNotCalled(t, "Subscribe")
+			}
+		})
+	}
+}
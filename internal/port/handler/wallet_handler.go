@@ -4,8 +4,12 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 	walleterror "wallet/internal/error"
 	"wallet/internal/model"
 	"wallet/internal/port"
@@ -15,13 +19,36 @@ import (
 	"github.com/google/uuid"
 )
 
+// IdempotencyKeyHeader ...
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const maxIdempotencyKeyLen = 255
+
 type WalletUsecase interface {
+	// CreateWallet ...
+	CreateWallet(ctx context.Context, in model.CreateWalletInput) (uuid.UUID, error)
 	// Deposit ...
-	Deposit(ctx context.Context, in model.DepositInput) error
+	Deposit(ctx context.Context, in model.DepositInput) (model.Receipt, error)
 	// Withdraw ...
-	Withdraw(ctx context.Context, in model.WithdrawInput) error
+	Withdraw(ctx context.Context, in model.WithdrawInput) (model.Receipt, error)
+	// Transfer ...
+	Transfer(ctx context.Context, in model.TransferInput) (uuid.UUID, error)
 	// Balance ...
-	Balance(ctx context.Context, walletID uuid.UUID) (int64, error)
+	Balance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error)
+	// BalanceAt ...
+	BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, error)
+	// Operation ...
+	Operation(ctx context.Context, operationID uuid.UUID) (model.Operation, error)
+	// ListOperations ...
+	ListOperations(ctx context.Context, in model.ListOperationsInput) (model.OperationsPage, error)
+	// CreateHold ...
+	CreateHold(ctx context.Context, in model.CreateHoldInput) (model.Hold, error)
+	// CaptureHold ...
+	CaptureHold(ctx context.Context, in model.CaptureHoldInput) (model.Receipt, error)
+	// VoidHold ...
+	VoidHold(ctx context.Context, in model.VoidHoldInput) (model.Hold, error)
+	// ExecuteBatch ...
+	ExecuteBatch(ctx context.Context, in model.BatchInput) ([]model.BatchResult, error)
 }
 
 type walletHandler struct {
@@ -37,6 +64,61 @@ func NewWalletHandler(walletUsecase WalletUsecase, server *port.ServerAPI) *wall
 	}
 }
 
+func (h *walletHandler) HandleCreateWallet() http.HandlerFunc {
+	const op = "walletHandler.HandleCreateWallet"
+	type req struct {
+		WalletID uuid.UUID `json:"walletId"`
+		Balance  int64     `json:"balance"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+		)
+		log.Info("creating wallet")
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.With(
+					slog.String("err", err.Error()),
+				).Warn("body close with error")
+			}
+		}()
+
+		req := &req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		if req.Balance < 0 {
+			h.server.Error(w, r, op, walleterror.ErrInvalidAmount)
+			return
+		}
+
+		ctx := r.Context()
+
+		walletID, err := h.walletUsecase.CreateWallet(ctx, model.CreateWalletInput{
+			WalletID: req.WalletID,
+			Balance:  req.Balance,
+		})
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		walletDTO := model.BalanceResponse{
+			WalletID:         walletID,
+			Balance:          req.Balance,
+			AvailableBalance: req.Balance,
+		}
+
+		w.Header().Set("Location", "/api/v1/wallets/"+walletID.String())
+		h.server.Respond(w, r, http.StatusCreated, walletDTO)
+	}
+}
+
 func (h *walletHandler) HandleOperation() http.HandlerFunc {
 	const op = "walletHandler.HandleOperation"
 	type req struct {
@@ -80,30 +162,40 @@ func (h *walletHandler) HandleOperation() http.HandlerFunc {
 			return
 		}
 
+		idempotencyKey := r.Header.Get(IdempotencyKeyHeader)
+		if len(idempotencyKey) > maxIdempotencyKeyLen {
+			h.server.Error(w, r, op, walleterror.ErrInvalidIdempotencyKey)
+			return
+		}
+
 		ctx := r.Context()
 
 		switch t {
 		case validation.DepositType:
 			dep := model.DepositInput{
-				WalletID: req.ValletId,
-				Amount:   req.Amount,
+				WalletID:       req.ValletId,
+				Amount:         req.Amount,
+				IdempotencyKey: idempotencyKey,
 			}
-			if err := h.walletUsecase.Deposit(ctx, dep); err != nil {
+			receipt, err := h.walletUsecase.Deposit(ctx, dep)
+			if err != nil {
 				h.server.Error(w, r, op, err)
 				return
 			}
-			w.WriteHeader(http.StatusOK)
+			h.respondReceipt(w, r, receipt)
 			return
 		case validation.WithdrawType:
 			wdraw := model.WithdrawInput{
-				WalletID: req.ValletId,
-				Amount:   req.Amount,
+				WalletID:       req.ValletId,
+				Amount:         req.Amount,
+				IdempotencyKey: idempotencyKey,
 			}
-			if err := h.walletUsecase.Withdraw(ctx, wdraw); err != nil {
+			receipt, err := h.walletUsecase.Withdraw(ctx, wdraw)
+			if err != nil {
 				h.server.Error(w, r, op, err)
 				return
 			}
-			w.WriteHeader(http.StatusOK)
+			h.respondReceipt(w, r, receipt)
 			return
 		default:
 			h.server.Error(w, r, op, nil)
@@ -112,6 +204,83 @@ func (h *walletHandler) HandleOperation() http.HandlerFunc {
 	}
 }
 
+func (h *walletHandler) respondReceipt(w http.ResponseWriter, r *http.Request, receipt model.Receipt) {
+	w.Header().Set("Location", "/api/v1/operations/"+receipt.Operation.ID.String())
+	h.server.Respond(w, r, http.StatusOK, toReceiptResponse(receipt))
+}
+
+func toReceiptResponse(receipt model.Receipt) model.ReceiptResponse {
+	return model.ReceiptResponse{
+		OperationID:  receipt.Operation.ID,
+		WalletID:     receipt.Operation.WalletID,
+		Type:         receipt.Operation.Type,
+		Amount:       receipt.Operation.Amount,
+		BalanceAfter: receipt.BalanceAfter,
+		CreatedAt:    receipt.Operation.CreatedAt,
+	}
+}
+
+func (h *walletHandler) HandleTransfer() http.HandlerFunc {
+	const op = "walletHandler.HandleTransfer"
+	type req struct {
+		FromWalletID uuid.UUID `json:"fromWalletId"`
+		ToWalletID   uuid.UUID `json:"toWalletId"`
+		Amount       int64     `json:"amount"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+		)
+		log.Info("processing transfer")
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.With(
+					slog.String("err", err.Error()),
+				).Warn("body close with error")
+			}
+		}()
+
+		req := &req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		if req.Amount <= 0 {
+			h.server.Error(w, r, op, walleterror.ErrInvalidAmount)
+			return
+		}
+		if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidValletID)
+			return
+		}
+
+		ctx := r.Context()
+
+		transferID, err := h.walletUsecase.Transfer(ctx, model.TransferInput{
+			FromWalletID: req.FromWalletID,
+			ToWalletID:   req.ToWalletID,
+			Amount:       req.Amount,
+		})
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		transferDTO := model.TransferResponse{
+			TransferID:   transferID,
+			FromWalletID: req.FromWalletID,
+			ToWalletID:   req.ToWalletID,
+			Amount:       req.Amount,
+		}
+
+		h.server.Respond(w, r, http.StatusCreated, transferDTO)
+	}
+}
+
 func (h *walletHandler) HandleGetBalance() http.HandlerFunc {
 	const op = "walletHandler.HandleGetBalance"
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -136,6 +305,27 @@ func (h *walletHandler) HandleGetBalance() http.HandlerFunc {
 
 		ctx := r.Context()
 
+		if v := r.URL.Query().Get("asOf"); v != "" {
+			asOf, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				h.server.Error(w, r, op, walleterror.ErrInvalidAsOf)
+				return
+			}
+
+			balance, err := h.walletUsecase.BalanceAt(ctx, walletID, asOf)
+			if err != nil {
+				h.server.Error(w, r, op, err)
+				return
+			}
+
+			h.server.Respond(w, r, http.StatusOK, model.HistoricalBalanceResponse{
+				WalletID: walletID,
+				Balance:  balance,
+				AsOf:     asOf,
+			})
+			return
+		}
+
 		balance, err := h.walletUsecase.Balance(ctx, walletID)
 		if err != nil {
 			h.server.Error(w, r, op, err)
@@ -143,10 +333,114 @@ func (h *walletHandler) HandleGetBalance() http.HandlerFunc {
 		}
 
 		balanceDTO := model.BalanceResponse{
-			WalletID: walletID,
-			Balance:  balance,
+			WalletID:         walletID,
+			Balance:          balance.Balance,
+			AvailableBalance: balance.AvailableBalance,
 		}
 
 		h.server.Respond(w, r, http.StatusOK, balanceDTO)
 	}
 }
+
+func (h *walletHandler) HandleListOperations() http.HandlerFunc {
+	const op = "walletHandler.HandleListOperations"
+	return func(w http.ResponseWriter, r *http.Request) {
+		walletID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || walletID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidValletID)
+			return
+		}
+
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("walletID", walletID.String()),
+		)
+
+		log.Info("list operations")
+
+		query := r.URL.Query()
+
+		in := model.ListOperationsInput{
+			WalletID: walletID,
+			Cursor:   query.Get("cursor"),
+		}
+
+		in.Types, err = validation.ValidationOperationTypeFilter(query["type"])
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		if v := query.Get("limit"); v != "" {
+			in.Limit, err = strconv.Atoi(v)
+			if err != nil || in.Limit <= 0 {
+				h.server.Error(w, r, op, walleterror.ErrInvalidLimit)
+				return
+			}
+		}
+
+		if v := query.Get("from"); v != "" {
+			in.From, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				h.server.Error(w, r, op, walleterror.ErrInvalidTimeRange)
+				return
+			}
+		}
+
+		if v := query.Get("to"); v != "" {
+			in.To, err = time.Parse(time.RFC3339, v)
+			if err != nil {
+				h.server.Error(w, r, op, walleterror.ErrInvalidTimeRange)
+				return
+			}
+		}
+
+		ctx := r.Context()
+
+		page, err := h.walletUsecase.ListOperations(ctx, in)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		pageDTO := model.OperationsPageResponse{
+			Operations: make([]model.OperationResponse, 0, len(page.Operations)),
+			NextCursor: page.NextCursor,
+		}
+		for _, o := range page.Operations {
+			pageDTO.Operations = append(pageDTO.Operations, model.NewOperationResponse(o))
+		}
+
+		h.server.Respond(w, r, http.StatusOK, pageDTO)
+	}
+}
+
+func (h *walletHandler) HandleGetOperation() http.HandlerFunc {
+	const op = "walletHandler.HandleGetOperation"
+	return func(w http.ResponseWriter, r *http.Request) {
+		operationID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || operationID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidOperationID)
+			return
+		}
+
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("operationID", operationID.String()),
+		)
+
+		log.Info("get operation")
+
+		ctx := r.Context()
+
+		o, err := h.walletUsecase.Operation(ctx, operationID)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		h.server.Respond(w, r, http.StatusOK, model.NewOperationResponse(o))
+	}
+}
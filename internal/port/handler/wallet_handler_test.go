@@ -8,7 +8,9 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 	walleterror "wallet/internal/error"
 	"wallet/internal/mocks"
 	"wallet/internal/model"
@@ -59,7 +61,7 @@ func TestHandleGetBalance_Success(t *testing.T) {
 
 	uc.
 		On("Balance", mock.Anything, walletID).
-		Return(expectedBalance, nil)
+		Return(model.WalletBalance{Balance: expectedBalance, AvailableBalance: 700}, nil)
 
 	h := handler.NewWalletHandler(uc, newTestServer())
 
@@ -76,6 +78,7 @@ func TestHandleGetBalance_Success(t *testing.T) {
 	decodeBody(t, rr, &resp)
 	assert.Equal(t, walletID, resp.WalletID)
 	assert.Equal(t, expectedBalance, resp.Balance)
+	assert.Equal(t, int64(700), resp.AvailableBalance)
 	uc.AssertExpectations(t)
 }
 
@@ -100,7 +103,7 @@ func TestHandleGetBalance_WalletNotFound(t *testing.T) {
 
 	uc.
 		On("Balance", mock.Anything, walletID).
-		Return(int64(0), walleterror.ErrWalletNotFound)
+		Return(model.WalletBalance{}, walleterror.ErrWalletNotFound)
 
 	h := handler.NewWalletHandler(uc, newTestServer())
 
@@ -119,18 +122,91 @@ func TestHandleGetBalance_WalletNotFound(t *testing.T) {
 	uc.AssertExpectations(t)
 }
 
+// --- HandleGetBalance / asOf ---
+
+func TestHandleGetBalance_AsOf(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	asOf := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	uc.
+		On("BalanceAt", mock.Anything, walletID, asOf).
+		Return(int64(750), nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/wallets/{id}", h.HandleGetBalance())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID.String()+"?asOf=2025-01-31T23:59:59Z", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.HistoricalBalanceResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, int64(750), resp.Balance)
+	assert.True(t, asOf.Equal(resp.AsOf))
+	uc.AssertNotCalled(t, "Balance")
+	uc.AssertExpectations(t)
+}
+
+func TestHandleGetBalance_AsOfErrors(t *testing.T) {
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	cases := []struct {
+		name  string
+		query string
+		err   error
+	}{
+		{name: "not RFC3339", query: "2025-01-31"},
+		{name: "before creation", query: "2000-01-01T00:00:00Z", err: walleterror.ErrAsOfBeforeCreation},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			uc := new(mocks.WalletUsecase)
+			if tc.err != nil {
+				uc.On("BalanceAt", mock.Anything, walletID, mock.Anything).Return(int64(0), tc.err)
+			}
+
+			h := handler.NewWalletHandler(uc, newTestServer())
+
+			mux := http.NewServeMux()
+			mux.Handle("GET /api/v1/wallets/{id}", h.HandleGetBalance())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID.String()+"?asOf="+tc.query, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
 // --- HandleOperation / DEPOSIT ---
 
 func TestHandleOperation_Deposit_Success(t *testing.T) {
 	uc := new(mocks.WalletUsecase)
 	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	receipt := model.Receipt{
+		Operation: model.Operation{
+			ID:        uuid.New(),
+			WalletID:  walletID,
+			Type:      "DEPOSIT",
+			Amount:    500,
+			CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		BalanceAfter: 1500,
+	}
 
 	uc.
 		On("Deposit", mock.Anything, model.DepositInput{
 			WalletID: walletID,
 			Amount:   500,
 		}).
-		Return(nil)
+		Return(receipt, nil)
 
 	h := handler.NewWalletHandler(uc, newTestServer())
 	rr := sendRequest(t, h.HandleOperation(), http.MethodPost, "/api/v1/wallet", map[string]any{
@@ -140,6 +216,18 @@ func TestHandleOperation_Deposit_Success(t *testing.T) {
 	})
 
 	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "/api/v1/operations/"+receipt.Operation.ID.String(), rr.Header().Get("Location"))
+
+	var resp model.ReceiptResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, model.ReceiptResponse{
+		OperationID:  receipt.Operation.ID,
+		WalletID:     walletID,
+		Type:         "DEPOSIT",
+		Amount:       500,
+		BalanceAfter: 1500,
+		CreatedAt:    receipt.Operation.CreatedAt,
+	}, resp)
 	uc.AssertExpectations(t)
 }
 
@@ -152,7 +240,7 @@ func TestHandleOperation_Deposit_WalletNotFound(t *testing.T) {
 			WalletID: walletID,
 			Amount:   500,
 		}).
-		Return(walleterror.ErrWalletNotFound)
+		Return(model.Receipt{}, walleterror.ErrWalletNotFound)
 
 	h := handler.NewWalletHandler(uc, newTestServer())
 	rr := sendRequest(t, h.HandleOperation(), http.MethodPost, "/api/v1/wallet", map[string]any{
@@ -176,7 +264,7 @@ func TestHandleOperation_Withdraw_Success(t *testing.T) {
 			WalletID: walletID,
 			Amount:   200,
 		}).
-		Return(nil)
+		Return(model.Receipt{}, nil)
 
 	h := handler.NewWalletHandler(uc, newTestServer())
 	rr := sendRequest(t, h.HandleOperation(), http.MethodPost, "/api/v1/wallet", map[string]any{
@@ -198,7 +286,7 @@ func TestHandleOperation_Withdraw_InsufficientFunds(t *testing.T) {
 			WalletID: walletID,
 			Amount:   99999,
 		}).
-		Return(walleterror.ErrInsufficientFunds)
+		Return(model.Receipt{}, walleterror.ErrInsufficientFunds)
 
 	h := handler.NewWalletHandler(uc, newTestServer())
 	rr := sendRequest(t, h.HandleOperation(), http.MethodPost, "/api/v1/wallet", map[string]any{
@@ -275,3 +363,432 @@ func TestHandleOperation_InvalidBody(t *testing.T) {
 	uc.AssertNotCalled(t, "Deposit")
 	uc.AssertNotCalled(t, "Withdraw")
 }
+
+// --- HandleCreateWallet ---
+
+func TestHandleCreateWallet_Success(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	uc.
+		On("CreateWallet", mock.Anything, model.CreateWalletInput{
+			WalletID: walletID,
+			Balance:  1000,
+		}).
+		Return(walletID, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleCreateWallet(), http.MethodPost, "/api/v1/wallets", map[string]any{
+		"walletId": walletID,
+		"balance":  1000,
+	})
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "/api/v1/wallets/"+walletID.String(), rr.Header().Get("Location"))
+
+	var resp model.BalanceResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, walletID, resp.WalletID)
+	assert.Equal(t, int64(1000), resp.Balance)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCreateWallet_EmptyBody(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	uc.
+		On("CreateWallet", mock.Anything, model.CreateWalletInput{}).
+		Return(walletID, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleCreateWallet(), http.MethodPost, "/api/v1/wallets", nil)
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var resp model.BalanceResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, walletID, resp.WalletID)
+	assert.Equal(t, int64(0), resp.Balance)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCreateWallet_AlreadyExists(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	uc.
+		On("CreateWallet", mock.Anything, model.CreateWalletInput{WalletID: walletID}).
+		Return(uuid.Nil, walleterror.ErrWalletAlreadyExists)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleCreateWallet(), http.MethodPost, "/api/v1/wallets", map[string]any{
+		"walletId": walletID,
+	})
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	var resp port.ErrorResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, "CONFLICT", resp.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCreateWallet_NegativeBalance(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleCreateWallet(), http.MethodPost, "/api/v1/wallets", map[string]any{
+		"balance": -1,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "CreateWallet")
+}
+
+// --- HandleTransfer ---
+
+func TestHandleTransfer_Success(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	from := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	to := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	transferID := uuid.New()
+
+	uc.
+		On("Transfer", mock.Anything, model.TransferInput{
+			FromWalletID: from,
+			ToWalletID:   to,
+			Amount:       300,
+		}).
+		Return(transferID, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleTransfer(), http.MethodPost, "/api/v1/transfers", map[string]any{
+		"fromWalletId": from,
+		"toWalletId":   to,
+		"amount":       300,
+	})
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	var resp model.TransferResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, transferID, resp.TransferID)
+	assert.Equal(t, from, resp.FromWalletID)
+	assert.Equal(t, to, resp.ToWalletID)
+	assert.Equal(t, int64(300), resp.Amount)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleTransfer_InsufficientFunds(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	from := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	to := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	uc.
+		On("Transfer", mock.Anything, model.TransferInput{
+			FromWalletID: from,
+			ToWalletID:   to,
+			Amount:       99999,
+		}).
+		Return(uuid.Nil, walleterror.ErrInsufficientFunds)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleTransfer(), http.MethodPost, "/api/v1/transfers", map[string]any{
+		"fromWalletId": from,
+		"toWalletId":   to,
+		"amount":       99999,
+	})
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	var resp port.ErrorResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, "CONFLICT", resp.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleTransfer_SameWallet(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	uc.
+		On("Transfer", mock.Anything, model.TransferInput{
+			FromWalletID: walletID,
+			ToWalletID:   walletID,
+			Amount:       10,
+		}).
+		Return(uuid.Nil, walleterror.ErrSameWallet)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleTransfer(), http.MethodPost, "/api/v1/transfers", map[string]any{
+		"fromWalletId": walletID,
+		"toWalletId":   walletID,
+		"amount":       10,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleTransfer_InvalidAmount(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+	rr := sendRequest(t, h.HandleTransfer(), http.MethodPost, "/api/v1/transfers", map[string]any{
+		"fromWalletId": uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		"toWalletId":   uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		"amount":       0,
+	})
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "Transfer")
+}
+
+// --- HandleListOperations ---
+
+func TestHandleListOperations_Success(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	transferID := uuid.New()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ops := []model.Operation{
+		{
+			ID:        uuid.New(),
+			WalletID:  walletID,
+			Type:      "DEPOSIT",
+			Amount:    100,
+			CreatedAt: from.Add(time.Hour),
+
+			BalanceBefore: 50,
+			BalanceAfter:  150,
+		},
+		{
+			ID:         uuid.New(),
+			WalletID:   walletID,
+			Type:       "TRANSFER_IN",
+			Amount:     50,
+			TransferID: uuid.NullUUID{UUID: transferID, Valid: true},
+			CreatedAt:  from.Add(time.Minute),
+		},
+	}
+
+	uc.
+		On("ListOperations", mock.Anything, model.ListOperationsInput{
+			WalletID: walletID,
+			Types:    []string{"DEPOSIT", "TRANSFER_IN"},
+			From:     from,
+			Cursor:   "abc",
+			Limit:    2,
+		}).
+		Return(model.OperationsPage{Operations: ops, NextCursor: "next"}, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/wallets/{id}/operations", h.HandleListOperations())
+
+	url := "/api/v1/wallets/" + walletID.String() +
+		"/operations?type=DEPOSIT,TRANSFER_IN&from=2025-01-01T00:00:00Z&cursor=abc&limit=2"
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.OperationsPageResponse
+	decodeBody(t, rr, &resp)
+	require.Len(t, resp.Operations, 2)
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.Equal(t, ops[0].ID, resp.Operations[0].ID)
+	assert.Equal(t, int64(50), resp.Operations[0].BalanceBefore)
+	assert.Equal(t, int64(150), resp.Operations[0].BalanceAfter)
+	assert.Nil(t, resp.Operations[0].TransferID)
+	require.NotNil(t, resp.Operations[1].TransferID)
+	assert.Equal(t, transferID, *resp.Operations[1].TransferID)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleListOperations_InvalidQuery(t *testing.T) {
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	for _, query := range []string{"type=BOGUS", "limit=abc", "limit=-1", "from=yesterday", "to=2025-13-01"} {
+		t.Run(query, func(t *testing.T) {
+			uc := new(mocks.WalletUsecase)
+			h := handler.NewWalletHandler(uc, newTestServer())
+
+			mux := http.NewServeMux()
+			mux.Handle("GET /api/v1/wallets/{id}/operations", h.HandleListOperations())
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/"+walletID.String()+"/operations?"+query, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			uc.AssertNotCalled(t, "ListOperations")
+		})
+	}
+}
+
+// --- HandleOperation / Idempotency-Key ---
+
+func TestHandleOperation_IdempotencyKey(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	uc.
+		On("Deposit", mock.Anything, model.DepositInput{
+			WalletID:       walletID,
+			Amount:         500,
+			IdempotencyKey: "key-1",
+		}).
+		Return(model.Receipt{}, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	body, err := json.Marshal(map[string]any{
+		"valletId":      walletID,
+		"operationType": "DEPOSIT",
+		"amount":        500,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handler.IdempotencyKeyHeader, "key-1")
+	rr := httptest.NewRecorder()
+	h.HandleOperation().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleOperation_IdempotencyKeyReused(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	uc.
+		On("Withdraw", mock.Anything, mock.Anything).
+		Return(model.Receipt{}, walleterror.ErrIdempotencyKeyReused)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	body, err := json.Marshal(map[string]any{
+		"valletId":      walletID,
+		"operationType": "WITHDRAW",
+		"amount":        10,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", bytes.NewReader(body))
+	req.Header.Set(handler.IdempotencyKeyHeader, "key-1")
+	rr := httptest.NewRecorder()
+	h.HandleOperation().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleOperation_IdempotencyKeyTooLong(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	body, err := json.Marshal(map[string]any{
+		"valletId":      walletID,
+		"operationType": "DEPOSIT",
+		"amount":        10,
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/wallet", bytes.NewReader(body))
+	req.Header.Set(handler.IdempotencyKeyHeader, strings.Repeat("k", 256))
+	rr := httptest.NewRecorder()
+	h.HandleOperation().ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "Deposit")
+}
+
+// --- HandleGetOperation ---
+
+func TestHandleGetOperation_Success(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	operation := model.Operation{
+		ID:         uuid.New(),
+		WalletID:   uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Type:       "TRANSFER_OUT",
+		Amount:     300,
+		TransferID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		CreatedAt:  time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	uc.
+		On("Operation", mock.Anything, operation.ID).
+		Return(operation, nil)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/operations/{id}", h.HandleGetOperation())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/"+operation.ID.String(), nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.OperationResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, operation.ID, resp.ID)
+	assert.Equal(t, operation.WalletID, resp.WalletID)
+	assert.Equal(t, "TRANSFER_OUT", resp.Type)
+	assert.Equal(t, int64(300), resp.Amount)
+	require.NotNil(t, resp.TransferID)
+	assert.Equal(t, operation.TransferID.UUID, *resp.TransferID)
+	assert.Equal(t, operation.CreatedAt, resp.CreatedAt)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleGetOperation_NotFound(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	operationID := uuid.New()
+
+	uc.
+		On("Operation", mock.Anything, operationID).
+		Return(model.Operation{}, walleterror.ErrOperationNotFound)
+
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/operations/{id}", h.HandleGetOperation())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/"+operationID.String(), nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	var resp port.ErrorResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, "NOT_FOUND", resp.Code)
+	assert.Equal(t, "operation not found", resp.Message)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleGetOperation_InvalidUUID(t *testing.T) {
+	uc := new(mocks.WalletUsecase)
+	h := handler.NewWalletHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /api/v1/operations/{id}", h.HandleGetOperation())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/operations/not-a-uuid", nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "Operation")
+}
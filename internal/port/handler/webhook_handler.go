@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/port"
+	"wallet/internal/port/middleware"
+	"wallet/internal/validation"
+
+	"github.com/google/uuid"
+)
+
+type WebhookUsecase interface {
+	// CreateWebhook ...
+	CreateWebhook(ctx context.Context, in model.CreateWebhookInput) (model.Webhook, error)
+	// Webhooks ...
+	Webhooks(ctx context.Context) ([]model.Webhook, error)
+	// DeleteWebhook ...
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	// WebhookAttempts ...
+	WebhookAttempts(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookAttempt, error)
+}
+
+type webhookHandler struct {
+	webhookUsecase WebhookUsecase
+	server         *port.ServerAPI
+}
+
+// NewWebhookHandler ...
+func NewWebhookHandler(webhookUsecase WebhookUsecase, server *port.ServerAPI) *webhookHandler {
+	return &webhookHandler{
+		webhookUsecase: webhookUsecase,
+		server:         server,
+	}
+}
+
+func (h *webhookHandler) HandleCreateWebhook() http.HandlerFunc {
+	const op = "webhookHandler.HandleCreateWebhook"
+	type req struct {
+		URL        string      `json:"url"`
+		EventTypes []string    `json:"eventTypes"`
+		WalletIDs  []uuid.UUID `json:"walletIds"`
+		Secret     string      `json:"secret"`
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+		)
+		log.Info("creating webhook")
+
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				log.With(
+					slog.String("err", err.Error()),
+				).Warn("body close with error")
+			}
+		}()
+
+		req := &req{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		url, err := validation.ValidationWebhookURL(req.URL)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		eventTypes, err := validation.ValidationEventTypes(req.EventTypes)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		for _, id := range req.WalletIDs {
+			if id == uuid.Nil {
+				h.server.Error(w, r, op, walleterror.ErrInvalidValletID)
+				return
+			}
+		}
+
+		ctx := r.Context()
+
+		hook, err := h.webhookUsecase.CreateWebhook(ctx, model.CreateWebhookInput{
+			URL:        url,
+			EventTypes: eventTypes,
+			WalletIDs:  req.WalletIDs,
+			Secret:     req.Secret,
+		})
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		// Секрет отдаётся один раз: в списке подписок его нет.
+		resp := toWebhookResponse(hook)
+		resp.Secret = hook.Secret
+
+		w.Header().Set("Location", "/api/v1/webhooks/"+hook.ID.String())
+		h.server.Respond(w, r, http.StatusCreated, resp)
+	}
+}
+
+func (h *webhookHandler) HandleListWebhooks() http.HandlerFunc {
+	const op = "webhookHandler.HandleListWebhooks"
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+		)
+		log.Info("list webhooks")
+
+		ctx := r.Context()
+
+		hooks, err := h.webhookUsecase.Webhooks(ctx)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		resp := model.WebhooksResponse{
+			Webhooks: make([]model.WebhookResponse, 0, len(hooks)),
+		}
+		for _, hook := range hooks {
+			resp.Webhooks = append(resp.Webhooks, toWebhookResponse(hook))
+		}
+
+		h.server.Respond(w, r, http.StatusOK, resp)
+	}
+}
+
+func (h *webhookHandler) HandleDeleteWebhook() http.HandlerFunc {
+	const op = "webhookHandler.HandleDeleteWebhook"
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || webhookID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidWebhookID)
+			return
+		}
+
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("webhookID", webhookID.String()),
+		)
+		log.Info("deleting webhook")
+
+		ctx := r.Context()
+
+		if err := h.webhookUsecase.DeleteWebhook(ctx, webhookID); err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		h.server.Respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+func (h *webhookHandler) HandleListWebhookAttempts() http.HandlerFunc {
+	const op = "webhookHandler.HandleListWebhookAttempts"
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhookID, err := uuid.Parse(r.PathValue("id"))
+		if err != nil || webhookID == uuid.Nil {
+			h.server.Error(w, r, op, walleterror.ErrInvalidWebhookID)
+			return
+		}
+
+		log := h.server.Logger().With(
+			slog.String("op", op),
+			slog.String("requestID", middleware.GetRequestIDFromRequest(r)),
+			slog.String("webhookID", webhookID.String()),
+		)
+		log.Info("list webhook attempts")
+
+		var limit int
+		if v := r.URL.Query().Get("limit"); v != "" {
+			limit, err = strconv.Atoi(v)
+			if err != nil || limit <= 0 {
+				h.server.Error(w, r, op, walleterror.ErrInvalidLimit)
+				return
+			}
+		}
+
+		ctx := r.Context()
+
+		attempts, err := h.webhookUsecase.WebhookAttempts(ctx, webhookID, limit)
+		if err != nil {
+			h.server.Error(w, r, op, err)
+			return
+		}
+
+		resp := model.WebhookAttemptsResponse{
+			Attempts: make([]model.WebhookAttemptResponse, 0, len(attempts)),
+		}
+		for _, a := range attempts {
+			resp.Attempts = append(resp.Attempts, model.WebhookAttemptResponse{
+				ID:         a.ID,
+				DeliveryID: a.DeliveryID,
+				EventID:    a.EventID,
+				EventType:  a.EventType,
+				Attempt:    a.Attempt,
+				StatusCode: a.StatusCode,
+				Error:      a.Error,
+				DurationMs: a.Duration.Milliseconds(),
+				Status:     a.Status,
+				CreatedAt:  a.CreatedAt,
+			})
+		}
+
+		h.server.Respond(w, r, http.StatusOK, resp)
+	}
+}
+
+func toWebhookResponse(hook model.Webhook) model.WebhookResponse {
+	resp := model.WebhookResponse{
+		ID:         hook.ID,
+		URL:        hook.URL,
+		EventTypes: hook.EventTypes,
+		WalletIDs:  hook.WalletIDs,
+		CreatedAt:  hook.CreatedAt,
+	}
+	if resp.EventTypes == nil {
+		resp.EventTypes = []string{}
+	}
+	if resp.WalletIDs == nil {
+		resp.WalletIDs = []uuid.UUID{}
+	}
+	return resp
+}
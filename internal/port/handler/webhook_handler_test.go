@@ -0,0 +1,181 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/port/handler"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newWebhookMux(uc handler.WebhookUsecase) *http.ServeMux {
+	h := handler.NewWebhookHandler(uc, newTestServer())
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /api/v1/webhooks", h.HandleCreateWebhook())
+	mux.Handle("GET /api/v1/webhooks", h.HandleListWebhooks())
+	mux.Handle("DELETE /api/v1/webhooks/{id}", h.HandleDeleteWebhook())
+	mux.Handle("GET /api/v1/webhooks/{id}/attempts", h.HandleListWebhookAttempts())
+	return mux
+}
+
+// --- HandleCreateWebhook ---
+
+func TestHandleCreateWebhook_Success(t *testing.T) {
+	uc := new(mocks.WebhookUsecase)
+	walletID := uuid.MustParse("11111111-1111-1111-1111-111111111111")
+	hookID := uuid.New()
+
+	uc.
+		On("CreateWebhook", mock.Anything, model.CreateWebhookInput{
+			URL:        "https://partner.example/hook",
+			EventTypes: []string{"DEPOSIT", "WITHDRAW"},
+			WalletIDs:  []uuid.UUID{walletID},
+		}).
+		Return(model.Webhook{
+			ID:         hookID,
+			URL:        "https://partner.example/hook",
+			EventTypes: []string{"DEPOSIT", "WITHDRAW"},
+			WalletIDs:  []uuid.UUID{walletID},
+			Secret:     "generated",
+		}, nil)
+
+	rr := serveJSON(t, newWebhookMux(uc), "/api/v1/webhooks", map[string]any{
+		"url":        "https://partner.example/hook",
+		"eventTypes": []string{"DEPOSIT", "WITHDRAW"},
+		"walletIds":  []uuid.UUID{walletID},
+	})
+
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, "/api/v1/webhooks/"+hookID.String(), rr.Header().Get("Location"))
+
+	var resp model.WebhookResponse
+	decodeBody(t, rr, &resp)
+	assert.Equal(t, hookID, resp.ID)
+	assert.Equal(t, "generated", resp.Secret)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleCreateWebhook_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+		want error
+	}{
+		{"http url", map[string]any{"url": "http://partner.example/hook"}, walleterror.ErrInvalidWebhookURL},
+		{"no url", map[string]any{}, walleterror.ErrInvalidWebhookURL},
+		{"unknown event", map[string]any{"url": "https://p.example", "eventTypes": []string{"REFUND"}}, walleterror.ErrInvalidEventType},
+		{"nil wallet", map[string]any{"url": "https://p.example", "walletIds": []uuid.UUID{uuid.Nil}}, walleterror.ErrInvalidValletID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(mocks.WebhookUsecase)
+
+			rr := serveJSON(t, newWebhookMux(uc), "/api/v1/webhooks", tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+			uc.AssertNotCalled(t, "CreateWebhook")
+		})
+	}
+}
+
+// --- HandleListWebhooks ---
+
+func TestHandleListWebhooks_HidesSecret(t *testing.T) {
+	uc := new(mocks.WebhookUsecase)
+
+	uc.On("Webhooks", mock.Anything).Return([]model.Webhook{
+		{ID: uuid.New(), URL: "https://partner.example/hook", Secret: "secret"},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
+	rr := httptest.NewRecorder()
+	newWebhookMux(uc).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, rr.Body.String(), "secret")
+
+	var resp model.WebhooksResponse
+	decodeBody(t, rr, &resp)
+	require.Len(t, resp.Webhooks, 1)
+	assert.Equal(t, []string{}, resp.Webhooks[0].EventTypes)
+}
+
+// --- HandleDeleteWebhook ---
+
+func TestHandleDeleteWebhook(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"deleted", nil, http.StatusNoContent},
+		{"not found", walleterror.ErrWebhookNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := new(mocks.WebhookUsecase)
+			uc.On("DeleteWebhook", mock.Anything, id).Return(tt.err)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+id.String(), nil)
+			rr := httptest.NewRecorder()
+			newWebhookMux(uc).ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.code, rr.Code)
+			uc.AssertExpectations(t)
+		})
+	}
+}
+
+// --- HandleListWebhookAttempts ---
+
+func TestHandleListWebhookAttempts_Success(t *testing.T) {
+	uc := new(mocks.WebhookUsecase)
+	id := uuid.New()
+
+	uc.On("WebhookAttempts", mock.Anything, id, 10).Return([]model.WebhookAttempt{
+		{
+			ID:         2,
+			DeliveryID: 1,
+			EventType:  "DEPOSIT",
+			Attempt:    2,
+			StatusCode: 500,
+			Error:      "unexpected status 500",
+			Duration:   150 * time.Millisecond,
+			Status:     model.WebhookDeliveryPending,
+		},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+id.String()+"/attempts?limit=10", nil)
+	rr := httptest.NewRecorder()
+	newWebhookMux(uc).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var resp model.WebhookAttemptsResponse
+	decodeBody(t, rr, &resp)
+	require.Len(t, resp.Attempts, 1)
+	assert.Equal(t, int64(150), resp.Attempts[0].DurationMs)
+	assert.Equal(t, model.WebhookDeliveryPending, resp.Attempts[0].Status)
+	uc.AssertExpectations(t)
+}
+
+func TestHandleListWebhookAttempts_InvalidID(t *testing.T) {
+	uc := new(mocks.WebhookUsecase)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/not-a-uuid/attempts", nil)
+	rr := httptest.NewRecorder()
+	newWebhookMux(uc).ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	uc.AssertNotCalled(t, "WebhookAttempts")
+}
@@ -0,0 +1,80 @@
+// Package middleware ...
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// UnmatchedRoute маршрут запросов, не подошедших ни к одному шаблону mux:
+// сырой путь в метке раздул бы число временных рядов.
+const UnmatchedRoute = "unmatched"
+
+// HTTPMetrics ...
+type HTTPMetrics interface {
+	ObserveHTTPRequest(method, route string, status int, d time.Duration)
+}
+
+// Metrics измеряет длительность запросов по методу, маршруту и статусу.
+// Маршрут - шаблон http.ServeMux без метода, например /api/v1/wallets/{id}.
+// ServeMux записывает шаблон в тот *http.Request, который получил сам,
+// поэтому Metrics должен стоять в цепочке последним, прямо перед mux:
+// middleware, подменяющие запрос через WithContext, прячут шаблон от
+// внешних обёрток.
+func Metrics(m HTTPMetrics) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			m.ObserveHTTPRequest(r.Method, route(r.Pattern), rec.status(), time.Since(start))
+		})
+	}
+}
+
+func route(pattern string) string {
+	if pattern == "" {
+		return UnmatchedRoute
+	}
+	// "POST /api/v1/wallet" -> "/api/v1/wallet"
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return strings.TrimSpace(path)
+	}
+	return pattern
+}
+
+// statusRecorder запоминает статус ответа. Unwrap отдаёт исходный writer
+// http.ResponseController, чтобы SSE-потоки могли делать Flush и ставить
+// дедлайны.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *statusRecorder) status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
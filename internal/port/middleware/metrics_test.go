@@ -0,0 +1,74 @@
+// Package middleware_test ...
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+	"wallet/internal/port/middleware"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type observation struct {
+	method string
+	route  string
+	status int
+}
+
+type recordingMetrics struct {
+	mu  sync.Mutex
+	obs []observation
+}
+
+func (m *recordingMetrics) ObserveHTTPRequest(method, route string, status int, _ time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.obs = append(m.obs, observation{method, route, status})
+}
+
+func TestMetrics_LabelsByRoutePattern(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/wallets/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	mux.HandleFunc("POST /api/v1/wallet", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	m := &recordingMetrics{}
+	h := middleware.Metrics(m)(mux)
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/api/v1/wallets/1", nil),
+		httptest.NewRequest(http.MethodGet, "/api/v1/wallets/2", nil),
+		httptest.NewRequest(http.MethodPost, "/api/v1/wallet", nil),
+		httptest.NewRequest(http.MethodGet, "/no/such/path", nil),
+	} {
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	assert.Equal(t, []observation{
+		{http.MethodGet, "/api/v1/wallets/{id}", http.StatusTeapot},
+		{http.MethodGet, "/api/v1/wallets/{id}", http.StatusTeapot},
+		{http.MethodPost, "/api/v1/wallet", http.StatusOK},
+		{http.MethodGet, middleware.UnmatchedRoute, http.StatusNotFound},
+	}, m.obs)
+}
+
+func TestMetrics_KeepsFlusher(t *testing.T) {
+	m := &recordingMetrics{}
+	h := middleware.Metrics(m)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		assert.NoError(t, http.NewResponseController(w).Flush())
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+
+	require.True(t, rec.Flushed)
+	require.Len(t, m.obs, 1)
+	assert.Equal(t, http.StatusOK, m.obs[0].status)
+}
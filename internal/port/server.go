@@ -50,7 +50,6 @@ func (s *ServerAPI) Respond(w http.ResponseWriter, r *http.Request, code int, da
 
 // Error ...
 func (s *ServerAPI) Error(w http.ResponseWriter, r *http.Request, op string, err error) {
-	var resp ErrorResponse
 	log := s.Logger().With(
 		slog.String("op", op),
 		slog.String("requestID:", middleware.GetRequestIDFromRequest(r)),
@@ -59,7 +58,16 @@ func (s *ServerAPI) Error(w http.ResponseWriter, r *http.Request, op string, err
 		log.Warn(err.Error())
 	}
 
-	var code int
+	code, resp := s.MapError(err)
+	s.Respond(w, r, code, resp)
+}
+
+// MapError HTTP-код и тело ответа для ошибки. Неизвестные ошибки - 500.
+func (s *ServerAPI) MapError(err error) (int, ErrorResponse) {
+	var (
+		code int
+		resp ErrorResponse
+	)
 	switch {
 	case errors.Is(err, walleterror.ErrWalletNotFound):
 		code = http.StatusNotFound
@@ -67,12 +75,54 @@ func (s *ServerAPI) Error(w http.ResponseWriter, r *http.Request, op string, err
 			Code:    "NOT_FOUND",
 			Message: "wallet not found",
 		}
+	case errors.Is(err, walleterror.ErrOperationNotFound):
+		code = http.StatusNotFound
+		resp = ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "operation not found",
+		}
+	case errors.Is(err, walleterror.ErrHoldNotFound):
+		code = http.StatusNotFound
+		resp = ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "hold not found",
+		}
+	case errors.Is(err, walleterror.ErrWebhookNotFound):
+		code = http.StatusNotFound
+		resp = ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "webhook not found",
+		}
+	case errors.Is(err, walleterror.ErrHoldNotActive):
+		code = http.StatusConflict
+		resp = ErrorResponse{
+			Code:    "CONFLICT",
+			Message: "hold is not active",
+		}
+	case errors.Is(err, walleterror.ErrCaptureExceedsHold):
+		code = http.StatusConflict
+		resp = ErrorResponse{
+			Code:    "CONFLICT",
+			Message: "capture amount exceeds hold",
+		}
+	case errors.Is(err, walleterror.ErrWalletAlreadyExists):
+		code = http.StatusConflict
+		resp = ErrorResponse{
+			Code:    "CONFLICT",
+			Message: "wallet already exists",
+		}
 	case errors.Is(err, walleterror.ErrInsufficientFunds):
 		code = http.StatusConflict
 		resp = ErrorResponse{
 			Code:    "CONFLICT",
 			Message: "insufficient funds",
 		}
+	case errors.Is(err, walleterror.ErrTxConflict):
+		code = http.StatusConflict
+		resp = ErrorResponse{
+			Code:    "CONFLICT",
+			Message: "concurrent update, please retry",
+		}
 	case errors.Is(err, walleterror.ErrInvalidOperationType):
 		code = http.StatusBadRequest
 		resp = ErrorResponse{
@@ -91,6 +141,102 @@ func (s *ServerAPI) Error(w http.ResponseWriter, r *http.Request, op string, err
 			Code:    "BAD_REQUEST",
 			Message: "invalid amount",
 		}
+	case errors.Is(err, walleterror.ErrSameWallet):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "source and destination wallets are the same",
+		}
+	case errors.Is(err, walleterror.ErrInvalidCursor):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid cursor",
+		}
+	case errors.Is(err, walleterror.ErrInvalidLimit):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid limit",
+		}
+	case errors.Is(err, walleterror.ErrInvalidTimeRange):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid time range",
+		}
+	case errors.Is(err, walleterror.ErrInvalidIdempotencyKey):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid idempotency key",
+		}
+	case errors.Is(err, walleterror.ErrIdempotencyKeyReused):
+		code = http.StatusUnprocessableEntity
+		resp = ErrorResponse{
+			Code:    "UNPROCESSABLE_ENTITY",
+			Message: "idempotency key reused with different request",
+		}
+	case errors.Is(err, walleterror.ErrInvalidOperationID):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid operation id",
+		}
+	case errors.Is(err, walleterror.ErrInvalidHoldID):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid hold id",
+		}
+	case errors.Is(err, walleterror.ErrInvalidHoldTTL):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid hold ttl",
+		}
+	case errors.Is(err, walleterror.ErrInvalidAsOf):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid asOf",
+		}
+	case errors.Is(err, walleterror.ErrAsOfBeforeCreation):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "asOf is before wallet creation",
+		}
+	case errors.Is(err, walleterror.ErrInvalidWebhookID):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid webhook id",
+		}
+	case errors.Is(err, walleterror.ErrInvalidWebhookURL):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid webhook url, https is required",
+		}
+	case errors.Is(err, walleterror.ErrInvalidEventType):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid event type",
+		}
+	case errors.Is(err, walleterror.ErrInvalidBatchSize):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid batch size",
+		}
+	case errors.Is(err, walleterror.ErrInvalidLastEventID):
+		code = http.StatusBadRequest
+		resp = ErrorResponse{
+			Code:    "BAD_REQUEST",
+			Message: "invalid Last-Event-ID",
+		}
 	case errors.Is(err, walleterror.ErrInvalidValletID):
 		code = http.StatusBadRequest
 		resp = ErrorResponse{
@@ -104,5 +250,5 @@ func (s *ServerAPI) Error(w http.ResponseWriter, r *http.Request, op string, err
 		}
 	}
 
-	s.Respond(w, r, code, resp)
+	return code, resp
 }
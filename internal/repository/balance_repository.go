@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ApplyOperation одним запросом меняет баланс кошелька op.WalletID на сумму
+// его проводок из entry, записывает операцию с балансами до и после и
+// журнальную запись. Как и в PostEntry, строки системных счетов не
+// обновляются. Условие balance + delta >= 0 проверяется в самом UPDATE,
+// поэтому блокировка строки берётся и отпускается без промежуточного чтения.
+// Возвращает op с заполненными BalanceBefore, BalanceAfter и CreatedAt.
+func (r *WalletRepository) ApplyOperation(ctx context.Context, op usecase.Operation, entry model.JournalEntry) (usecase.Operation, error) {
+	if !entry.Balanced() {
+		return usecase.Operation{}, walleterror.ErrUnbalancedEntry
+	}
+
+	var delta int64
+	accounts := make([]uuid.UUID, len(entry.Postings))
+	amounts := make([]int64, len(entry.Postings))
+	for i, p := range entry.Postings {
+		accounts[i] = p.AccountID
+		amounts[i] = p.Amount
+		if p.AccountID == op.WalletID {
+			delta += p.Amount
+		}
+	}
+
+	// Все вставки идут от w: если UPDATE не затронул строку, запрос ничего
+	// не пишет и не возвращает.
+	query := `
+		WITH w AS (
+			UPDATE wallets
+			SET balance = balance + $6::bigint, updated_at = NOW()
+			WHERE id = $2::uuid AND balance + $6::bigint >= 0
+			RETURNING balance
+		), op AS (
+			INSERT INTO wallet_operations (id, wallet_id, operation, amount, transfer_id, balance_before, balance_after)
+			SELECT $1::uuid, $2::uuid, $3::operation_type, $4::bigint, $5::uuid, w.balance - $6::bigint, w.balance
+			FROM w
+			RETURNING balance_before, balance_after, created_at, seq
+		), entry AS (
+			INSERT INTO journal_entries (id, description)
+			SELECT $7::uuid, $8::text FROM w
+			RETURNING id
+		), p AS (
+			SELECT account_id, SUM(amount) AS amount
+			FROM unnest($9::uuid[], $10::bigint[]) AS t(account_id, amount)
+			GROUP BY account_id
+		), postings AS (
+			INSERT INTO ledger_postings (entry_id, account_id, amount)
+			SELECT entry.id, t.account_id, t.amount
+			FROM entry, unnest($9::uuid[], $10::bigint[]) AS t(account_id, amount)
+		), accounts AS (
+			UPDATE ledger_accounts a
+			SET balance = a.balance + p.amount
+			FROM p, w
+			WHERE a.id = p.account_id AND a.kind = 'WALLET'
+		)
+		SELECT balance_before, balance_after, created_at, seq FROM op
+	`
+
+	err := r.q(ctx).QueryRow(ctx, query,
+		op.ID, op.WalletID, op.Type, op.Amount, op.TransferID, delta,
+		entry.ID, entry.Description, accounts, amounts,
+	).Scan(&op.BalanceBefore, &op.BalanceAfter, &op.CreatedAt, &op.Seq)
+	if err == nil {
+		return op, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return usecase.Operation{}, fmt.Errorf("apply operation: %w", err)
+	}
+
+	// Строка не обновилась: кошелька нет или не хватает средств. Это редкий
+	// путь, поэтому выясняем причину отдельным запросом.
+	var exists bool
+	err = r.q(ctx).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM wallets WHERE id = $1)`, op.WalletID).Scan(&exists)
+	if err != nil {
+		return usecase.Operation{}, fmt.Errorf("apply operation: %w", err)
+	}
+	if !exists {
+		return usecase.Operation{}, walleterror.ErrWalletNotFound
+	}
+
+	return usecase.Operation{}, walleterror.ErrInsufficientFunds
+}
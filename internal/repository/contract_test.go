@@ -0,0 +1,32 @@
+package repository_test
+
+import (
+	"testing"
+	"wallet/internal/repository"
+	"wallet/internal/repository/repositorytest"
+
+	"github.com/google/uuid"
+)
+
+func newBackend(t testing.TB) repositorytest.Backend {
+	pool, store := setupDB(t)
+	return repositorytest.Backend{
+		Repo:      repository.New(pool),
+		TxManager: store,
+		AccountBalance: func(t testing.TB, accountID uuid.UUID) int64 {
+			return accountBalance(t, pool, accountID)
+		},
+		DeleteWallet: func(walletID uuid.UUID) { deleteWallet(pool, walletID) },
+	}
+}
+
+func TestRepository_Contract(t *testing.T) {
+	repositorytest.Run(t, newBackend)
+}
+
+// BenchmarkHotWallet ...
+//
+//	go test ./internal/repository -run '^$' -bench HotWallet -cpu 1 -benchtime 2000x
+func BenchmarkHotWallet(b *testing.B) {
+	repositorytest.BenchHotWallet(b, newBackend)
+}
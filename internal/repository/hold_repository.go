@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// holdColumns статус ACTIVE с истёкшим сроком отдаётся как EXPIRED.
+const holdColumns = `
+	id, wallet_id, amount,
+	CASE WHEN status = 'ACTIVE' AND expires_at <= NOW() THEN 'EXPIRED' ELSE status::text END,
+	captured_amount, operation_id, expires_at, created_at
+`
+
+// CreateHold ...
+func (r *WalletRepository) CreateHold(ctx context.Context, hold model.Hold, ttl time.Duration) (model.Hold, error) {
+	query := `
+		INSERT INTO holds (id, wallet_id, amount, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING ` + holdColumns
+
+	created, err := scanHold(r.q(ctx).QueryRow(ctx, query, hold.ID, hold.WalletID, hold.Amount, ttl.Seconds()))
+	if err != nil {
+		return model.Hold{}, fmt.Errorf("create hold: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetHoldForUpdate ...
+func (r *WalletRepository) GetHoldForUpdate(ctx context.Context, holdID uuid.UUID) (model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = $1 FOR UPDATE`
+
+	hold, err := scanHold(r.q(ctx).QueryRow(ctx, query, holdID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Hold{}, walleterror.ErrHoldNotFound
+		}
+		return model.Hold{}, fmt.Errorf("get hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// UpdateHold сохраняет статус, списанную сумму и операцию списания.
+func (r *WalletRepository) UpdateHold(ctx context.Context, hold model.Hold) error {
+	query := `
+		UPDATE holds
+		SET status = $2, captured_amount = $3, operation_id = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.q(ctx).Exec(ctx, query, hold.ID, string(hold.Status), hold.CapturedAmount, hold.OperationID)
+	if err != nil {
+		return fmt.Errorf("update hold: %w", err)
+	}
+
+	return nil
+}
+
+// GetHeldAmount сумма активных непросроченных холдов кошелька.
+func (r *WalletRepository) GetHeldAmount(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM holds
+		WHERE wallet_id = $1 AND status = 'ACTIVE' AND expires_at > NOW()
+	`
+
+	var held int64
+	if err := r.q(ctx).QueryRow(ctx, query, walletID).Scan(&held); err != nil {
+		return 0, fmt.Errorf("get held amount: %w", err)
+	}
+
+	return held, nil
+}
+
+// GetWalletBalance ...
+func (r *WalletRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error) {
+	query := `
+		SELECT w.balance, w.balance - COALESCE((
+			SELECT SUM(h.amount)
+			FROM holds h
+			WHERE h.wallet_id = w.id AND h.status = 'ACTIVE' AND h.expires_at > NOW()
+		), 0)
+		FROM wallets w
+		WHERE w.id = $1
+	`
+
+	var b model.WalletBalance
+	err := r.q(ctx).QueryRow(ctx, query, walletID).Scan(&b.Balance, &b.AvailableBalance)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.WalletBalance{}, walleterror.ErrWalletNotFound
+		}
+		return model.WalletBalance{}, fmt.Errorf("get wallet balance: %w", err)
+	}
+
+	return b, nil
+}
+
+func scanHold(row pgx.Row) (model.Hold, error) {
+	var (
+		h      model.Hold
+		status string
+	)
+	err := row.Scan(&h.ID, &h.WalletID, &h.Amount, &status,
+		&h.CapturedAmount, &h.OperationID, &h.ExpiresAt, &h.CreatedAt)
+	if err != nil {
+		return model.Hold{}, err
+	}
+	h.Status = model.HoldStatus(status)
+
+	return h, nil
+}
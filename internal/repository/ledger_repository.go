@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// checkViolation ...
+const checkViolation = "23514"
+
+// PostEntry записывает журнальную запись с проводками и одним запросом
+// применяет их к кэшированным балансам кошельков: ledger_accounts.balance и
+// wallets.balance. Строки системных счетов не обновляются, иначе все
+// движения денег ждали бы блокировку строки внешнего счёта; их баланс -
+// сумма проводок. Несбалансированная запись отклоняется до обращения к базе.
+func (r *WalletRepository) PostEntry(ctx context.Context, entry model.JournalEntry) error {
+	if !entry.Balanced() {
+		return walleterror.ErrUnbalancedEntry
+	}
+
+	accounts := make([]uuid.UUID, len(entry.Postings))
+	amounts := make([]int64, len(entry.Postings))
+	for i, p := range entry.Postings {
+		accounts[i] = p.AccountID
+		amounts[i] = p.Amount
+	}
+
+	query := `
+		WITH entry AS (
+			INSERT INTO journal_entries (id, description)
+			VALUES ($1, $2)
+			RETURNING id
+		), p AS (
+			SELECT account_id, amount
+			FROM unnest($3::uuid[], $4::bigint[]) AS t(account_id, amount)
+		), postings AS (
+			INSERT INTO ledger_postings (entry_id, account_id, amount)
+			SELECT entry.id, p.account_id, p.amount
+			FROM entry, p
+		), delta AS (
+			SELECT account_id, SUM(amount) AS amount
+			FROM p
+			GROUP BY account_id
+		), accounts AS (
+			UPDATE ledger_accounts a
+			SET balance = a.balance + delta.amount
+			FROM delta
+			WHERE a.id = delta.account_id AND a.kind = 'WALLET'
+		)
+		UPDATE wallets w
+		SET balance = w.balance + delta.amount, updated_at = NOW()
+		FROM delta
+		WHERE w.id = delta.account_id
+	`
+
+	_, err := r.q(ctx).Exec(ctx, query, entry.ID, entry.Description, accounts, amounts)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == checkViolation {
+			return walleterror.ErrInsufficientFunds
+		}
+		return fmt.Errorf("post journal entry: %w", err)
+	}
+
+	return nil
+}
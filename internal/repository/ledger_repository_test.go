@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"wallet/internal/model"
+	"wallet/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// accountBalance баланс счёта главной книги: у счёта кошелька - кэш в
+// ledger_accounts, у системного - сумма проводок.
+func accountBalance(t testing.TB, pool *pgxpool.Pool, accountID uuid.UUID) int64 {
+	t.Helper()
+
+	var balance int64
+	err := pool.QueryRow(context.Background(),
+		`SELECT CASE WHEN a.kind = 'WALLET' THEN a.balance ELSE (
+			SELECT COALESCE(SUM(p.amount), 0) FROM ledger_postings p WHERE p.account_id = a.id
+		) END
+		FROM ledger_accounts a WHERE a.id = $1`, accountID,
+	).Scan(&balance)
+	require.NoError(t, err)
+
+	return balance
+}
+
+// --- PostEntry ---
+
+func TestRepository_PostEntry_SkipsSystemAccountRows(t *testing.T) {
+	pool, store := setupDB(t)
+	repo := repository.New(pool)
+	ctx := context.Background()
+
+	walletID := createWallet(t, pool, 100)
+
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		return repo.PostEntry(ctx, model.JournalEntry{
+			ID:          uuid.New(),
+			Description: "DEPOSIT",
+			Postings: []model.Posting{
+				{AccountID: walletID, Amount: 40},
+				{AccountID: model.ExternalFundingAccountID, Amount: -40},
+			},
+		})
+	})
+	require.NoError(t, err)
+
+	// Кэш ведётся только у счёта кошелька, строка системного счёта не
+	// обновляется.
+	assert.Equal(t, int64(140), accountBalance(t, pool, walletID))
+
+	var cached int64
+	err = pool.QueryRow(ctx,
+		`SELECT balance FROM ledger_accounts WHERE id = $1`, model.ExternalFundingAccountID,
+	).Scan(&cached)
+	require.NoError(t, err)
+	assert.Zero(t, cached)
+}
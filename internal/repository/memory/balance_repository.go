@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+)
+
+// ApplyOperation меняет баланс кошелька op.WalletID на сумму его проводок из
+// entry, записывает операцию с балансами до и после и журнальную запись.
+// Возвращает op с заполненными BalanceBefore, BalanceAfter и CreatedAt.
+func (r *WalletRepository) ApplyOperation(ctx context.Context, op usecase.Operation, entry model.JournalEntry) (usecase.Operation, error) {
+	if !entry.Balanced() {
+		return usecase.Operation{}, walleterror.ErrUnbalancedEntry
+	}
+	if err := checkOperation(op); err != nil {
+		return usecase.Operation{}, fmt.Errorf("apply operation: %w", err)
+	}
+
+	if err := r.store.Lock(ctx, walletKey(op.WalletID)); err != nil {
+		return usecase.Operation{}, fmt.Errorf("apply operation: %w", err)
+	}
+	if err := r.lockWallets(ctx, entry); err != nil {
+		return usecase.Operation{}, fmt.Errorf("apply operation: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.wallets.Get(ctx, op.WalletID)
+	if !ok {
+		return usecase.Operation{}, walleterror.ErrWalletNotFound
+	}
+
+	var delta int64
+	for _, p := range entry.Postings {
+		if p.AccountID == op.WalletID {
+			delta += p.Amount
+		}
+	}
+
+	op.BalanceBefore = w.balance
+	op.BalanceAfter = w.balance + delta
+	if op.BalanceAfter < 0 {
+		return usecase.Operation{}, walleterror.ErrInsufficientFunds
+	}
+
+	if err := r.postEntry(ctx, entry); err != nil {
+		return usecase.Operation{}, err
+	}
+
+	if err := r.insertOperation(ctx, &op); err != nil {
+		return usecase.Operation{}, fmt.Errorf("apply operation: %w", err)
+	}
+
+	return op, nil
+}
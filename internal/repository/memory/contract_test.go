@@ -0,0 +1,21 @@
+package memory_test
+
+import (
+	"testing"
+	"wallet/internal/repository/repositorytest"
+
+	"github.com/google/uuid"
+)
+
+func TestRepository_Contract(t *testing.T) {
+	repositorytest.Run(t, func(t testing.TB) repositorytest.Backend {
+		repo, store := setupRepo(t)
+		return repositorytest.Backend{
+			Repo:      repo,
+			TxManager: store,
+			AccountBalance: func(t testing.TB, accountID uuid.UUID) int64 {
+				return accountBalance(t, repo, accountID)
+			},
+		}
+	})
+}
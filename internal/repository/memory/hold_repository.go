@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// CreateHold ...
+func (r *WalletRepository) CreateHold(ctx context.Context, hold model.Hold, ttl time.Duration) (model.Hold, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.wallets.Get(ctx, hold.WalletID); !ok {
+		return model.Hold{}, fmt.Errorf("create hold: wallet %s does not exist", hold.WalletID)
+	}
+
+	hold.Status = model.HoldActive
+	hold.CapturedAmount = 0
+	hold.OperationID = uuid.NullUUID{}
+	hold.CreatedAt = now()
+	hold.ExpiresAt = hold.CreatedAt.Add(ttl)
+
+	r.holds.Put(ctx, hold.ID, hold)
+
+	return withExpiry(hold), nil
+}
+
+// GetHoldForUpdate ...
+func (r *WalletRepository) GetHoldForUpdate(ctx context.Context, holdID uuid.UUID) (model.Hold, error) {
+	if err := r.store.Lock(ctx, holdKey(holdID)); err != nil {
+		return model.Hold{}, fmt.Errorf("get hold: %w", err)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hold, ok := r.holds.Get(ctx, holdID)
+	if !ok {
+		return model.Hold{}, walleterror.ErrHoldNotFound
+	}
+
+	return withExpiry(hold), nil
+}
+
+// UpdateHold сохраняет статус, списанную сумму и операцию списания.
+func (r *WalletRepository) UpdateHold(ctx context.Context, hold model.Hold) error {
+	if err := r.store.Lock(ctx, holdKey(hold.ID)); err != nil {
+		return fmt.Errorf("update hold: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, ok := r.holds.Get(ctx, hold.ID)
+	if !ok {
+		return nil
+	}
+
+	updated.Status = hold.Status
+	updated.CapturedAmount = hold.CapturedAmount
+	updated.OperationID = hold.OperationID
+	r.holds.Put(ctx, hold.ID, updated)
+
+	return nil
+}
+
+// GetHeldAmount сумма активных непросроченных холдов кошелька.
+func (r *WalletRepository) GetHeldAmount(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.heldAmount(ctx, walletID), nil
+}
+
+// heldAmount вызывается под r.mu.
+func (r *WalletRepository) heldAmount(ctx context.Context, walletID uuid.UUID) int64 {
+	var held int64
+	for _, h := range r.holds.All(ctx) {
+		if h.WalletID == walletID && withExpiry(h).Status == model.HoldActive {
+			held += h.Amount
+		}
+	}
+	return held
+}
+
+// GetWalletBalance ...
+func (r *WalletRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets.Get(ctx, walletID)
+	if !ok {
+		return model.WalletBalance{}, walleterror.ErrWalletNotFound
+	}
+
+	return model.WalletBalance{
+		Balance:          w.balance,
+		AvailableBalance: w.balance - r.heldAmount(ctx, walletID),
+	}, nil
+}
+
+// withExpiry статус ACTIVE с истёкшим сроком отдаётся как EXPIRED.
+func withExpiry(h model.Hold) model.Hold {
+	if h.Status == model.HoldActive && !h.ExpiresAt.After(now()) {
+		h.Status = model.HoldExpired
+	}
+	return h
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// PostEntry записывает журнальную запись и применяет её проводки к
+// балансам кошельков. Кошельки из записи блокируются до конца
+// транзакции, как строки wallets при UPDATE. Несбалансированная запись и
+// запись, уводящая кошелёк в минус, отклоняются без изменений.
+func (r *WalletRepository) PostEntry(ctx context.Context, entry model.JournalEntry) error {
+	if !entry.Balanced() {
+		return walleterror.ErrUnbalancedEntry
+	}
+
+	if err := r.lockWallets(ctx, entry); err != nil {
+		return fmt.Errorf("post journal entry: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.postEntry(ctx, entry)
+}
+
+// lockWallets блокирует кошельки, по которым есть проводки. Системные счета
+// не блокируются: их балансы не хранятся, а считаются по проводкам.
+func (r *WalletRepository) lockWallets(ctx context.Context, entry model.JournalEntry) error {
+	for _, p := range entry.Postings {
+		r.mu.RLock()
+		_, isWallet := r.wallets.Get(ctx, p.AccountID)
+		r.mu.RUnlock()
+
+		if !isWallet {
+			continue
+		}
+		if err := r.store.Lock(ctx, walletKey(p.AccountID)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// postEntry проверяет и применяет запись. Вызывается под r.mu.
+func (r *WalletRepository) postEntry(ctx context.Context, entry model.JournalEntry) error {
+	if _, ok := r.entries.Get(ctx, entry.ID); ok {
+		return fmt.Errorf("post journal entry: entry %s already exists", entry.ID)
+	}
+
+	delta := make(map[uuid.UUID]int64, len(entry.Postings))
+	for _, p := range entry.Postings {
+		if !r.accountExists(ctx, p.AccountID) {
+			return fmt.Errorf("post journal entry: account %s does not exist", p.AccountID)
+		}
+		delta[p.AccountID] += p.Amount
+	}
+
+	// Ограничение balance >= 0 на кошельке.
+	for id, amount := range delta {
+		if w, ok := r.wallets.Get(ctx, id); ok && w.balance+amount < 0 {
+			return walleterror.ErrInsufficientFunds
+		}
+	}
+
+	for id, amount := range delta {
+		if w, ok := r.wallets.Get(ctx, id); ok {
+			w.balance += amount
+			r.wallets.Put(ctx, id, w)
+		}
+	}
+	entry.CreatedAt = now()
+	r.entries.Put(ctx, entry.ID, entry)
+
+	return nil
+}
+
+// accountExists счёт главной книги - системный или счёт кошелька.
+// Вызывается под r.mu.
+func (r *WalletRepository) accountExists(ctx context.Context, accountID uuid.UUID) bool {
+	switch accountID {
+	case model.ExternalFundingAccountID, model.FeesAccountID, model.AdjustmentsAccountID:
+		return true
+	}
+	_, ok := r.wallets.Get(ctx, accountID)
+	return ok
+}
+
+// AccountBalance баланс счёта главной книги - сумма его проводок.
+func (r *WalletRepository) AccountBalance(ctx context.Context, accountID uuid.UUID) (int64, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if !r.accountExists(ctx, accountID) {
+		return 0, false
+	}
+
+	var balance int64
+	for _, entry := range r.entries.All(ctx) {
+		for _, p := range entry.Postings {
+			if p.AccountID == accountID {
+				balance += p.Amount
+			}
+		}
+	}
+	return balance, true
+}
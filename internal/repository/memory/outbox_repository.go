@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"wallet/internal/driver/memstore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type outboxRow struct {
+	event         model.Event
+	deliveredAt   time.Time
+	failed        bool
+	nextAttemptAt time.Time
+	lockedUntil   time.Time
+	lastError     string
+}
+
+// SaveEvent добавляет событие в outbox при коммите транзакции: откаченное
+// событие никто не увидит, а номер получает уже закоммиченное.
+func (r *WalletRepository) SaveEvent(ctx context.Context, event model.Event) error {
+	memstore.AfterCommit(ctx, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.outboxSeq++
+		event.Sequence = r.outboxSeq
+		event.Attempts = 0
+		event.CreatedAt = now()
+		r.outbox.Put(context.Background(), event.Sequence, outboxRow{event: event, nextAttemptAt: event.CreatedAt})
+	})
+
+	return nil
+}
+
+// ClaimPendingEvents забирает в аренду на lease до limit недоставленных
+// событий в порядке записи. Пока первое из них ждёт повторной попытки или
+// арендовано другим диспетчером, не возвращает ничего: более поздние
+// события не должны обгонять его. События в dead letter пропускаются.
+// Захваты упорядочены блокировкой outbox до конца транзакции.
+func (r *WalletRepository) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	if err := r.store.Lock(ctx, outboxKey{}); err != nil {
+		return nil, fmt.Errorf("claim pending events: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pending := make([]outboxRow, 0, limit)
+	for _, row := range r.outbox.All(ctx) {
+		if row.deliveredAt.IsZero() && !row.failed {
+			pending = append(pending, row)
+		}
+	}
+	slices.SortFunc(pending, func(a, b outboxRow) int {
+		return cmp.Compare(a.event.Sequence, b.event.Sequence)
+	})
+
+	t := now()
+	if len(pending) == 0 || pending[0].nextAttemptAt.After(t) || pending[0].lockedUntil.After(t) {
+		return []model.Event{}, nil
+	}
+	if len(pending) > limit {
+		pending = pending[:limit]
+	}
+
+	events := make([]model.Event, len(pending))
+	for i, row := range pending {
+		row.lockedUntil = t.Add(lease)
+		r.outbox.Put(ctx, row.event.Sequence, row)
+		events[i] = row.event
+	}
+
+	return events, nil
+}
+
+// MarkEventDelivered ...
+func (r *WalletRepository) MarkEventDelivered(ctx context.Context, sequence int64) error {
+	return r.updateEvent(ctx, sequence, func(row *outboxRow) {
+		row.deliveredAt = now()
+		row.lastError = ""
+	})
+}
+
+// MarkEventFailed увеличивает счётчик попыток, откладывает следующую на
+// retryIn и снимает аренду.
+func (r *WalletRepository) MarkEventFailed(ctx context.Context, sequence int64, retryIn time.Duration, reason string) error {
+	return r.updateEvent(ctx, sequence, func(row *outboxRow) {
+		row.event.Attempts++
+		row.lastError = reason
+		row.nextAttemptAt = now().Add(retryIn)
+		row.lockedUntil = time.Time{}
+	})
+}
+
+// MarkEventDead увеличивает счётчик попыток, убирает событие из очереди и
+// снимает аренду.
+func (r *WalletRepository) MarkEventDead(ctx context.Context, sequence int64, reason string) error {
+	return r.updateEvent(ctx, sequence, func(row *outboxRow) {
+		row.event.Attempts++
+		row.lastError = reason
+		row.failed = true
+		row.lockedUntil = time.Time{}
+	})
+}
+
+// DeleteDeliveredEvents ...
+func (r *WalletRepository) DeleteDeliveredEvents(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	before := now().Add(-olderThan)
+	var expired []int64
+	for seq, row := range r.outbox.All(ctx) {
+		if len(expired) >= limit {
+			break
+		}
+		if _, ok := r.fannedOut.Get(ctx, seq); !ok {
+			continue
+		}
+		if !row.deliveredAt.IsZero() && !row.deliveredAt.After(before) {
+			expired = append(expired, seq)
+		}
+	}
+	for _, seq := range expired {
+		r.outbox.Delete(ctx, seq)
+		r.fannedOut.Delete(ctx, seq)
+	}
+
+	return len(expired), nil
+}
+
+// ClaimWebhookEvents забирает до limit событий, ещё не разосланных
+// подпискам, в порядке записи и отмечает их разосланными. Порядок и
+// доставка внешнему publisher на рассылку не влияют. Рассылки упорядочены
+// своей блокировкой до конца транзакции.
+func (r *WalletRepository) ClaimWebhookEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	if err := r.store.Lock(ctx, fanoutKey{}); err != nil {
+		return nil, fmt.Errorf("claim webhook events: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]model.Event, 0, limit)
+	for seq, row := range r.outbox.All(ctx) {
+		if _, ok := r.fannedOut.Get(ctx, seq); !ok {
+			events = append(events, row.event)
+		}
+	}
+	slices.SortFunc(events, func(a, b model.Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	for _, e := range events {
+		r.fannedOut.Put(ctx, e.Sequence, struct{}{})
+	}
+
+	return events, nil
+}
+
+func (r *WalletRepository) updateEvent(ctx context.Context, sequence int64, update func(row *outboxRow)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.outbox.Get(ctx, sequence)
+	if !ok {
+		return nil
+	}
+
+	update(&row)
+	r.outbox.Put(ctx, sequence, row)
+
+	return nil
+}
+
+// NotifyWalletChanged будит подписчиков кошелька после коммита транзакции,
+// в которой вызван метод.
+func (r *WalletRepository) NotifyWalletChanged(ctx context.Context, walletID uuid.UUID) error {
+	if r.notifier != nil {
+		memstore.AfterCommit(ctx, func() { r.notifier.Notify(walletID) })
+	}
+
+	return nil
+}
+
+// ListWalletEvents события кошелька с номером больше afterSequence по порядку.
+func (r *WalletRepository) ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterSequence int64, limit int) ([]model.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]model.Event, 0, limit)
+	for seq, row := range r.outbox.All(ctx) {
+		if row.event.WalletID == walletID && seq > afterSequence {
+			events = append(events, row.event)
+		}
+	}
+	slices.SortFunc(events, func(a, b model.Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+
+	return events, nil
+}
+
+// GetWalletSnapshot ...
+func (r *WalletRepository) GetWalletSnapshot(ctx context.Context, walletID uuid.UUID) (model.WalletSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets.Get(ctx, walletID)
+	if !ok {
+		return model.WalletSnapshot{}, walleterror.ErrWalletNotFound
+	}
+
+	s := model.WalletSnapshot{
+		WalletBalance: model.WalletBalance{
+			Balance:          w.balance,
+			AvailableBalance: w.balance - r.heldAmount(ctx, walletID),
+		},
+	}
+	for seq, row := range r.outbox.All(ctx) {
+		if row.event.WalletID == walletID && seq > s.Sequence {
+			s.Sequence = seq
+		}
+	}
+
+	return s, nil
+}
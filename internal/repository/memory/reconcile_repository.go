@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+)
+
+// signedAmount сумма операции со знаком: списания уменьшают баланс, всё
+// остальное (включая ADJUSTMENT, который сам хранит знак) - увеличивает.
+func signedAmount(op usecase.Operation) int64 {
+	if op.Type == "WITHDRAW" || op.Type == "TRANSFER_OUT" {
+		return -op.Amount
+	}
+	return op.Amount
+}
+
+// GetBalanceAt возвращает сумму операций кошелька с created_at <= at и
+// время создания кошелька.
+func (r *WalletRepository) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, time.Time, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets.Get(ctx, walletID)
+	if !ok {
+		return 0, time.Time{}, walleterror.ErrWalletNotFound
+	}
+
+	var balance int64
+	for _, op := range r.operations.All(ctx) {
+		if op.WalletID == walletID && !op.CreatedAt.After(at) {
+			balance += signedAmount(op)
+		}
+	}
+
+	return balance, w.createdAt, nil
+}
+
+// StreamLedgerBalances ...
+func (r *WalletRepository) StreamLedgerBalances(ctx context.Context, fn func(model.LedgerBalance) error) error {
+	// fn может обращаться к репозиторию, поэтому балансы собираются заранее.
+	r.mu.RLock()
+	sums := make(map[uuid.UUID]int64)
+	for _, op := range r.operations.All(ctx) {
+		sums[op.WalletID] += signedAmount(op)
+	}
+	var balances []model.LedgerBalance
+	for id, w := range r.wallets.All(ctx) {
+		balances = append(balances, model.LedgerBalance{WalletID: id, Balance: w.balance, LedgerSum: sums[id]})
+	}
+	r.mu.RUnlock()
+
+	slices.SortFunc(balances, func(a, b model.LedgerBalance) int {
+		return bytes.Compare(a.WalletID[:], b.WalletID[:])
+	})
+
+	for _, b := range balances {
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetLedgerBalanceForUpdate ...
+func (r *WalletRepository) GetLedgerBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (model.LedgerBalance, error) {
+	balance, err := r.GetBalanceForUpdate(ctx, walletID)
+	if err != nil {
+		return model.LedgerBalance{}, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var sum int64
+	for _, op := range r.operations.All(ctx) {
+		if op.WalletID == walletID {
+			sum += signedAmount(op)
+		}
+	}
+
+	return model.LedgerBalance{
+		WalletID:  walletID,
+		Balance:   balance,
+		LedgerSum: sum,
+	}, nil
+}
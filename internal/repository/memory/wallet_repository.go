@@ -0,0 +1,340 @@
+// Package memory реализация репозиториев в памяти поверх memstore: для
+// dev-режима без базы и тестов. Данные живут, пока жив процесс.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+	"wallet/internal/driver/memstore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+)
+
+// Ключи блокировок строк в memstore.
+type (
+	walletKey      uuid.UUID
+	holdKey        uuid.UUID
+	idempotencyKey string
+	deliveryKey    int64
+	outboxKey      struct{}
+	fanoutKey      struct{}
+)
+
+// Notifier ...
+type Notifier interface {
+	Notify(walletID uuid.UUID)
+}
+
+type walletRow struct {
+	balance   int64
+	createdAt time.Time
+}
+
+type idempotencyRow struct {
+	model.IdempotencyRecord
+	expiresAt time.Time
+}
+
+// WalletRepository хранит данные в таблицах memstore: изменения транзакции
+// не видны другим до коммита. Все таблицы делят mu.
+type WalletRepository struct {
+	store    *memstore.Store
+	notifier Notifier
+
+	mu          sync.RWMutex
+	wallets     *memstore.Table[uuid.UUID, walletRow]
+	operations  *memstore.Table[uuid.UUID, usecase.Operation]
+	opSeq       int64
+	entries     *memstore.Table[uuid.UUID, model.JournalEntry]
+	idempotency *memstore.Table[string, idempotencyRow]
+	holds       *memstore.Table[uuid.UUID, model.Hold]
+	outbox      *memstore.Table[int64, outboxRow]
+	outboxSeq   int64
+	// fannedOut события, разосланные подпискам вебхуков. Отдельная таблица,
+	// а не поле outboxRow: рассылка и диспетчер outbox пишут независимо.
+	fannedOut   *memstore.Table[int64, struct{}]
+	webhooks    *memstore.Table[uuid.UUID, model.Webhook]
+	deliveries  *memstore.Table[int64, deliveryRow]
+	deliverySeq int64
+	attempts    *memstore.Table[int64, model.WebhookAttempt]
+	attemptSeq  int64
+}
+
+// New создаёт пустое хранилище. Системные счета главной книги заводить не
+// нужно: они есть всегда, как после миграций. Тестовый кошелёк, как и в
+// остальных хранилищах, создаёт make seed.
+func New(store *memstore.Store) *WalletRepository {
+	r := &WalletRepository{store: store}
+	r.wallets = memstore.NewTable[uuid.UUID, walletRow](&r.mu)
+	r.operations = memstore.NewTable[uuid.UUID, usecase.Operation](&r.mu)
+	r.entries = memstore.NewTable[uuid.UUID, model.JournalEntry](&r.mu)
+	r.idempotency = memstore.NewTable[string, idempotencyRow](&r.mu)
+	r.holds = memstore.NewTable[uuid.UUID, model.Hold](&r.mu)
+	r.outbox = memstore.NewTable[int64, outboxRow](&r.mu)
+	r.fannedOut = memstore.NewTable[int64, struct{}](&r.mu)
+	r.webhooks = memstore.NewTable[uuid.UUID, model.Webhook](&r.mu)
+	r.deliveries = memstore.NewTable[int64, deliveryRow](&r.mu)
+	r.attempts = memstore.NewTable[int64, model.WebhookAttempt](&r.mu)
+
+	return r
+}
+
+// SetNotifier задаёт, кого будить после коммита NotifyWalletChanged.
+func (r *WalletRepository) SetNotifier(n Notifier) {
+	r.notifier = n
+}
+
+// now время с точностью Postgres TIMESTAMP (микросекунды) в UTC.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// CreateWallet создаёт кошелёк с нулевым балансом и его счёт в главной
+// книге. Начальный баланс вносится журнальной записью.
+func (r *WalletRepository) CreateWallet(ctx context.Context, walletID uuid.UUID) error {
+	// Новая строка заблокирована до конца транзакции, как после INSERT.
+	if err := r.store.Lock(ctx, walletKey(walletID)); err != nil {
+		return fmt.Errorf("create wallet: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.wallets.Get(ctx, walletID); ok {
+		return walleterror.ErrWalletAlreadyExists
+	}
+
+	r.wallets.Put(ctx, walletID, walletRow{createdAt: now()})
+
+	return nil
+}
+
+// GetBalance ...
+func (r *WalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	w, ok := r.wallets.Get(ctx, walletID)
+	if !ok {
+		return 0, walleterror.ErrWalletNotFound
+	}
+
+	return w.balance, nil
+}
+
+// GetBalanceForUpdate ...
+func (r *WalletRepository) GetBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	if err := r.store.Lock(ctx, walletKey(walletID)); err != nil {
+		return 0, fmt.Errorf("lock wallet: %w", err)
+	}
+
+	return r.GetBalance(ctx, walletID)
+}
+
+// UpdateBalance ...
+func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, newBalance int64) error {
+	// Ограничение balance >= 0 на кошельке.
+	if newBalance < 0 {
+		return fmt.Errorf("update balance: balance %d is negative", newBalance)
+	}
+
+	if err := r.store.Lock(ctx, walletKey(walletID)); err != nil {
+		return fmt.Errorf("update balance: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.wallets.Get(ctx, walletID)
+	if !ok {
+		return nil
+	}
+
+	w.balance = newBalance
+	r.wallets.Put(ctx, walletID, w)
+
+	return nil
+}
+
+// SaveOperation ...
+func (r *WalletRepository) SaveOperation(ctx context.Context, op usecase.Operation) (time.Time, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.insertOperation(ctx, &op); err != nil {
+		return time.Time{}, fmt.Errorf("save operation: %w", err)
+	}
+
+	return op.CreatedAt, nil
+}
+
+// insertOperation проставляет op.CreatedAt и op.Seq и сохраняет операцию.
+// Вызывается под r.mu.
+func (r *WalletRepository) insertOperation(ctx context.Context, op *usecase.Operation) error {
+	if _, ok := r.wallets.Get(ctx, op.WalletID); !ok {
+		return fmt.Errorf("wallet %s does not exist", op.WalletID)
+	}
+	if _, ok := r.operations.Get(ctx, op.ID); ok {
+		return fmt.Errorf("operation %s already exists", op.ID)
+	}
+	if err := checkOperation(*op); err != nil {
+		return err
+	}
+
+	r.opSeq++
+	op.CreatedAt = now()
+	op.Seq = r.opSeq
+	r.operations.Put(ctx, op.ID, *op)
+
+	return nil
+}
+
+// checkOperation ограничения wallet_operations: известный тип и
+// положительная сумма, у ADJUSTMENT - любая ненулевая.
+func checkOperation(op usecase.Operation) error {
+	switch op.Type {
+	case "DEPOSIT", "WITHDRAW", "TRANSFER_OUT", "TRANSFER_IN":
+		if op.Amount <= 0 {
+			return fmt.Errorf("operation amount %d must be positive", op.Amount)
+		}
+	case "ADJUSTMENT":
+		if op.Amount == 0 {
+			return errors.New("adjustment amount must not be zero")
+		}
+	default:
+		return fmt.Errorf("invalid operation type %q", op.Type)
+	}
+
+	return nil
+}
+
+// GetOperation ...
+func (r *WalletRepository) GetOperation(ctx context.Context, operationID uuid.UUID) (usecase.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	op, ok := r.operations.Get(ctx, operationID)
+	if !ok {
+		return usecase.Operation{}, walleterror.ErrOperationNotFound
+	}
+
+	return op, nil
+}
+
+// ListOperations ...
+func (r *WalletRepository) ListOperations(ctx context.Context, filter model.OperationFilter) ([]usecase.Operation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ops := make([]usecase.Operation, 0, filter.Limit)
+	for _, op := range r.operations.All(ctx) {
+		if op.WalletID != filter.WalletID {
+			continue
+		}
+		if len(filter.Types) > 0 && !slices.Contains(filter.Types, op.Type) {
+			continue
+		}
+		if !filter.From.IsZero() && op.CreatedAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !op.CreatedAt.Before(filter.To) {
+			continue
+		}
+		if filter.After != nil && compareOperations(op, filter.After.CreatedAt, filter.After.Seq) >= 0 {
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	// ORDER BY created_at DESC, seq DESC
+	slices.SortFunc(ops, func(a, b usecase.Operation) int {
+		return compareOperations(b, a.CreatedAt, a.Seq)
+	})
+	if len(ops) > filter.Limit {
+		ops = ops[:filter.Limit]
+	}
+
+	return ops, nil
+}
+
+// compareOperations сравнивает (created_at, seq) операции с (createdAt, seq),
+// как сравнение строк в Postgres.
+func compareOperations(op usecase.Operation, createdAt time.Time, seq int64) int {
+	if c := op.CreatedAt.Compare(createdAt); c != 0 {
+		return c
+	}
+	return cmp.Compare(op.Seq, seq)
+}
+
+// ClaimIdempotencyKey ...
+func (r *WalletRepository) ClaimIdempotencyKey(
+	ctx context.Context, key, fingerprint string, ttl time.Duration,
+) (model.IdempotencyRecord, bool, error) {
+	// Если ключ держит незавершённая транзакция, ждём её окончания, как
+	// INSERT ... ON CONFLICT.
+	if err := r.store.Lock(ctx, idempotencyKey(key)); err != nil {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Просроченный ключ перезаписывается, как будто его не было.
+	old, exists := r.idempotency.Get(ctx, key)
+	if exists && old.expiresAt.After(now()) {
+		return old.IdempotencyRecord, true, nil
+	}
+
+	r.idempotency.Put(ctx, key, idempotencyRow{
+		IdempotencyRecord: model.IdempotencyRecord{Key: key, Fingerprint: fingerprint},
+		expiresAt:         now().Add(ttl),
+	})
+
+	return model.IdempotencyRecord{}, false, nil
+}
+
+// DeleteExpiredIdempotencyKeys ...
+func (r *WalletRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, limit int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := now()
+	var expired []string
+	for key, row := range r.idempotency.All(ctx) {
+		if len(expired) >= limit {
+			break
+		}
+		if !row.expiresAt.After(t) {
+			expired = append(expired, key)
+		}
+	}
+	for _, key := range expired {
+		r.idempotency.Delete(ctx, key)
+	}
+
+	return len(expired), nil
+}
+
+// SaveIdempotencyResponse ...
+func (r *WalletRepository) SaveIdempotencyResponse(ctx context.Context, key string, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.idempotency.Get(ctx, key)
+	if !ok {
+		return nil
+	}
+
+	row.Response = response
+	r.idempotency.Put(ctx, key, row)
+
+	return nil
+}
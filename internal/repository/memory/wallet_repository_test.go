@@ -0,0 +1,176 @@
+// Package memory_test ...
+package memory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"wallet/internal/driver/memstore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/repository/memory"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Репозиторий в памяти должен подходить везде, где подходит Postgres.
+var (
+	_ usecase.WalletRepository    = (*memory.WalletRepository)(nil)
+	_ usecase.ReconcileRepository = (*memory.WalletRepository)(nil)
+	_ usecase.OutboxRepository    = (*memory.WalletRepository)(nil)
+	_ usecase.WebhookRepository   = (*memory.WalletRepository)(nil)
+	_ usecase.EventRepository     = (*memory.WalletRepository)(nil)
+	_ usecase.TxManager           = (*memstore.Store)(nil)
+)
+
+func setupRepo(t testing.TB) (*memory.WalletRepository, *memstore.Store) {
+	t.Helper()
+
+	cfg := memstore.DefaultConfig()
+	cfg.TxRetryBaseDelay = time.Millisecond
+	cfg.TxRetryMaxDelay = 5 * time.Millisecond
+
+	store := memstore.New(cfg)
+	return memory.New(store), store
+}
+
+// createWallet создаёт кошелёк с балансом, открытым журнальной записью
+// против счёта корректировок.
+func createWallet(t testing.TB, repo *memory.WalletRepository, balance int64) uuid.UUID {
+	t.Helper()
+
+	ctx := context.Background()
+	id := uuid.New()
+	require.NoError(t, repo.CreateWallet(ctx, id))
+
+	if balance != 0 {
+		err := repo.PostEntry(ctx, model.JournalEntry{
+			ID:          uuid.New(),
+			Description: "OPENING_BALANCE",
+			Postings: []model.Posting{
+				{AccountID: id, Amount: balance},
+				{AccountID: model.AdjustmentsAccountID, Amount: -balance},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	return id
+}
+
+func accountBalance(t testing.TB, repo *memory.WalletRepository, accountID uuid.UUID) int64 {
+	t.Helper()
+
+	balance, ok := repo.AccountBalance(context.Background(), accountID)
+	require.True(t, ok)
+	return balance
+}
+
+func TestRepository_StartsEmpty(t *testing.T) {
+	repo, _ := setupRepo(t)
+
+	_, err := repo.GetBalance(context.Background(), uuid.MustParse("11111111-1111-1111-1111-111111111111"))
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	assert.Zero(t, accountBalance(t, repo, model.AdjustmentsAccountID))
+}
+
+func TestRepository_RollbackOnError(t *testing.T) {
+	repo, store := setupRepo(t)
+	uc := usecase.New(repo, store)
+	ctx := context.Background()
+
+	walletID := createWallet(t, repo, 100)
+	fundingBefore := accountBalance(t, repo, model.ExternalFundingAccountID)
+
+	var opID uuid.UUID
+	errRollback := errors.New("rollback")
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		receipt, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 50})
+		require.NoError(t, err)
+		opID = receipt.Operation.ID
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	balance, err := repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance)
+	assert.Equal(t, int64(100), accountBalance(t, repo, walletID))
+	assert.Equal(t, fundingBefore, accountBalance(t, repo, model.ExternalFundingAccountID))
+
+	_, err = repo.GetOperation(ctx, opID)
+	assert.ErrorIs(t, err, walleterror.ErrOperationNotFound)
+
+	events, err := repo.ListWalletEvents(ctx, walletID, 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestRepository_ListOperations_Paginates(t *testing.T) {
+	repo, store := setupRepo(t)
+	uc := usecase.New(repo, store)
+	ctx := context.Background()
+
+	walletID := createWallet(t, repo, 0)
+	for range 5 {
+		_, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 1})
+		require.NoError(t, err)
+	}
+
+	var seen []uuid.UUID
+	cursor := ""
+	for {
+		page, err := uc.ListOperations(ctx, model.ListOperationsInput{WalletID: walletID, Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		for _, op := range page.Operations {
+			seen = append(seen, op.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Len(t, seen, 5)
+	assert.Len(t, uniq(seen), 5)
+}
+
+func TestRepository_OutboxEventsCommittedInOrder(t *testing.T) {
+	repo, store := setupRepo(t)
+	uc := usecase.New(repo, store)
+	ctx := context.Background()
+
+	walletID := createWallet(t, repo, 0)
+	for range 3 {
+		_, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 1})
+		require.NoError(t, err)
+	}
+
+	var events []model.Event
+	err := store.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		events, err = repo.ClaimPendingEvents(ctx, 10, time.Minute)
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Less(t, events[0].Sequence, events[1].Sequence)
+	assert.Less(t, events[1].Sequence, events[2].Sequence)
+
+	snapshot, err := repo.GetWalletSnapshot(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, events[2].Sequence, snapshot.Sequence)
+	assert.Equal(t, int64(3), snapshot.Balance)
+}
+
+func uniq(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	m := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		m[id] = struct{}{}
+	}
+	return m
+}
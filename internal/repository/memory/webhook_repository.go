@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type deliveryRow struct {
+	delivery      model.WebhookDelivery
+	status        model.WebhookDeliveryStatus
+	nextAttemptAt time.Time
+}
+
+// CreateWebhook ...
+func (r *WalletRepository) CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error) {
+	if hook.EventTypes == nil {
+		hook.EventTypes = []string{}
+	}
+	if hook.WalletIDs == nil {
+		hook.WalletIDs = []uuid.UUID{}
+	}
+	hook.CreatedAt = now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.webhooks.Put(ctx, hook.ID, hook)
+
+	return hook, nil
+}
+
+// GetWebhook ...
+func (r *WalletRepository) GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hook, ok := r.webhooks.Get(ctx, id)
+	if !ok {
+		return model.Webhook{}, walleterror.ErrWebhookNotFound
+	}
+
+	return hook, nil
+}
+
+// ListWebhooks ...
+func (r *WalletRepository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	hooks := make([]model.Webhook, 0)
+	for _, hook := range r.webhooks.All(ctx) {
+		hooks = append(hooks, hook)
+	}
+	slices.SortFunc(hooks, func(a, b model.Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+
+	return hooks, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её доставками и попытками.
+func (r *WalletRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.webhooks.Get(ctx, id); !ok {
+		return walleterror.ErrWebhookNotFound
+	}
+
+	var deliveries, attempts []int64
+	for deliveryID, row := range r.deliveries.All(ctx) {
+		if row.delivery.WebhookID == id {
+			deliveries = append(deliveries, deliveryID)
+		}
+	}
+	for attemptID, a := range r.attempts.All(ctx) {
+		if a.WebhookID == id {
+			attempts = append(attempts, attemptID)
+		}
+	}
+
+	for _, deliveryID := range deliveries {
+		r.deliveries.Delete(ctx, deliveryID)
+	}
+	for _, attemptID := range attempts {
+		r.attempts.Delete(ctx, attemptID)
+	}
+	r.webhooks.Delete(ctx, id)
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries создаёт доставку события для каждой подходящей
+// подписки. Повторный вызов для того же события ничего не добавляет.
+func (r *WalletRepository) EnqueueWebhookDeliveries(ctx context.Context, event model.Event, payload []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var added int
+	for _, hook := range r.webhooks.All(ctx) {
+		if len(hook.EventTypes) > 0 && !slices.Contains(hook.EventTypes, event.Type) {
+			continue
+		}
+		if len(hook.WalletIDs) > 0 && !slices.Contains(hook.WalletIDs, event.WalletID) {
+			continue
+		}
+		if r.hasDelivery(ctx, hook.ID, event.ID) {
+			continue
+		}
+
+		r.deliverySeq++
+		r.deliveries.Put(ctx, r.deliverySeq, deliveryRow{
+			delivery: model.WebhookDelivery{
+				ID:        r.deliverySeq,
+				WebhookID: hook.ID,
+				EventID:   event.ID,
+				EventType: event.Type,
+				Payload:   payload,
+			},
+			status:        model.WebhookDeliveryPending,
+			nextAttemptAt: now(),
+		})
+		added++
+	}
+
+	return added, nil
+}
+
+// hasDelivery вызывается под r.mu.
+func (r *WalletRepository) hasDelivery(ctx context.Context, webhookID, eventID uuid.UUID) bool {
+	for _, row := range r.deliveries.All(ctx) {
+		if row.delivery.WebhookID == webhookID && row.delivery.EventID == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimDueWebhookDeliveries забирает в аренду на lease до limit доставок,
+// которым пора повторить попытку: следующая попытка переносится на конец
+// аренды, и до него доставку не получит никто другой. Доставки, занятые
+// другой транзакцией, пропускаются.
+func (r *WalletRepository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t := now()
+	var due []deliveryRow
+	for _, row := range r.deliveries.All(ctx) {
+		if row.status == model.WebhookDeliveryPending && !row.nextAttemptAt.After(t) {
+			due = append(due, row)
+		}
+	}
+
+	slices.SortFunc(due, func(a, b deliveryRow) int {
+		if c := a.nextAttemptAt.Compare(b.nextAttemptAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.delivery.ID, b.delivery.ID)
+	})
+
+	deliveries := make([]model.WebhookDelivery, 0, limit)
+	for _, row := range due {
+		if len(deliveries) == limit {
+			break
+		}
+		if !r.store.TryLock(ctx, deliveryKey(row.delivery.ID)) {
+			continue
+		}
+
+		row.nextAttemptAt = t.Add(lease)
+		r.deliveries.Put(ctx, row.delivery.ID, row)
+
+		hook, _ := r.webhooks.Get(ctx, row.delivery.WebhookID)
+		d := row.delivery
+		d.URL = hook.URL
+		d.Secret = hook.Secret
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt сохраняет попытку и переводит доставку в её статус.
+// Для PENDING следующая попытка назначается через retryIn.
+func (r *WalletRepository) RecordWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt, retryIn time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	row, ok := r.deliveries.Get(ctx, attempt.DeliveryID)
+	if !ok {
+		return nil
+	}
+
+	row.status = attempt.Status
+	row.delivery.Attempts = attempt.Attempt
+	row.nextAttemptAt = now().Add(retryIn)
+	r.deliveries.Put(ctx, attempt.DeliveryID, row)
+
+	r.attemptSeq++
+	attempt.ID = r.attemptSeq
+	attempt.WebhookID = row.delivery.WebhookID
+	attempt.EventID = row.delivery.EventID
+	attempt.EventType = row.delivery.EventType
+	attempt.Duration = attempt.Duration.Truncate(time.Millisecond)
+	attempt.CreatedAt = now()
+	r.attempts.Put(ctx, attempt.ID, attempt)
+
+	return nil
+}
+
+// ListWebhookAttempts последние попытки доставки по подписке, новые первыми.
+func (r *WalletRepository) ListWebhookAttempts(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookAttempt, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	attempts := make([]model.WebhookAttempt, 0, limit)
+	for _, a := range r.attempts.All(ctx) {
+		if a.WebhookID == webhookID {
+			attempts = append(attempts, a)
+		}
+	}
+	slices.SortFunc(attempts, func(a, b model.WebhookAttempt) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if len(attempts) > limit {
+		attempts = attempts[:limit]
+	}
+
+	return attempts, nil
+}
@@ -0,0 +1,39 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	"wallet/internal/model"
+	"wallet/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// --- Outbox ---
+
+func TestRepository_Outbox_MarkEventFailedRecordsAttempt(t *testing.T) {
+	pool, _ := setupDB(t)
+	repo := repository.New(pool)
+	ctx := context.Background()
+
+	walletID := createWallet(t, pool, 0)
+	err := repo.SaveEvent(ctx, model.Event{ID: uuid.New(), Type: "DEPOSIT", WalletID: walletID})
+	require.NoError(t, err)
+
+	var sequence int64
+	err = pool.QueryRow(ctx, `SELECT id FROM outbox WHERE wallet_id = $1`, walletID).Scan(&sequence)
+	require.NoError(t, err)
+	require.NoError(t, repo.MarkEventFailed(ctx, sequence, time.Hour, "unavailable"))
+
+	var attempts int
+	var lastError string
+	err = pool.QueryRow(ctx,
+		`SELECT attempts, last_error FROM outbox WHERE id = $1`, sequence,
+	).Scan(&attempts, &lastError)
+	require.NoError(t, err)
+	assert.Equal(t, 1, attempts)
+	assert.Equal(t, "unavailable", lastError)
+}
@@ -0,0 +1,54 @@
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+)
+
+// BenchHotWallet сравнивает режимы обновления баланса и group commit на
+// одном кошельке, который параллельно пополняют многие клиенты - как bench.js
+// со 100 VU. Group commit всегда проводит группу как BALANCE_UPDATE=locked,
+// поэтому у него один вариант.
+func BenchHotWallet(b *testing.B, newBackend Factory) {
+	variants := []struct {
+		name string
+		new  func(b Backend) depositor
+	}{
+		{name: "locked", new: func(b Backend) depositor {
+			return usecase.New(b.Repo, b.TxManager, usecase.WithBalanceUpdate(usecase.BalanceUpdateLocked))
+		}},
+		{name: "atomic", new: func(b Backend) depositor {
+			return usecase.New(b.Repo, b.TxManager, usecase.WithBalanceUpdate(usecase.BalanceUpdateAtomic))
+		}},
+		{name: "group", new: func(b Backend) depositor {
+			return usecase.NewGroupCommit(usecase.New(b.Repo, b.TxManager))
+		}},
+	}
+
+	for _, v := range variants {
+		for _, clients := range []int{1, 16, 100} {
+			b.Run(fmt.Sprintf("%s/clients=%d", v.name, clients), func(b *testing.B) {
+				backend := newBackend(b)
+				uc := v.new(backend)
+				walletID := createWallet(b, backend, 0)
+				ctx := context.Background()
+
+				// RunParallel запускает clients*GOMAXPROCS горутин.
+				b.SetParallelism(clients)
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						_, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 1})
+						if err != nil {
+							b.Error(err)
+							return
+						}
+					}
+				})
+			})
+		}
+	}
+}
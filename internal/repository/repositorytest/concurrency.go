@@ -0,0 +1,221 @@
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConcurrentDeposit(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	const goroutines = 100
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+
+	for range goroutines {
+		go func() {
+			defer wg.Done()
+
+			err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+				balance, err := b.Repo.GetBalanceForUpdate(ctx, walletID)
+				if err != nil {
+					return err
+				}
+				return b.Repo.UpdateBalance(ctx, walletID, balance+1)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(goroutines), balance)
+}
+
+func testConcurrentWithdraw(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	const goroutines = 50
+	const initialBalance = int64(goroutines) // ровно столько, чтобы хватило на всех
+
+	walletID := createWallet(t, b, initialBalance)
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines * 2)
+
+	// Вдвое больше желающих, чем денег: лишние видят нулевой баланс.
+	for range goroutines * 2 {
+		go func() {
+			defer wg.Done()
+
+			err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+				balance, err := b.Repo.GetBalanceForUpdate(ctx, walletID)
+				if err != nil {
+					return err
+				}
+				if balance < 1 {
+					return nil
+				}
+				return b.Repo.UpdateBalance(ctx, walletID, balance-1)
+			})
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), balance)
+}
+
+func testConcurrentApplyOperation(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	const goroutines = 100
+	const initialBalance = int64(goroutines / 2)
+
+	walletID := createWallet(t, b, initialBalance)
+
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		insufficient int
+	)
+	wg.Add(goroutines)
+
+	for range goroutines {
+		go func() {
+			defer wg.Done()
+
+			err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+				op := usecase.Operation{ID: uuid.New(), WalletID: walletID, Type: "WITHDRAW", Amount: 1}
+				_, err := b.Repo.ApplyOperation(ctx, op, withdrawEntry(op.ID, walletID, 1))
+				return err
+			})
+			if errors.Is(err, walleterror.ErrInsufficientFunds) {
+				mu.Lock()
+				insufficient++
+				mu.Unlock()
+				return
+			}
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), balance)
+	assert.Equal(t, goroutines-int(initialBalance), insufficient)
+	requireLedgerMatches(t, b, walletID)
+}
+
+// depositor общее у WalletUsecase и GroupCommit.
+type depositor interface {
+	Deposit(ctx context.Context, in model.DepositInput) (model.Receipt, error)
+	Withdraw(ctx context.Context, in model.WithdrawInput) (model.Receipt, error)
+}
+
+// runMixed параллельно пополняет кошелёк на 1 и списывает по 2: списания,
+// уводящие в минус, отклоняются, остальные должны сойтись до копейки.
+func runMixed(t *testing.T, b Backend, uc depositor) {
+	ctx := context.Background()
+
+	const goroutines = 100
+	const initialBalance = int64(goroutines / 4)
+
+	walletID := createWallet(t, b, initialBalance)
+
+	var (
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+		insufficient int
+	)
+	wg.Add(goroutines)
+
+	for i := range goroutines {
+		go func() {
+			defer wg.Done()
+
+			var err error
+			if i%2 == 0 {
+				_, err = uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 1})
+			} else {
+				_, err = uc.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 2})
+			}
+			if errors.Is(err, walleterror.ErrInsufficientFunds) {
+				mu.Lock()
+				insufficient++
+				mu.Unlock()
+				return
+			}
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	withdrawn := int64(goroutines/2-insufficient) * 2
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, initialBalance+goroutines/2-withdrawn, balance)
+	assert.GreaterOrEqual(t, balance, int64(0))
+	requireLedgerMatches(t, b, walletID)
+}
+
+func testConcurrentUsecaseMixed(t *testing.T, b Backend) {
+	runMixed(t, b, usecase.New(b.Repo, b.TxManager))
+}
+
+func testConcurrentGroupCommit(t *testing.T, b Backend) {
+	runMixed(t, b, usecase.NewGroupCommit(usecase.New(b.Repo, b.TxManager)))
+}
+
+func testConcurrentTransfers(t *testing.T, b Backend) {
+	uc := usecase.New(b.Repo, b.TxManager)
+	ctx := context.Background()
+
+	const goroutines = 100
+	a := createWallet(t, b, goroutines)
+	c := createWallet(t, b, goroutines)
+
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+
+	// Встречные переводы A->C и C->A не должны ловить дедлок.
+	for i := range goroutines {
+		go func() {
+			defer wg.Done()
+
+			in := model.TransferInput{FromWalletID: a, ToWalletID: c, Amount: 1}
+			if i%2 == 1 {
+				in.FromWalletID, in.ToWalletID = c, a
+			}
+			_, err := uc.Transfer(ctx, in)
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, id := range []uuid.UUID{a, c} {
+		balance, err := b.Repo.GetBalance(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(goroutines), balance)
+		requireLedgerMatches(t, b, id)
+	}
+}
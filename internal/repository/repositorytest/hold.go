@@ -0,0 +1,79 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHoldsAvailableBalance(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 1000)
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := b.Repo.CreateHold(ctx, model.Hold{ID: uuid.New(), WalletID: walletID, Amount: 300}, time.Minute); err != nil {
+			return err
+		}
+		// Холд с истёкшим сроком не уменьшает доступный баланс.
+		_, err := b.Repo.CreateHold(ctx, model.Hold{ID: uuid.New(), WalletID: walletID, Amount: 200}, -time.Second)
+		return err
+	})
+	require.NoError(t, err)
+
+	wb, err := b.Repo.GetWalletBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), wb.Balance)
+	assert.Equal(t, int64(700), wb.AvailableBalance)
+}
+
+func testGetHoldForUpdateExpired(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 1000)
+	holdID := uuid.New()
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := b.Repo.CreateHold(ctx, model.Hold{ID: holdID, WalletID: walletID, Amount: 300}, -time.Second); err != nil {
+			return err
+		}
+		hold, err := b.Repo.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, model.HoldExpired, hold.Status)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func testHoldLifecycle(t *testing.T, b Backend) {
+	ctx := context.Background()
+	uc := usecase.New(b.Repo, b.TxManager)
+	walletID := createWallet(t, b, 1000)
+
+	hold, err := uc.CreateHold(ctx, model.CreateHoldInput{WalletID: walletID, Amount: 600})
+	require.NoError(t, err)
+
+	// Зарезервированные деньги нельзя списать обычным выводом.
+	_, err = uc.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 500})
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+
+	receipt, err := uc.CaptureHold(ctx, model.CaptureHoldInput{WalletID: walletID, HoldID: hold.ID, Amount: 250})
+	require.NoError(t, err)
+	assert.Equal(t, int64(750), receipt.BalanceAfter)
+
+	// Остаток холда освобождён.
+	wb, err := uc.Balance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WalletBalance{Balance: 750, AvailableBalance: 750}, wb)
+	requireLedgerMatches(t, b, walletID)
+
+	_, err = uc.VoidHold(ctx, model.VoidHoldInput{WalletID: walletID, HoldID: hold.ID})
+	require.ErrorIs(t, err, walleterror.ErrHoldNotActive)
+}
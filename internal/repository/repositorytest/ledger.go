@@ -0,0 +1,42 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPostEntry(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	var fundingBefore int64
+	if b.AccountBalance != nil {
+		fundingBefore = b.AccountBalance(t, model.ExternalFundingAccountID)
+	}
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		return b.Repo.PostEntry(ctx, model.JournalEntry{
+			ID:          uuid.New(),
+			Description: "DEPOSIT",
+			Postings: []model.Posting{
+				{AccountID: walletID, Amount: 40},
+				{AccountID: model.ExternalFundingAccountID, Amount: -40},
+			},
+		})
+	})
+	require.NoError(t, err)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(140), balance)
+
+	requireLedgerMatches(t, b, walletID)
+	if b.AccountBalance != nil {
+		assert.Equal(t, fundingBefore-40, b.AccountBalance(t, model.ExternalFundingAccountID))
+	}
+}
@@ -0,0 +1,244 @@
+package repositorytest
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func saveEvents(t *testing.T, b Backend, walletID uuid.UUID, n int) []uuid.UUID {
+	t.Helper()
+
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+		err := b.Repo.SaveEvent(context.Background(), model.Event{
+			ID:       ids[i],
+			Type:     "DEPOSIT",
+			WalletID: walletID,
+			Payload:  json.RawMessage(`{"amount":100}`),
+		})
+		require.NoError(t, err)
+	}
+	return ids
+}
+
+// walletEvents оставляет события одного кошелька: в outbox общего хранилища
+// могут быть события других тестов.
+func walletEvents(events []model.Event, walletID uuid.UUID) []model.Event {
+	var out []model.Event
+	for _, e := range events {
+		if e.WalletID == walletID {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func testOutboxClaimAndDeliver(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+	ids := saveEvents(t, b, walletID, 3)
+
+	var events []model.Event
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		events, err = b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Отметки о доставке идут уже вне транзакции захвата.
+	events = walletEvents(events, walletID)
+	require.Len(t, events, 3)
+	for i, e := range events {
+		assert.Equal(t, ids[i], e.ID)
+		assert.JSONEq(t, `{"amount":100}`, string(e.Payload))
+		if i > 0 {
+			assert.Greater(t, e.Sequence, events[i-1].Sequence)
+		}
+		require.NoError(t, b.Repo.MarkEventDelivered(ctx, e.Sequence))
+	}
+
+	events, err = b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, walletEvents(events, walletID))
+}
+
+func testOutboxLeaseBlocksSecondClaim(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+	saveEvents(t, b, walletID, 2)
+
+	events, err := b.Repo.ClaimPendingEvents(ctx, 1000, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, walletEvents(events, walletID), 2)
+
+	// Пока аренда не истекла, второй диспетчер ничего не получает.
+	events, err = b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, walletEvents(events, walletID))
+
+	time.Sleep(100 * time.Millisecond)
+
+	events, err = b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, walletEvents(events, walletID), 2)
+	for _, e := range events {
+		require.NoError(t, b.Repo.MarkEventDelivered(ctx, e.Sequence))
+	}
+}
+
+func testOutboxFailedHeadBlocksQueue(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+	saveEvents(t, b, walletID, 2)
+
+	events, err := b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	events = walletEvents(events, walletID)
+	require.Len(t, events, 2)
+	require.NoError(t, b.Repo.MarkEventFailed(ctx, events[0].Sequence, time.Hour, "unavailable"))
+
+	events, err = b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, walletEvents(events, walletID))
+}
+
+func testOutboxDeadEventUnblocksQueue(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+	ids := saveEvents(t, b, walletID, 2)
+
+	events, err := b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	events = walletEvents(events, walletID)
+	require.Len(t, events, 2)
+	require.NoError(t, b.Repo.MarkEventDead(ctx, events[0].Sequence, "rejected"))
+	require.NoError(t, b.Repo.MarkEventFailed(ctx, events[1].Sequence, 0, "unavailable"))
+
+	// Событие в dead letter больше не захватывается и не держит очередь.
+	events, err = b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	events = walletEvents(events, walletID)
+	require.Len(t, events, 1)
+	assert.Equal(t, ids[1], events[0].ID)
+	assert.Equal(t, 1, events[0].Attempts)
+	require.NoError(t, b.Repo.MarkEventDelivered(ctx, events[0].Sequence))
+}
+
+func testOutboxDeleteDeliveredEvents(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+	ids := saveEvents(t, b, walletID, 3)
+
+	fanned, err := b.Repo.ClaimWebhookEvents(ctx, 1000)
+	require.NoError(t, err)
+	require.Len(t, walletEvents(fanned, walletID), 3)
+
+	// Четвёртое событие доставлено, но ещё не разослано подпискам.
+	ids = append(ids, saveEvents(t, b, walletID, 1)...)
+	events, err := b.Repo.ListWalletEvents(ctx, walletID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	for _, i := range []int{0, 1, 3} {
+		require.NoError(t, b.Repo.MarkEventDelivered(ctx, events[i].Sequence))
+	}
+
+	// Хранилище может быть общим с другими тестами: дочищаем до конца.
+	var n int
+	for range 100 {
+		n, err = b.Repo.DeleteDeliveredEvents(ctx, 0, 1000)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+	assert.Zero(t, n)
+
+	events, err = b.Repo.ListWalletEvents(ctx, walletID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, ids[2], events[0].ID)
+	assert.Equal(t, ids[3], events[1].ID)
+
+	// Недавно доставленные события остаются до конца срока хранения.
+	_, err = b.Repo.ClaimWebhookEvents(ctx, 1000)
+	require.NoError(t, err)
+	n, err = b.Repo.DeleteDeliveredEvents(ctx, time.Hour, 1000)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func testOutboxWebhookFanoutIgnoresHead(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+	ids := saveEvents(t, b, walletID, 2)
+
+	events, err := b.Repo.ClaimPendingEvents(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	events = walletEvents(events, walletID)
+	require.Len(t, events, 2)
+	require.NoError(t, b.Repo.MarkEventFailed(ctx, events[0].Sequence, time.Hour, "unavailable"))
+
+	// Откат транзакции возвращает события в рассылку.
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		fanned, err := b.Repo.ClaimWebhookEvents(ctx, 1000)
+		require.NoError(t, err)
+		require.Len(t, walletEvents(fanned, walletID), 2)
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	// Застрявшая голова outbox рассылку не держит.
+	fanned, err := b.Repo.ClaimWebhookEvents(ctx, 1000)
+	require.NoError(t, err)
+	fanned = walletEvents(fanned, walletID)
+	require.Len(t, fanned, 2)
+	assert.Equal(t, ids[0], fanned[0].ID)
+	assert.Equal(t, ids[1], fanned[1].ID)
+
+	fanned, err = b.Repo.ClaimWebhookEvents(ctx, 1000)
+	require.NoError(t, err)
+	assert.Empty(t, walletEvents(fanned, walletID))
+}
+
+func testWalletEventsSnapshotAndList(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 300)
+
+	snapshot, err := b.Repo.GetWalletSnapshot(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), snapshot.Balance)
+	assert.Equal(t, int64(300), snapshot.AvailableBalance)
+	assert.Zero(t, snapshot.Sequence)
+
+	ids := saveEvents(t, b, walletID, 3)
+	saveEvents(t, b, createWallet(t, b, 0), 1)
+
+	events, err := b.Repo.ListWalletEvents(ctx, walletID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	for i, e := range events {
+		assert.Equal(t, ids[i], e.ID)
+	}
+
+	snapshot, err = b.Repo.GetWalletSnapshot(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, events[2].Sequence, snapshot.Sequence)
+
+	after, err := b.Repo.ListWalletEvents(ctx, walletID, events[0].Sequence, 1)
+	require.NoError(t, err)
+	require.Len(t, after, 1)
+	assert.Equal(t, ids[1], after[0].ID)
+
+	_, err = b.Repo.GetWalletSnapshot(ctx, uuid.New())
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+}
@@ -0,0 +1,115 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStreamLedgerBalances(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 250)
+
+	// Операции без изменения баланса: сумма по ним сходится с балансом.
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		for _, op := range []usecase.Operation{
+			{ID: uuid.New(), WalletID: walletID, Type: "DEPOSIT", Amount: 500},
+			{ID: uuid.New(), WalletID: walletID, Type: "WITHDRAW", Amount: 100},
+			{ID: uuid.New(), WalletID: walletID, Type: "TRANSFER_OUT", Amount: 200},
+			{ID: uuid.New(), WalletID: walletID, Type: "TRANSFER_IN", Amount: 50},
+		} {
+			if _, err := b.Repo.SaveOperation(ctx, op); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	var got model.LedgerBalance
+	err = b.Repo.StreamLedgerBalances(ctx, func(lb model.LedgerBalance) error {
+		if lb.WalletID == walletID {
+			got = lb
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(250), got.Balance)
+	assert.Equal(t, int64(250), got.LedgerSum)
+	assert.Zero(t, got.Difference())
+}
+
+func testAdjustmentFixesMismatch(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	// Кошелёк с балансом без операций, как после make seed.
+	walletID := createWallet(t, b, 1000)
+
+	op, err := usecase.NewReconcile(b.Repo, b.TxManager).Adjust(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), op.Amount)
+
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		lb, err := b.Repo.GetLedgerBalanceForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+		assert.Equal(t, int64(1000), lb.LedgerSum)
+		assert.Zero(t, lb.Difference())
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func testAdjustmentNegative(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := b.Repo.SaveOperation(ctx, usecase.Operation{
+			ID: uuid.New(), WalletID: walletID, Type: "DEPOSIT", Amount: 300,
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	op, err := usecase.NewReconcile(b.Repo, b.TxManager).Adjust(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-300), op.Amount)
+}
+
+func testGetBalanceAt(t *testing.T, b Backend) {
+	ctx := context.Background()
+	uc := usecase.New(b.Repo, b.TxManager)
+	walletID := createWallet(t, b, 0)
+
+	receipt, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 500})
+	require.NoError(t, err)
+	afterDeposit := receipt.Operation.CreatedAt
+
+	_, err = uc.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 200})
+	require.NoError(t, err)
+
+	balance, createdAt, err := b.Repo.GetBalanceAt(ctx, walletID, afterDeposit)
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), balance)
+	assert.False(t, afterDeposit.Before(createdAt))
+
+	balance, _, err = b.Repo.GetBalanceAt(ctx, walletID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(300), balance)
+
+	_, err = uc.BalanceAt(ctx, walletID, createdAt.Add(-time.Hour))
+	require.ErrorIs(t, err, walleterror.ErrAsOfBeforeCreation)
+
+	_, _, err = b.Repo.GetBalanceAt(ctx, uuid.New(), time.Now())
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+}
@@ -0,0 +1,160 @@
+// Package repositorytest общий набор тестов для хранилищ кошельков. Каждая
+// реализация usecase.WalletRepository и usecase.TxManager прогоняет его из
+// своих тестов через Run, поэтому все хранилища держатся одной семантики:
+// балансы, ошибки «не найдено», блокировки, откаты, конкурентные
+// пополнения и списания, холды, главная книга, сверка, outbox и вебхуки.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// Repository репозиторий под тестом. UpdateBalance нет в
+// usecase.WalletRepository, но на прямой записи баланса видно, теряются
+// ли обновления без блокировки.
+type Repository interface {
+	usecase.WalletRepository
+	usecase.IdempotencyRepository
+	usecase.ReconcileRepository
+	usecase.OutboxRepository
+	usecase.EventRepository
+	usecase.WebhookRepository
+	UpdateBalance(ctx context.Context, walletID uuid.UUID, newBalance int64) error
+}
+
+// Backend хранилище под тестом.
+type Backend struct {
+	Repo      Repository
+	TxManager usecase.TxManager
+
+	// AccountBalance баланс счёта главной книги. Если не задан, проверки
+	// главной книги пропускаются.
+	AccountBalance func(t testing.TB, accountID uuid.UUID) int64
+	// DeleteWallet убирает кошелёк, созданный тестом, для хранилищ, общих
+	// для нескольких тестов. Необязателен.
+	DeleteWallet func(walletID uuid.UUID)
+}
+
+// Factory создаёт хранилище для одного теста. Может пропустить тест через
+// t.Skip, если хранилище недоступно.
+type Factory func(t testing.TB) Backend
+
+// Run прогоняет весь набор против хранилищ из newBackend. Каждый подтест
+// получает своё хранилище.
+func Run(t *testing.T, newBackend Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, b Backend)
+	}{
+		{"Balance", testBalance},
+		{"NotFound", testNotFound},
+		{"CreateWallet_AlreadyExists", testCreateWalletAlreadyExists},
+		{"UpdateBalance_Negative", testUpdateBalanceNegative},
+		{"Operation_RoundTrip", testOperationRoundTrip},
+		{"SaveOperation_InvalidType", testSaveOperationInvalidType},
+		{"ListOperations_Pagination", testListOperationsPagination},
+		{"ListOperations_CursorBoundary", testListOperationsCursorBoundary},
+		{"ListOperations_AtomicBatchOrder", testListOperationsAtomicBatchOrder},
+		{"ListOperations_GroupCommitOrder", testListOperationsGroupCommitOrder},
+		{"PostEntry", testPostEntry},
+		{"PostEntry_Rejected", testPostEntryRejected},
+		{"ApplyOperation", testApplyOperation},
+		{"ApplyOperation_Rejected", testApplyOperationRejected},
+		{"Holds_AvailableBalance", testHoldsAvailableBalance},
+		{"GetHoldForUpdate_Expired", testGetHoldForUpdateExpired},
+		{"HoldLifecycle", testHoldLifecycle},
+		{"StreamLedgerBalances", testStreamLedgerBalances},
+		{"Adjustment_FixesMismatch", testAdjustmentFixesMismatch},
+		{"Adjustment_Negative", testAdjustmentNegative},
+		{"GetBalanceAt", testGetBalanceAt},
+		{"Outbox_ClaimAndDeliver", testOutboxClaimAndDeliver},
+		{"Outbox_LeaseBlocksSecondClaim", testOutboxLeaseBlocksSecondClaim},
+		{"Outbox_FailedHeadBlocksQueue", testOutboxFailedHeadBlocksQueue},
+		{"Outbox_DeadEventUnblocksQueue", testOutboxDeadEventUnblocksQueue},
+		{"Outbox_DeleteDeliveredEvents", testOutboxDeleteDeliveredEvents},
+		{"Outbox_WebhookFanoutIgnoresHead", testOutboxWebhookFanoutIgnoresHead},
+		{"WalletEvents_SnapshotAndList", testWalletEventsSnapshotAndList},
+		{"Webhook_CreateGetDelete", testWebhookCreateGetDelete},
+		{"Webhook_EnqueueFilters", testWebhookEnqueueFilters},
+		{"Webhook_RecordAttempt", testWebhookRecordAttempt},
+		{"Webhook_ClaimLeasesDelivery", testWebhookClaimLeasesDelivery},
+		{"IdempotencyKey", testIdempotencyKey},
+		{"DeleteExpiredIdempotencyKeys", testDeleteExpiredIdempotencyKeys},
+		{"Rollback", testRollback},
+		{"Rollback_Usecase", testRollbackUsecase},
+		{"NoDirtyReads", testNoDirtyReads},
+		{"NestedTx", testNestedTx},
+		{"LockBlocksWriters", testLockBlocksWriters},
+		{"ConcurrentDeposit", testConcurrentDeposit},
+		{"ConcurrentWithdraw", testConcurrentWithdraw},
+		{"ConcurrentApplyOperation", testConcurrentApplyOperation},
+		{"ConcurrentUsecaseMixed", testConcurrentUsecaseMixed},
+		{"ConcurrentTransfers", testConcurrentTransfers},
+		{"ConcurrentGroupCommit", testConcurrentGroupCommit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newBackend(t))
+		})
+	}
+}
+
+// createWallet создаёт кошелёк с балансом, открытым журнальной записью
+// против счёта корректировок.
+func createWallet(t testing.TB, b Backend, balance int64) uuid.UUID {
+	t.Helper()
+
+	ctx := context.Background()
+	id := uuid.New()
+	require.NoError(t, b.Repo.CreateWallet(ctx, id))
+	if b.DeleteWallet != nil {
+		t.Cleanup(func() { b.DeleteWallet(id) })
+	}
+
+	if balance != 0 {
+		err := b.Repo.PostEntry(ctx, model.JournalEntry{
+			ID:          uuid.New(),
+			Description: "OPENING_BALANCE",
+			Postings: []model.Posting{
+				{AccountID: id, Amount: balance},
+				{AccountID: model.AdjustmentsAccountID, Amount: -balance},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	return id
+}
+
+// withdrawEntry журнальная запись списания amount с кошелька.
+func withdrawEntry(id, walletID uuid.UUID, amount int64) model.JournalEntry {
+	return model.JournalEntry{
+		ID:          id,
+		Description: "WITHDRAW",
+		Postings: []model.Posting{
+			{AccountID: walletID, Amount: -amount},
+			{AccountID: model.ExternalFundingAccountID, Amount: amount},
+		},
+	}
+}
+
+// requireLedgerMatches проверяет, что кэш баланса кошелька совпадает со
+// счётом главной книги.
+func requireLedgerMatches(t testing.TB, b Backend, walletID uuid.UUID) {
+	t.Helper()
+
+	if b.AccountBalance == nil {
+		return
+	}
+
+	balance, err := b.Repo.GetBalance(context.Background(), walletID)
+	require.NoError(t, err)
+	require.Equal(t, balance, b.AccountBalance(t, walletID), "wallet balance differs from ledger account")
+}
@@ -0,0 +1,627 @@
+package repositorytest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errRollback = errors.New("rollback")
+
+func testBalance(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	empty := createWallet(t, b, 0)
+	balance, err := b.Repo.GetBalance(ctx, empty)
+	require.NoError(t, err)
+	assert.Zero(t, balance)
+
+	walletID := createWallet(t, b, 1000)
+	balance, err = b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), balance)
+
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		balance, err := b.Repo.GetBalanceForUpdate(ctx, walletID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1000), balance)
+		return b.Repo.UpdateBalance(ctx, walletID, 750)
+	})
+	require.NoError(t, err)
+
+	wb, err := b.Repo.GetWalletBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, model.WalletBalance{Balance: 750, AvailableBalance: 750}, wb)
+}
+
+func testNotFound(t *testing.T, b Backend) {
+	ctx := context.Background()
+	missing := uuid.New()
+
+	_, err := b.Repo.GetBalance(ctx, missing)
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+
+	_, err = b.Repo.GetWalletBalance(ctx, missing)
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := b.Repo.GetBalanceForUpdate(ctx, missing)
+		return err
+	})
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+
+	_, err = b.Repo.GetOperation(ctx, uuid.New())
+	assert.ErrorIs(t, err, walleterror.ErrOperationNotFound)
+
+	_, err = b.Repo.GetHoldForUpdate(ctx, uuid.New())
+	assert.ErrorIs(t, err, walleterror.ErrHoldNotFound)
+}
+
+func testCreateWalletAlreadyExists(t *testing.T, b Backend) {
+	walletID := createWallet(t, b, 0)
+
+	err := b.Repo.CreateWallet(context.Background(), walletID)
+
+	assert.ErrorIs(t, err, walleterror.ErrWalletAlreadyExists)
+}
+
+func testUpdateBalanceNegative(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		return b.Repo.UpdateBalance(ctx, walletID, -1)
+	})
+	require.Error(t, err)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance)
+}
+
+func testOperationRoundTrip(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	saved := usecase.Operation{
+		ID:         uuid.New(),
+		WalletID:   walletID,
+		Type:       "TRANSFER_IN",
+		Amount:     300,
+		TransferID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+
+		BalanceBefore: 0,
+		BalanceAfter:  300,
+	}
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		saved.CreatedAt, err = b.Repo.SaveOperation(ctx, saved)
+		return err
+	})
+	require.NoError(t, err)
+	assert.False(t, saved.CreatedAt.IsZero())
+
+	op, err := b.Repo.GetOperation(ctx, saved.ID)
+	require.NoError(t, err)
+	assert.Positive(t, op.Seq)
+	saved.Seq = op.Seq
+	assert.Equal(t, saved, op)
+}
+
+func testSaveOperationInvalidType(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		_, err := b.Repo.SaveOperation(ctx, usecase.Operation{
+			ID:       uuid.New(),
+			WalletID: walletID,
+			Type:     "INVALID",
+			Amount:   100,
+		})
+		return err
+	})
+
+	assert.Error(t, err)
+}
+
+func testListOperationsPagination(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	for _, typ := range []string{"DEPOSIT", "WITHDRAW", "DEPOSIT"} {
+		err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+			_, err := b.Repo.SaveOperation(ctx, usecase.Operation{
+				ID:       uuid.New(),
+				WalletID: walletID,
+				Type:     typ,
+				Amount:   100,
+			})
+			return err
+		})
+		require.NoError(t, err)
+	}
+
+	first, err := b.Repo.ListOperations(ctx, model.OperationFilter{WalletID: walletID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.False(t, first[0].CreatedAt.Before(first[1].CreatedAt), "operations must be newest first")
+
+	last := first[1]
+	rest, err := b.Repo.ListOperations(ctx, model.OperationFilter{
+		WalletID: walletID,
+		After:    &model.OperationCursor{CreatedAt: last.CreatedAt, Seq: last.Seq},
+		Limit:    2,
+	})
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.NotContains(t, []uuid.UUID{first[0].ID, first[1].ID}, rest[0].ID)
+
+	deposits, err := b.Repo.ListOperations(ctx, model.OperationFilter{
+		WalletID: walletID,
+		Types:    []string{"DEPOSIT"},
+		Limit:    10,
+	})
+	require.NoError(t, err)
+	assert.Len(t, deposits, 2)
+}
+
+// testListOperationsCursorBoundary операции одной транзакции (в Postgres с
+// общим created_at) упорядочены по seq, а курсор (created_at, seq) отдаёт
+// строго более старые операции.
+func testListOperationsCursorBoundary(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	saved := make([]uuid.UUID, 3)
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		for i := range saved {
+			saved[i] = uuid.New()
+			_, err := b.Repo.SaveOperation(ctx, usecase.Operation{ID: saved[i], WalletID: walletID, Type: "DEPOSIT", Amount: 1})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	list := func(after *model.OperationCursor) []uuid.UUID {
+		ops, err := b.Repo.ListOperations(ctx, model.OperationFilter{WalletID: walletID, After: after, Limit: 10})
+		require.NoError(t, err)
+		ids := make([]uuid.UUID, len(ops))
+		for i, op := range ops {
+			ids[i] = op.ID
+		}
+		return ids
+	}
+
+	newest, err := b.Repo.GetOperation(ctx, saved[2])
+	require.NoError(t, err)
+	oldest, err := b.Repo.GetOperation(ctx, saved[0])
+	require.NoError(t, err)
+
+	assert.Equal(t, []uuid.UUID{saved[2], saved[1], saved[0]}, list(nil))
+	assert.Equal(t, []uuid.UUID{saved[1], saved[0]}, list(&model.OperationCursor{CreatedAt: newest.CreatedAt, Seq: newest.Seq}))
+	assert.Equal(t, []uuid.UUID{saved[2], saved[1], saved[0]}, list(&model.OperationCursor{CreatedAt: newest.CreatedAt, Seq: newest.Seq + 1}))
+	assert.Empty(t, list(&model.OperationCursor{CreatedAt: oldest.CreatedAt, Seq: oldest.Seq}))
+}
+
+// testListOperationsAtomicBatchOrder операции атомарного пакета пишутся в
+// одной транзакции, и история должна отдавать их в порядке применения,
+// даже если у них общий created_at.
+func testListOperationsAtomicBatchOrder(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	ops := make([]model.BatchOperation, 0, 10)
+	for i := range 10 {
+		typ := "DEPOSIT"
+		if i%2 == 1 {
+			typ = "WITHDRAW"
+		}
+		ops = append(ops, model.BatchOperation{WalletID: walletID, Type: typ, Amount: int64(10 - i)})
+	}
+
+	results, err := usecase.New(b.Repo, b.TxManager).ExecuteBatch(ctx, model.BatchInput{Operations: ops, Atomic: true})
+	require.NoError(t, err)
+
+	applied := make([]uuid.UUID, len(results))
+	for i, res := range results {
+		require.NoError(t, res.Err)
+		applied[i] = res.Receipt.Operation.ID
+	}
+
+	requireHistory(t, b, walletID, applied)
+}
+
+// testListOperationsGroupCommitOrder group commit проводит попутные
+// пополнения одной транзакцией. Порядок применения восстанавливается по
+// balance_after: суммы положительные, баланс только растёт.
+func testListOperationsGroupCommitOrder(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 0)
+
+	g := usecase.NewGroupCommit(usecase.New(b.Repo, b.TxManager),
+		usecase.WithGroupCommitWindow(50*time.Millisecond),
+	)
+
+	const n = 10
+	receipts := make([]model.Receipt, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			var err error
+			receipts[i], err = g.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: int64(i + 1)})
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	slices.SortFunc(receipts, func(x, y model.Receipt) int {
+		return cmp.Compare(x.BalanceAfter, y.BalanceAfter)
+	})
+	applied := make([]uuid.UUID, n)
+	for i, r := range receipts {
+		applied[i] = r.Operation.ID
+	}
+
+	requireHistory(t, b, walletID, applied)
+}
+
+// requireHistory листает историю кошелька страницами по две операции и
+// проверяет, что она совпадает с applied в обратном порядке, а balance_after
+// каждой операции равен balance_before следующей.
+func requireHistory(t *testing.T, b Backend, walletID uuid.UUID, applied []uuid.UUID) {
+	t.Helper()
+
+	var history []usecase.Operation
+	filter := model.OperationFilter{WalletID: walletID, Limit: 2}
+	for {
+		page, err := b.Repo.ListOperations(context.Background(), filter)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		history = append(history, page...)
+		last := page[len(page)-1]
+		filter.After = &model.OperationCursor{CreatedAt: last.CreatedAt, Seq: last.Seq}
+	}
+
+	got := make([]uuid.UUID, len(history))
+	for i, op := range history {
+		got[len(history)-1-i] = op.ID
+		if i > 0 {
+			assert.Equal(t, op.BalanceAfter, history[i-1].BalanceBefore, "balance chain is broken at %s", op.ID)
+		}
+	}
+	assert.Equal(t, applied, got)
+}
+
+func testPostEntryRejected(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 10)
+
+	err := b.Repo.PostEntry(ctx, model.JournalEntry{
+		ID:          uuid.New(),
+		Description: "DEPOSIT",
+		Postings: []model.Posting{
+			{AccountID: walletID, Amount: 40},
+			{AccountID: model.ExternalFundingAccountID, Amount: -30},
+		},
+	})
+	require.ErrorIs(t, err, walleterror.ErrUnbalancedEntry)
+
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		return b.Repo.PostEntry(ctx, withdrawEntry(uuid.New(), walletID, 11))
+	})
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), balance)
+	requireLedgerMatches(t, b, walletID)
+}
+
+func testApplyOperation(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	var fundingBefore int64
+	if b.AccountBalance != nil {
+		fundingBefore = b.AccountBalance(t, model.ExternalFundingAccountID)
+	}
+
+	var op usecase.Operation
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		op = usecase.Operation{ID: uuid.New(), WalletID: walletID, Type: "WITHDRAW", Amount: 30}
+		op, err = b.Repo.ApplyOperation(ctx, op, withdrawEntry(op.ID, walletID, 30))
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), op.BalanceBefore)
+	assert.Equal(t, int64(70), op.BalanceAfter)
+	assert.False(t, op.CreatedAt.IsZero())
+
+	saved, err := b.Repo.GetOperation(ctx, op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, op, saved)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(70), balance)
+
+	requireLedgerMatches(t, b, walletID)
+	if b.AccountBalance != nil {
+		assert.Equal(t, fundingBefore+30, b.AccountBalance(t, model.ExternalFundingAccountID))
+	}
+}
+
+func testApplyOperationRejected(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	opID := uuid.New()
+	_, err := b.Repo.ApplyOperation(ctx,
+		usecase.Operation{ID: opID, WalletID: walletID, Type: "WITHDRAW", Amount: 101},
+		withdrawEntry(opID, walletID, 101),
+	)
+	assert.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+
+	// Отклонённая операция ничего не пишет.
+	_, err = b.Repo.GetOperation(ctx, opID)
+	assert.ErrorIs(t, err, walleterror.ErrOperationNotFound)
+
+	missing := uuid.New()
+	_, err = b.Repo.ApplyOperation(ctx,
+		usecase.Operation{ID: uuid.New(), WalletID: missing, Type: "WITHDRAW", Amount: 1},
+		withdrawEntry(uuid.New(), missing, 1),
+	)
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance)
+	requireLedgerMatches(t, b, walletID)
+}
+
+func testIdempotencyKey(t *testing.T, b Backend) {
+	ctx := context.Background()
+	key := uuid.NewString()
+
+	// Ключ из откаченной транзакции свободен.
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		_, replayed, err := b.Repo.ClaimIdempotencyKey(ctx, key, "fp", time.Hour)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		_, replayed, err := b.Repo.ClaimIdempotencyKey(ctx, key, "fp", time.Hour)
+		require.NoError(t, err)
+		assert.False(t, replayed)
+		return b.Repo.SaveIdempotencyResponse(ctx, key, []byte(`{"ok":true}`))
+	})
+	require.NoError(t, err)
+
+	rec, replayed, err := b.Repo.ClaimIdempotencyKey(ctx, key, "other", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, "fp", rec.Fingerprint)
+	assert.JSONEq(t, `{"ok":true}`, string(rec.Response))
+}
+
+func testDeleteExpiredIdempotencyKeys(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	// Отрицательный TTL сразу делает ключ просроченным.
+	for range 3 {
+		_, _, err := b.Repo.ClaimIdempotencyKey(ctx, uuid.NewString(), "fp", -time.Minute)
+		require.NoError(t, err)
+	}
+	live := uuid.NewString()
+	_, _, err := b.Repo.ClaimIdempotencyKey(ctx, live, "fp", time.Hour)
+	require.NoError(t, err)
+
+	n, err := b.Repo.DeleteExpiredIdempotencyKeys(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// Хранилище может быть общим с другими тестами: дочищаем до конца.
+	for range 100 {
+		n, err = b.Repo.DeleteExpiredIdempotencyKeys(ctx, 1000)
+		require.NoError(t, err)
+		if n == 0 {
+			break
+		}
+	}
+	assert.Zero(t, n)
+
+	rec, replayed, err := b.Repo.ClaimIdempotencyKey(ctx, live, "other", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, replayed, "live key must survive cleanup")
+	assert.Equal(t, "fp", rec.Fingerprint)
+}
+
+func testRollback(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, b.Repo.UpdateBalance(ctx, walletID, 9999))
+		require.NoError(t, b.Repo.PostEntry(ctx, withdrawEntry(uuid.New(), walletID, 50)))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance)
+	requireLedgerMatches(t, b, walletID)
+}
+
+func testRollbackUsecase(t *testing.T, b Backend) {
+	uc := usecase.New(b.Repo, b.TxManager)
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	var opID uuid.UUID
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		receipt, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 50})
+		require.NoError(t, err)
+		opID = receipt.Operation.ID
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance)
+	requireLedgerMatches(t, b, walletID)
+
+	_, err = b.Repo.GetOperation(ctx, opID)
+	assert.ErrorIs(t, err, walleterror.ErrOperationNotFound)
+}
+
+// testNoDirtyReads изменения открытой транзакции не видны снаружи до
+// коммита, а после коммита видны целиком.
+func testNoDirtyReads(t *testing.T, b Backend) {
+	uc := usecase.New(b.Repo, b.TxManager)
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	var opID uuid.UUID
+	written := make(chan struct{})
+	commit := make(chan struct{})
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+			receipt, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 50})
+			if err != nil {
+				return err
+			}
+			opID = receipt.Operation.ID
+			close(written)
+			<-commit
+			return nil
+		})
+	}()
+
+	select {
+	case <-written:
+	case err := <-errCh:
+		require.FailNow(t, "transaction finished early", "%v", err)
+	}
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), balance)
+	requireLedgerMatches(t, b, walletID)
+
+	_, err = b.Repo.GetOperation(ctx, opID)
+	assert.ErrorIs(t, err, walleterror.ErrOperationNotFound)
+	ops, err := b.Repo.ListOperations(ctx, model.OperationFilter{WalletID: walletID, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, ops)
+
+	close(commit)
+	require.NoError(t, <-errCh)
+
+	balance, err = b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(150), balance)
+	requireLedgerMatches(t, b, walletID)
+
+	op, err := b.Repo.GetOperation(ctx, opID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(150), op.BalanceAfter)
+}
+
+func testNestedTx(t *testing.T, b Backend) {
+	uc := usecase.New(b.Repo, b.TxManager)
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	err := b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		if _, err := uc.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 50}); err != nil {
+			return err
+		}
+
+		// Неудачное списание откатывается до savepoint и не трогает депозит.
+		_, err := uc.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 1000})
+		require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+
+		_, err = uc.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 30})
+		return err
+	})
+	require.NoError(t, err)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(120), balance)
+	requireLedgerMatches(t, b, walletID)
+}
+
+// testLockBlocksWriters: пока транзакция держит кошелёк после
+// GetBalanceForUpdate, другая транзакция не может его изменить и видит
+// результат первой после её коммита.
+func testLockBlocksWriters(t *testing.T, b Backend) {
+	ctx := context.Background()
+	walletID := createWallet(t, b, 100)
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+			balance, err := b.Repo.GetBalanceForUpdate(ctx, walletID)
+			if err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return b.Repo.UpdateBalance(ctx, walletID, balance+10)
+		})
+	}()
+	<-locked
+
+	second := make(chan error, 1)
+	go func() {
+		second <- b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+			balance, err := b.Repo.GetBalanceForUpdate(ctx, walletID)
+			if err != nil {
+				return err
+			}
+			return b.Repo.UpdateBalance(ctx, walletID, balance+1)
+		})
+	}()
+
+	select {
+	case err := <-second:
+		t.Fatalf("second transaction finished while the wallet was locked: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-first)
+	require.NoError(t, <-second)
+
+	balance, err := b.Repo.GetBalance(ctx, walletID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(111), balance)
+}
@@ -0,0 +1,171 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createWebhook(t *testing.T, b Backend, hook model.Webhook) model.Webhook {
+	t.Helper()
+
+	hook.ID = uuid.New()
+	hook.URL = "https://partner.example/hook"
+	hook.Secret = "secret"
+
+	created, err := b.Repo.CreateWebhook(context.Background(), hook)
+	require.NoError(t, err)
+
+	// Доставки и попытки удаляются вместе с подпиской.
+	t.Cleanup(func() { _ = b.Repo.DeleteWebhook(context.Background(), hook.ID) })
+
+	return created
+}
+
+// hookDeliveries доставки подписки hookID из пачки: в очереди общего
+// хранилища могут быть доставки других тестов.
+func hookDeliveries(deliveries []model.WebhookDelivery, hookID uuid.UUID) []model.WebhookDelivery {
+	var out []model.WebhookDelivery
+	for _, d := range deliveries {
+		if d.WebhookID == hookID {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func testWebhookCreateGetDelete(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	walletID := uuid.New()
+	hook := createWebhook(t, b, model.Webhook{
+		EventTypes: []string{"DEPOSIT"},
+		WalletIDs:  []uuid.UUID{walletID},
+	})
+	assert.False(t, hook.CreatedAt.IsZero())
+
+	got, err := b.Repo.GetWebhook(ctx, hook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEPOSIT"}, got.EventTypes)
+	assert.Equal(t, []uuid.UUID{walletID}, got.WalletIDs)
+	assert.Equal(t, "secret", got.Secret)
+
+	hooks, err := b.Repo.ListWebhooks(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, hooks, got)
+
+	require.NoError(t, b.Repo.DeleteWebhook(ctx, hook.ID))
+	assert.ErrorIs(t, b.Repo.DeleteWebhook(ctx, hook.ID), walleterror.ErrWebhookNotFound)
+
+	_, err = b.Repo.GetWebhook(ctx, hook.ID)
+	assert.ErrorIs(t, err, walleterror.ErrWebhookNotFound)
+}
+
+func testWebhookEnqueueFilters(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	walletID := uuid.New()
+	hook := createWebhook(t, b, model.Webhook{
+		EventTypes: []string{"DEPOSIT"},
+		WalletIDs:  []uuid.UUID{walletID},
+	})
+
+	events := []model.Event{
+		{ID: uuid.New(), Type: "DEPOSIT", WalletID: walletID},
+		{ID: uuid.New(), Type: "WITHDRAW", WalletID: walletID},
+		{ID: uuid.New(), Type: "DEPOSIT", WalletID: uuid.New()},
+	}
+	for _, e := range events {
+		_, err := b.Repo.EnqueueWebhookDeliveries(ctx, e, []byte(`{}`))
+		require.NoError(t, err)
+	}
+	// Повторная публикация того же события не дублирует доставку.
+	_, err := b.Repo.EnqueueWebhookDeliveries(ctx, events[0], []byte(`{}`))
+	require.NoError(t, err)
+
+	deliveries, err := b.Repo.ClaimDueWebhookDeliveries(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+
+	deliveries = hookDeliveries(deliveries, hook.ID)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, events[0].ID, deliveries[0].EventID)
+}
+
+func testWebhookRecordAttempt(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	hook := createWebhook(t, b, model.Webhook{})
+	event := model.Event{ID: uuid.New(), Type: "WITHDRAW", WalletID: uuid.New()}
+	_, err := b.Repo.EnqueueWebhookDeliveries(ctx, event, []byte(`{"a":1}`))
+	require.NoError(t, err)
+
+	var deliveries []model.WebhookDelivery
+	err = b.TxManager.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		deliveries, err = b.Repo.ClaimDueWebhookDeliveries(ctx, 1000, time.Minute)
+		return err
+	})
+	require.NoError(t, err)
+
+	deliveries = hookDeliveries(deliveries, hook.ID)
+	require.Len(t, deliveries, 1)
+	d := deliveries[0]
+	assert.Equal(t, event.ID, d.EventID)
+	assert.Equal(t, hook.URL, d.URL)
+	assert.JSONEq(t, `{"a":1}`, string(d.Payload))
+
+	// Попытка записывается уже вне транзакции захвата.
+	err = b.Repo.RecordWebhookAttempt(ctx, model.WebhookAttempt{
+		DeliveryID: d.ID,
+		Attempt:    1,
+		StatusCode: 502,
+		Error:      "unexpected status 502",
+		Duration:   20 * time.Millisecond,
+		Status:     model.WebhookDeliveryPending,
+	}, time.Hour)
+	require.NoError(t, err)
+
+	// Отложенная доставка больше не считается готовой к отправке.
+	deliveries, err = b.Repo.ClaimDueWebhookDeliveries(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, hookDeliveries(deliveries, hook.ID))
+
+	attempts, err := b.Repo.ListWebhookAttempts(ctx, hook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	assert.Equal(t, event.ID, attempts[0].EventID)
+	assert.Equal(t, "WITHDRAW", attempts[0].EventType)
+	assert.Equal(t, 502, attempts[0].StatusCode)
+	assert.Equal(t, 20*time.Millisecond, attempts[0].Duration)
+	assert.Equal(t, model.WebhookDeliveryPending, attempts[0].Status)
+}
+
+func testWebhookClaimLeasesDelivery(t *testing.T, b Backend) {
+	ctx := context.Background()
+
+	hook := createWebhook(t, b, model.Webhook{})
+	event := model.Event{ID: uuid.New(), Type: "DEPOSIT", WalletID: uuid.New()}
+	_, err := b.Repo.EnqueueWebhookDeliveries(ctx, event, []byte(`{}`))
+	require.NoError(t, err)
+
+	deliveries, err := b.Repo.ClaimDueWebhookDeliveries(ctx, 1000, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Len(t, hookDeliveries(deliveries, hook.ID), 1)
+
+	// Пока аренда не истекла, другой воркер доставку не получает.
+	deliveries, err = b.Repo.ClaimDueWebhookDeliveries(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, hookDeliveries(deliveries, hook.ID))
+
+	time.Sleep(100 * time.Millisecond)
+
+	deliveries, err = b.Repo.ClaimDueWebhookDeliveries(ctx, 1000, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, hookDeliveries(deliveries, hook.ID), 1)
+}
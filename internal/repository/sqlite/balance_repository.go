@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+)
+
+// ApplyOperation меняет баланс кошелька op.WalletID на сумму его проводок из
+// entry, записывает операцию с балансами до и после и журнальную запись.
+// Условие balance + delta >= 0 проверяется в самом UPDATE, без
+// промежуточного чтения. Возвращает op с заполненными BalanceBefore,
+// BalanceAfter и CreatedAt.
+func (r *WalletRepository) ApplyOperation(ctx context.Context, op usecase.Operation, entry model.JournalEntry) (usecase.Operation, error) {
+	if !entry.Balanced() {
+		return usecase.Operation{}, walleterror.ErrUnbalancedEntry
+	}
+
+	var delta int64
+	for _, p := range entry.Postings {
+		if p.AccountID == op.WalletID {
+			delta += p.Amount
+		}
+	}
+
+	err := r.inTx(ctx, func(ctx context.Context) error {
+		op.CreatedAt = now()
+		t := formatTime(op.CreatedAt)
+
+		query := `
+			UPDATE wallets
+			SET balance = balance + ?1, updated_at = ?2
+			WHERE id = ?3 AND balance + ?1 >= 0
+			RETURNING balance
+		`
+
+		err := r.q(ctx).QueryRowContext(ctx, query, delta, t, op.WalletID).Scan(&op.BalanceAfter)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Строка не обновилась: кошелька нет или не хватает средств.
+			if _, err := r.GetBalance(ctx, op.WalletID); err != nil {
+				return err
+			}
+			return walleterror.ErrInsufficientFunds
+		}
+		if err != nil {
+			return fmt.Errorf("apply operation: %w", err)
+		}
+		op.BalanceBefore = op.BalanceAfter - delta
+
+		if err := r.insertOperation(ctx, &op); err != nil {
+			return fmt.Errorf("apply operation: %w", err)
+		}
+		if _, err := r.insertEntry(ctx, entry, t); err != nil {
+			return fmt.Errorf("apply operation: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return usecase.Operation{}, err
+	}
+
+	return op, nil
+}
@@ -0,0 +1,31 @@
+package sqlite_test
+
+import (
+	"testing"
+	"wallet/internal/repository/repositorytest"
+	"wallet/internal/repository/sqlite"
+
+	"github.com/google/uuid"
+)
+
+func newBackend(t testing.TB) repositorytest.Backend {
+	db, store := setupDB(t)
+	return repositorytest.Backend{
+		Repo:      sqlite.New(store),
+		TxManager: store,
+		AccountBalance: func(t testing.TB, accountID uuid.UUID) int64 {
+			return accountBalance(t, db, accountID)
+		},
+	}
+}
+
+func TestRepository_Contract(t *testing.T) {
+	repositorytest.Run(t, newBackend)
+}
+
+// BenchmarkHotWallet ...
+//
+//	go test ./internal/repository/sqlite -run '^$' -bench HotWallet -cpu 1 -benchtime 2000x
+func BenchmarkHotWallet(b *testing.B) {
+	repositorytest.BenchHotWallet(b, newBackend)
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// holdColumns статус ACTIVE с истёкшим сроком отдаётся как EXPIRED. Текущее
+// время передаётся параметром ?1.
+const holdColumns = `
+	id, wallet_id, amount,
+	CASE WHEN status = 'ACTIVE' AND expires_at <= ?1 THEN 'EXPIRED' ELSE status END,
+	captured_amount, operation_id, expires_at, created_at
+`
+
+// CreateHold ...
+func (r *WalletRepository) CreateHold(ctx context.Context, hold model.Hold, ttl time.Duration) (model.Hold, error) {
+	query := `
+		INSERT INTO holds (id, wallet_id, amount, expires_at, created_at, updated_at)
+		VALUES (?2, ?3, ?4, ?5, ?1, ?1)
+		RETURNING ` + holdColumns
+
+	t := now()
+	created, err := scanHold(r.q(ctx).QueryRowContext(ctx, query,
+		formatTime(t), hold.ID, hold.WalletID, hold.Amount, formatTime(t.Add(ttl)),
+	))
+	if err != nil {
+		return model.Hold{}, fmt.Errorf("create hold: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetHoldForUpdate ...
+func (r *WalletRepository) GetHoldForUpdate(ctx context.Context, holdID uuid.UUID) (model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = ?2`
+
+	hold, err := scanHold(r.q(ctx).QueryRowContext(ctx, query, formatTime(now()), holdID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Hold{}, walleterror.ErrHoldNotFound
+		}
+		return model.Hold{}, fmt.Errorf("get hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// UpdateHold сохраняет статус, списанную сумму и операцию списания.
+func (r *WalletRepository) UpdateHold(ctx context.Context, hold model.Hold) error {
+	query := `
+		UPDATE holds
+		SET status = ?2, captured_amount = ?3, operation_id = ?4, updated_at = ?5
+		WHERE id = ?1
+	`
+
+	_, err := r.q(ctx).ExecContext(ctx, query,
+		hold.ID, string(hold.Status), hold.CapturedAmount, hold.OperationID, formatTime(now()),
+	)
+	if err != nil {
+		return fmt.Errorf("update hold: %w", err)
+	}
+
+	return nil
+}
+
+// GetHeldAmount сумма активных непросроченных холдов кошелька.
+func (r *WalletRepository) GetHeldAmount(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM holds
+		WHERE wallet_id = ?1 AND status = 'ACTIVE' AND expires_at > ?2
+	`
+
+	var held int64
+	if err := r.q(ctx).QueryRowContext(ctx, query, walletID, formatTime(now())).Scan(&held); err != nil {
+		return 0, fmt.Errorf("get held amount: %w", err)
+	}
+
+	return held, nil
+}
+
+// GetWalletBalance ...
+func (r *WalletRepository) GetWalletBalance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error) {
+	query := `
+		SELECT w.balance, w.balance - COALESCE((
+			SELECT SUM(h.amount)
+			FROM holds h
+			WHERE h.wallet_id = w.id AND h.status = 'ACTIVE' AND h.expires_at > ?2
+		), 0)
+		FROM wallets w
+		WHERE w.id = ?1
+	`
+
+	var b model.WalletBalance
+	err := r.q(ctx).QueryRowContext(ctx, query, walletID, formatTime(now())).Scan(&b.Balance, &b.AvailableBalance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WalletBalance{}, walleterror.ErrWalletNotFound
+		}
+		return model.WalletBalance{}, fmt.Errorf("get wallet balance: %w", err)
+	}
+
+	return b, nil
+}
+
+func scanHold(row scanner) (model.Hold, error) {
+	var (
+		h      model.Hold
+		status string
+	)
+	err := row.Scan(&h.ID, &h.WalletID, &h.Amount, &status,
+		&h.CapturedAmount, &h.OperationID, timeValue{&h.ExpiresAt}, timeValue{&h.CreatedAt})
+	if err != nil {
+		return model.Hold{}, err
+	}
+	h.Status = model.HoldStatus(status)
+
+	return h, nil
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// PostEntry записывает журнальную запись с проводками и применяет их к
+// кэшированным балансам кошельков: ledger_accounts.balance и
+// wallets.balance. Балансы системных счетов, как и в Postgres, не ведутся. Несбалансированная запись
+// отклоняется до обращения к базе.
+func (r *WalletRepository) PostEntry(ctx context.Context, entry model.JournalEntry) error {
+	if !entry.Balanced() {
+		return walleterror.ErrUnbalancedEntry
+	}
+
+	return r.inTx(ctx, func(ctx context.Context) error {
+		t := formatTime(now())
+
+		delta, err := r.insertEntry(ctx, entry, t)
+		if err != nil {
+			return fmt.Errorf("post journal entry: %w", err)
+		}
+
+		for _, accountID := range delta.accounts {
+			_, err := r.q(ctx).ExecContext(ctx,
+				`UPDATE wallets SET balance = balance + ?1, updated_at = ?2 WHERE id = ?3`,
+				delta.amounts[accountID], t, accountID,
+			)
+			if err != nil {
+				if isConstraint(err, sqlite3.SQLITE_CONSTRAINT_CHECK) {
+					return walleterror.ErrInsufficientFunds
+				}
+				return fmt.Errorf("post journal entry: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// entryDelta изменение баланса по каждому счёту записи. accounts хранит
+// порядок первого упоминания, чтобы запросы шли в порядке проводок.
+type entryDelta struct {
+	accounts []uuid.UUID
+	amounts  map[uuid.UUID]int64
+}
+
+// insertEntry вставляет запись и проводки и применяет их к
+// ledger_accounts.balance счетов кошельков. Вызывается внутри inTx.
+func (r *WalletRepository) insertEntry(ctx context.Context, entry model.JournalEntry, createdAt string) (entryDelta, error) {
+	_, err := r.q(ctx).ExecContext(ctx,
+		`INSERT INTO journal_entries (id, description, created_at) VALUES (?1, ?2, ?3)`,
+		entry.ID, entry.Description, createdAt,
+	)
+	if err != nil {
+		return entryDelta{}, err
+	}
+
+	delta := entryDelta{amounts: make(map[uuid.UUID]int64, len(entry.Postings))}
+	for _, p := range entry.Postings {
+		_, err := r.q(ctx).ExecContext(ctx,
+			`INSERT INTO ledger_postings (entry_id, account_id, amount) VALUES (?1, ?2, ?3)`,
+			entry.ID, p.AccountID, p.Amount,
+		)
+		if err != nil {
+			return entryDelta{}, err
+		}
+
+		if _, ok := delta.amounts[p.AccountID]; !ok {
+			delta.accounts = append(delta.accounts, p.AccountID)
+		}
+		delta.amounts[p.AccountID] += p.Amount
+	}
+
+	for _, accountID := range delta.accounts {
+		_, err := r.q(ctx).ExecContext(ctx,
+			`UPDATE ledger_accounts SET balance = balance + ?1 WHERE id = ?2 AND kind = 'WALLET'`,
+			delta.amounts[accountID], accountID,
+		)
+		if err != nil {
+			return entryDelta{}, err
+		}
+	}
+
+	return delta, nil
+}
@@ -0,0 +1,281 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+	"wallet/internal/driver/sqlitestore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const eventColumns = `id, event_id, event_type, wallet_id, payload, attempts, created_at`
+
+// SaveEvent ...
+func (r *WalletRepository) SaveEvent(ctx context.Context, event model.Event) error {
+	query := `
+		INSERT INTO outbox (event_id, event_type, wallet_id, payload, next_attempt_at, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?5)
+	`
+
+	_, err := r.q(ctx).ExecContext(ctx, query,
+		event.ID, event.Type, event.WalletID, []byte(event.Payload), formatTime(now()),
+	)
+	if err != nil {
+		return fmt.Errorf("save event: %w", err)
+	}
+
+	return nil
+}
+
+// ClaimPendingEvents забирает в аренду на lease до limit недоставленных
+// событий в порядке записи. Пока первое из них ждёт повторной попытки или
+// арендовано другим диспетчером, не возвращает ничего: более поздние
+// события не должны обгонять его. События в dead letter пропускаются.
+// Отдельная блокировка строк не нужна: транзакция и так единственный
+// писатель.
+func (r *WalletRepository) ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error) {
+	query := `
+		UPDATE outbox
+		SET locked_until = ?3
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at IS NULL AND failed_at IS NULL
+			ORDER BY id
+			LIMIT ?1
+		)
+		  AND EXISTS (
+			SELECT 1 FROM outbox
+			WHERE id = (SELECT MIN(id) FROM outbox WHERE delivered_at IS NULL AND failed_at IS NULL)
+			  AND next_attempt_at <= ?2
+			  AND (locked_until IS NULL OR locked_until <= ?2)
+		  )
+		RETURNING ` + eventColumns + `
+	`
+
+	t := now()
+	rows, err := r.q(ctx).QueryContext(ctx, query, limit, formatTime(t), formatTime(t.Add(lease)))
+	if err != nil {
+		return nil, fmt.Errorf("claim pending events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.Event, 0, limit)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim pending events: %w", err)
+	}
+
+	// RETURNING не сохраняет порядок подзапроса.
+	slices.SortFunc(events, func(a, b model.Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	return events, nil
+}
+
+// MarkEventDelivered ...
+func (r *WalletRepository) MarkEventDelivered(ctx context.Context, sequence int64) error {
+	query := `UPDATE outbox SET delivered_at = ?2, last_error = NULL WHERE id = ?1`
+
+	if _, err := r.q(ctx).ExecContext(ctx, query, sequence, formatTime(now())); err != nil {
+		return fmt.Errorf("mark event delivered: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEventFailed увеличивает счётчик попыток, откладывает следующую на
+// retryIn и снимает аренду.
+func (r *WalletRepository) MarkEventFailed(ctx context.Context, sequence int64, retryIn time.Duration, reason string) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			last_error = ?2,
+			next_attempt_at = ?3,
+			locked_until = NULL
+		WHERE id = ?1
+	`
+
+	if _, err := r.q(ctx).ExecContext(ctx, query, sequence, reason, formatTime(now().Add(retryIn))); err != nil {
+		return fmt.Errorf("mark event failed: %w", err)
+	}
+
+	return nil
+}
+
+// MarkEventDead увеличивает счётчик попыток, отмечает событие failed_at и
+// снимает аренду.
+func (r *WalletRepository) MarkEventDead(ctx context.Context, sequence int64, reason string) error {
+	query := `
+		UPDATE outbox
+		SET attempts = attempts + 1,
+			last_error = ?2,
+			failed_at = ?3,
+			locked_until = NULL
+		WHERE id = ?1
+	`
+
+	if _, err := r.q(ctx).ExecContext(ctx, query, sequence, reason, formatTime(now())); err != nil {
+		return fmt.Errorf("mark event dead: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDeliveredEvents ...
+func (r *WalletRepository) DeleteDeliveredEvents(ctx context.Context, olderThan time.Duration, limit int) (int, error) {
+	query := `
+		DELETE FROM outbox
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE delivered_at <= ?1
+			  AND webhooks_enqueued_at IS NOT NULL
+			ORDER BY delivered_at
+			LIMIT ?2
+		)
+	`
+
+	res, err := r.q(ctx).ExecContext(ctx, query, formatTime(now().Add(-olderThan)), limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete delivered events: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete delivered events: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// ClaimWebhookEvents забирает до limit событий, ещё не разосланных
+// подпискам, в порядке записи и отмечает их webhooks_enqueued_at. Порядок
+// и доставка внешнему publisher на рассылку не влияют.
+func (r *WalletRepository) ClaimWebhookEvents(ctx context.Context, limit int) ([]model.Event, error) {
+	query := `
+		UPDATE outbox
+		SET webhooks_enqueued_at = ?2
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE webhooks_enqueued_at IS NULL
+			ORDER BY id
+			LIMIT ?1
+		)
+		RETURNING ` + eventColumns + `
+	`
+
+	rows, err := r.q(ctx).QueryContext(ctx, query, limit, formatTime(now()))
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.Event, 0, limit)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim webhook events: %w", err)
+	}
+
+	slices.SortFunc(events, func(a, b model.Event) int {
+		return cmp.Compare(a.Sequence, b.Sequence)
+	})
+
+	return events, nil
+}
+
+// NotifyWalletChanged будит подписчиков кошелька после коммита транзакции,
+// в которой вызван метод. Процесс с базой один, поэтому уведомление идёт
+// в Notifier напрямую.
+func (r *WalletRepository) NotifyWalletChanged(ctx context.Context, walletID uuid.UUID) error {
+	if r.notifier == nil {
+		return nil
+	}
+
+	sqlitestore.AfterCommit(ctx, func() { r.notifier.Notify(walletID) })
+
+	return nil
+}
+
+// ListWalletEvents события кошелька с номером больше afterSequence по порядку.
+func (r *WalletRepository) ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterSequence int64, limit int) ([]model.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM outbox
+		WHERE wallet_id = ?1 AND id > ?2
+		ORDER BY id
+		LIMIT ?3
+	`
+
+	rows, err := r.q(ctx).QueryContext(ctx, query, walletID, afterSequence, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list wallet events: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]model.Event, 0, limit)
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list wallet events: %w", err)
+	}
+
+	return events, nil
+}
+
+// GetWalletSnapshot ...
+func (r *WalletRepository) GetWalletSnapshot(ctx context.Context, walletID uuid.UUID) (model.WalletSnapshot, error) {
+	query := `
+		SELECT w.balance, w.balance - COALESCE((
+			SELECT SUM(h.amount)
+			FROM holds h
+			WHERE h.wallet_id = w.id AND h.status = 'ACTIVE' AND h.expires_at > ?2
+		), 0), COALESCE((
+			SELECT MAX(o.id) FROM outbox o WHERE o.wallet_id = w.id
+		), 0)
+		FROM wallets w
+		WHERE w.id = ?1
+	`
+
+	var s model.WalletSnapshot
+	err := r.q(ctx).QueryRowContext(ctx, query, walletID, formatTime(now())).Scan(&s.Balance, &s.AvailableBalance, &s.Sequence)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.WalletSnapshot{}, walleterror.ErrWalletNotFound
+		}
+		return model.WalletSnapshot{}, fmt.Errorf("get wallet snapshot: %w", err)
+	}
+
+	return s, nil
+}
+
+func scanEvent(row scanner) (model.Event, error) {
+	var e model.Event
+	var payload []byte
+	err := row.Scan(&e.Sequence, &e.ID, &e.Type, &e.WalletID, &payload, &e.Attempts, timeValue{&e.CreatedAt})
+	e.Payload = payload
+	return e, err
+}
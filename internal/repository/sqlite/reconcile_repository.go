@@ -0,0 +1,101 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// signedAmount сумма операции со знаком: списания уменьшают баланс, всё
+// остальное (включая ADJUSTMENT, который сам хранит знак) - увеличивает.
+const signedAmount = `CASE WHEN operation IN ('WITHDRAW', 'TRANSFER_OUT') THEN -amount ELSE amount END`
+
+// GetBalanceAt возвращает сумму операций кошелька с created_at <= at и
+// время создания кошелька.
+func (r *WalletRepository) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, time.Time, error) {
+	query := `
+		SELECT w.created_at, COALESCE((
+			SELECT SUM(` + signedAmount + `)
+			FROM wallet_operations
+			WHERE wallet_id = w.id AND created_at <= ?2
+		), 0)
+		FROM wallets w
+		WHERE w.id = ?1
+	`
+
+	var (
+		createdAt time.Time
+		balance   int64
+	)
+	err := r.q(ctx).QueryRowContext(ctx, query, walletID, formatTime(at)).Scan(timeValue{&createdAt}, &balance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, time.Time{}, walleterror.ErrWalletNotFound
+		}
+		return 0, time.Time{}, fmt.Errorf("get balance at: %w", err)
+	}
+
+	return balance, createdAt, nil
+}
+
+// StreamLedgerBalances ...
+func (r *WalletRepository) StreamLedgerBalances(ctx context.Context, fn func(model.LedgerBalance) error) error {
+	query := `
+		SELECT w.id, w.balance, COALESCE(l.sum, 0)
+		FROM wallets w
+		LEFT JOIN (
+			SELECT wallet_id, SUM(` + signedAmount + `) AS sum
+			FROM wallet_operations
+			GROUP BY wallet_id
+		) l ON l.wallet_id = w.id
+		ORDER BY w.id
+	`
+
+	rows, err := r.q(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("stream ledger balances: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b model.LedgerBalance
+		if err := rows.Scan(&b.WalletID, &b.Balance, &b.LedgerSum); err != nil {
+			return fmt.Errorf("scan ledger balance: %w", err)
+		}
+		if err := fn(b); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("stream ledger balances: %w", err)
+	}
+
+	return nil
+}
+
+// GetLedgerBalanceForUpdate ...
+func (r *WalletRepository) GetLedgerBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (model.LedgerBalance, error) {
+	balance, err := r.GetBalanceForUpdate(ctx, walletID)
+	if err != nil {
+		return model.LedgerBalance{}, err
+	}
+
+	query := `SELECT COALESCE(SUM(` + signedAmount + `), 0) FROM wallet_operations WHERE wallet_id = ?1`
+
+	var sum int64
+	if err := r.q(ctx).QueryRowContext(ctx, query, walletID).Scan(&sum); err != nil {
+		return model.LedgerBalance{}, fmt.Errorf("sum ledger: %w", err)
+	}
+
+	return model.LedgerBalance{
+		WalletID:  walletID,
+		Balance:   balance,
+		LedgerSum: sum,
+	}, nil
+}
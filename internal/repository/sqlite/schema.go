@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed schema/*.sql
+var schemaFS embed.FS
+
+// Migrate применяет к базе файлы schema/NNNN_*.sql, которых в ней ещё нет.
+// Номер последнего применённого файла хранится в PRAGMA user_version.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	files, err := fs.Glob(schemaFS, "schema/*.sql")
+	if err != nil {
+		return fmt.Errorf("list schema files: %w", err)
+	}
+	sort.Strings(files)
+
+	current, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range files {
+		version, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(name, "schema/"), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("parse schema version %s: %w", name, err)
+		}
+		if int64(version) <= current {
+			continue
+		}
+
+		script, err := schemaFS.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", name, err)
+		}
+
+		if err := applySchema(ctx, db, string(script), version); err != nil {
+			return fmt.Errorf("apply %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// SchemaVersion номер последнего применённого файла схемы.
+func SchemaVersion(ctx context.Context, db *sql.DB) (int64, error) {
+	var version int64
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, nil
+}
+
+// applySchema выполняет скрипт и поднимает user_version в одной транзакции.
+func applySchema(ctx context.Context, db *sql.DB, script string, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `PRAGMA user_version = `+strconv.Itoa(version)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,140 @@
+-- Схема SQLite повторяет итог миграций Postgres из migration/. UUID
+-- хранятся строками, время - строками фиксированной ширины в UTC
+-- (2006-01-02T15:04:05.000000Z), поэтому сравнивается лексикографически.
+
+CREATE TABLE wallets (
+    id TEXT PRIMARY KEY,
+    balance INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE wallet_operations (
+    id TEXT PRIMARY KEY,
+    wallet_id TEXT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    operation TEXT NOT NULL
+        CHECK (operation IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER_OUT', 'TRANSFER_IN', 'ADJUSTMENT')),
+    amount INTEGER NOT NULL
+        CHECK (amount > 0 OR (operation = 'ADJUSTMENT' AND amount <> 0)),
+    transfer_id TEXT,
+    balance_before INTEGER NOT NULL DEFAULT 0,
+    balance_after INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_wallet_operations_cursor
+    ON wallet_operations(wallet_id, created_at, id);
+
+CREATE INDEX idx_wallet_operations_transfer_id
+    ON wallet_operations(transfer_id);
+
+CREATE TABLE idempotency_keys (
+    key TEXT PRIMARY KEY,
+    fingerprint TEXT NOT NULL,
+    response BLOB,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+
+CREATE TABLE ledger_accounts (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL CHECK (kind IN ('WALLET', 'SYSTEM')),
+    name TEXT NOT NULL,
+    balance INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE journal_entries (
+    id TEXT PRIMARY KEY,
+    description TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE ledger_postings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id TEXT NOT NULL REFERENCES journal_entries(id),
+    account_id TEXT NOT NULL REFERENCES ledger_accounts(id),
+    amount INTEGER NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX idx_ledger_postings_account_id ON ledger_postings(account_id);
+
+CREATE TABLE holds (
+    id TEXT PRIMARY KEY,
+    wallet_id TEXT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    status TEXT NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'CAPTURED', 'VOIDED')),
+    captured_amount INTEGER NOT NULL DEFAULT 0,
+    operation_id TEXT,
+    expires_at TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_holds_wallet_active ON holds(wallet_id) WHERE status = 'ACTIVE';
+
+CREATE TABLE outbox (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id TEXT NOT NULL UNIQUE,
+    event_type TEXT NOT NULL,
+    wallet_id TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TEXT NOT NULL,
+    delivered_at TEXT,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE delivered_at IS NULL;
+CREATE INDEX idx_outbox_wallet_id ON outbox(wallet_id, id);
+
+-- event_types и wallet_ids - JSON-массивы, пустой массив означает «все».
+CREATE TABLE webhooks (
+    id TEXT PRIMARY KEY,
+    url TEXT NOT NULL,
+    event_types TEXT NOT NULL DEFAULT '[]',
+    wallet_ids TEXT NOT NULL DEFAULT '[]',
+    secret TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payload BLOB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    UNIQUE (webhook_id, event_id)
+);
+
+CREATE INDEX idx_webhook_deliveries_due
+    ON webhook_deliveries(next_attempt_at)
+    WHERE status = 'PENDING';
+
+CREATE TABLE webhook_attempts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    delivery_id INTEGER NOT NULL REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    status_code INTEGER,
+    error TEXT,
+    duration_ms INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE INDEX idx_webhook_attempts_webhook_id ON webhook_attempts(webhook_id, id DESC);
+
+-- Системные счета главной книги. Тестового кошелька в схеме нет: для
+-- bench.js его создаёт make seed через API.
+INSERT INTO ledger_accounts (id, kind, name, created_at) VALUES
+    ('00000000-0000-0000-0000-000000000001', 'SYSTEM', 'external funding', strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    ('00000000-0000-0000-0000-000000000002', 'SYSTEM', 'fees', strftime('%Y-%m-%dT%H:%M:%f000Z', 'now')),
+    ('00000000-0000-0000-0000-000000000003', 'SYSTEM', 'adjustments', strftime('%Y-%m-%dT%H:%M:%f000Z', 'now'));
@@ -0,0 +1,2 @@
+CREATE INDEX idx_idempotency_keys_expires_at
+    ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+-- Аренда событий диспетчером: публикация идёт вне транзакции захвата.
+ALTER TABLE outbox ADD COLUMN locked_until TEXT;
@@ -0,0 +1,11 @@
+-- seq задаёт порядок операций с одинаковым created_at. rowid растёт в
+-- порядке вставки, поэтому существующие операции нумеруются по нему.
+ALTER TABLE wallet_operations ADD COLUMN seq INTEGER NOT NULL DEFAULT 0;
+
+UPDATE wallet_operations SET seq = rowid;
+
+CREATE UNIQUE INDEX idx_wallet_operations_seq ON wallet_operations(seq);
+
+DROP INDEX idx_wallet_operations_cursor;
+CREATE INDEX idx_wallet_operations_cursor
+    ON wallet_operations(wallet_id, created_at, seq);
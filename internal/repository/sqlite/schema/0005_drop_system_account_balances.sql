@@ -0,0 +1,3 @@
+-- Балансы системных счетов не ведутся, как и в Postgres (миграция 0016):
+-- баланс системного счёта - сумма его проводок в ledger_postings.
+UPDATE ledger_accounts SET balance = 0 WHERE kind = 'SYSTEM';
@@ -0,0 +1,11 @@
+-- failed_at отмечает событие, исчерпавшее попытки, webhooks_enqueued_at -
+-- отдельный курсор рассылки подпискам вебхуков (миграция Postgres 0017).
+ALTER TABLE outbox ADD COLUMN failed_at TEXT;
+ALTER TABLE outbox ADD COLUMN webhooks_enqueued_at TEXT;
+
+UPDATE outbox SET webhooks_enqueued_at = delivered_at WHERE delivered_at IS NOT NULL;
+
+DROP INDEX idx_outbox_pending;
+CREATE INDEX idx_outbox_pending ON outbox(id) WHERE delivered_at IS NULL AND failed_at IS NULL;
+CREATE INDEX idx_outbox_webhooks_pending ON outbox(id) WHERE webhooks_enqueued_at IS NULL;
+CREATE INDEX idx_outbox_delivered_at ON outbox(delivered_at) WHERE delivered_at IS NOT NULL;
@@ -0,0 +1,124 @@
+// Package sqlite_test ...
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+	"wallet/internal/driver/sqlitestore"
+	"wallet/internal/model"
+	"wallet/internal/repository/sqlite"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Репозиторий SQLite должен подходить везде, где подходит Postgres.
+var (
+	_ usecase.WalletRepository    = (*sqlite.WalletRepository)(nil)
+	_ usecase.ReconcileRepository = (*sqlite.WalletRepository)(nil)
+	_ usecase.OutboxRepository    = (*sqlite.WalletRepository)(nil)
+	_ usecase.WebhookRepository   = (*sqlite.WalletRepository)(nil)
+	_ usecase.EventRepository     = (*sqlite.WalletRepository)(nil)
+	_ usecase.TxManager           = (*sqlitestore.Store)(nil)
+)
+
+// setupDB создаёт для теста отдельный файл базы со схемой.
+func setupDB(t testing.TB) (*sql.DB, *sqlitestore.Store) {
+	t.Helper()
+
+	cfg := sqlitestore.DefaultConfig()
+	cfg.TxRetryBaseDelay = time.Millisecond
+	cfg.TxRetryMaxDelay = 5 * time.Millisecond
+
+	ctx := context.Background()
+	store, err := sqlitestore.New(ctx, filepath.Join(t.TempDir(), "wallet.db"), cfg)
+	require.NoError(t, err, "failed to open test database")
+
+	t.Cleanup(func() { store.Close() })
+
+	require.NoError(t, sqlite.Migrate(ctx, store.DB()))
+
+	return store.DB(), store
+}
+
+// accountBalance баланс счёта главной книги: у счёта кошелька - кэш в
+// ledger_accounts, у системного - сумма проводок.
+func accountBalance(t testing.TB, db *sql.DB, accountID uuid.UUID) int64 {
+	t.Helper()
+
+	var balance int64
+	err := db.QueryRowContext(context.Background(),
+		`SELECT CASE WHEN a.kind = 'WALLET' THEN a.balance ELSE (
+			SELECT COALESCE(SUM(p.amount), 0) FROM ledger_postings p WHERE p.account_id = a.id
+		) END
+		FROM ledger_accounts a WHERE a.id = ?1`, accountID,
+	).Scan(&balance)
+	require.NoError(t, err)
+
+	return balance
+}
+
+func TestMigrate_NoTestWallet(t *testing.T) {
+	db, _ := setupDB(t)
+
+	var wallets int
+	err := db.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM wallets`).Scan(&wallets)
+	require.NoError(t, err)
+	assert.Zero(t, wallets)
+}
+
+func TestMigrate_Idempotent(t *testing.T) {
+	db, _ := setupDB(t)
+
+	require.NoError(t, sqlite.Migrate(context.Background(), db))
+}
+
+func TestRepository_CreateWallet_LedgerAccount(t *testing.T) {
+	db, store := setupDB(t)
+	repo := sqlite.New(store)
+	ctx := context.Background()
+
+	walletID := uuid.New()
+	require.NoError(t, repo.CreateWallet(ctx, walletID))
+
+	var kind string
+	err := db.QueryRowContext(ctx, `SELECT kind FROM ledger_accounts WHERE id = ?1`, walletID).Scan(&kind)
+	require.NoError(t, err)
+	assert.Equal(t, "WALLET", kind)
+}
+
+func TestRepository_PostEntry_SkipsSystemAccountRows(t *testing.T) {
+	db, store := setupDB(t)
+	repo := sqlite.New(store)
+	ctx := context.Background()
+
+	walletID := uuid.New()
+	require.NoError(t, repo.CreateWallet(ctx, walletID))
+
+	err := repo.PostEntry(ctx, model.JournalEntry{
+		ID:          uuid.New(),
+		Description: "DEPOSIT",
+		Postings: []model.Posting{
+			{AccountID: walletID, Amount: 40},
+			{AccountID: model.ExternalFundingAccountID, Amount: -40},
+		},
+	})
+	require.NoError(t, err)
+
+	// Кэш ведётся только у счёта кошелька, строка системного счёта не
+	// обновляется.
+	assert.Equal(t, int64(40), accountBalance(t, db, walletID))
+	assert.Equal(t, int64(-40), accountBalance(t, db, model.ExternalFundingAccountID))
+
+	var cached int64
+	err = db.QueryRowContext(ctx,
+		`SELECT balance FROM ledger_accounts WHERE id = ?1`, model.ExternalFundingAccountID,
+	).Scan(&cached)
+	require.NoError(t, err)
+	assert.Zero(t, cached)
+}
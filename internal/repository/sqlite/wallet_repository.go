@@ -0,0 +1,340 @@
+// Package sqlite реализация репозиториев поверх встроенной базы SQLite
+// (sqlitestore): для edge-развёртываний и локальных демо без Postgres.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wallet/internal/driver/sqlitestore"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// timeLayout время хранится строкой фиксированной ширины в UTC, поэтому
+// строки сравниваются и сортируются так же, как моменты времени.
+const timeLayout = "2006-01-02T15:04:05.000000Z"
+
+const operationColumns = `id, wallet_id, operation, amount, transfer_id, balance_before, balance_after, created_at, seq`
+
+// Notifier ...
+type Notifier interface {
+	Notify(walletID uuid.UUID)
+}
+
+// WalletRepository ...
+type WalletRepository struct {
+	store    *sqlitestore.Store
+	notifier Notifier
+}
+
+// New ...
+func New(store *sqlitestore.Store) *WalletRepository {
+	return &WalletRepository{store: store}
+}
+
+// SetNotifier задаёт, кого будить после коммита NotifyWalletChanged.
+func (r *WalletRepository) SetNotifier(n Notifier) {
+	r.notifier = n
+}
+
+func (r *WalletRepository) q(ctx context.Context) sqlitestore.Querier {
+	return r.store.Q(ctx)
+}
+
+// inTx выполняет несколько запросов одного метода атомарно: в SQLite нет
+// CTE с изменением данных, как в Postgres. Внутри транзакции вызывающего
+// fn выполняется под SAVEPOINT, поэтому ошибка откатывает только её.
+func (r *WalletRepository) inTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.store.RunInTx(ctx, fn)
+}
+
+// now время с точностью timeLayout.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+// timeValue сканирует время, сохранённое в формате timeLayout.
+type timeValue struct {
+	t *time.Time
+}
+
+// Scan ...
+func (v timeValue) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("scan time: unexpected type %T", src)
+	}
+
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return fmt.Errorf("scan time: %w", err)
+	}
+	*v.t = t
+
+	return nil
+}
+
+// isConstraint сообщает, что запрос нарушил ограничение с расширенным кодом code.
+func isConstraint(err error, code int) bool {
+	c, ok := sqlitestore.Code(err)
+	return ok && c == code
+}
+
+// CreateWallet создаёт кошелёк с нулевым балансом и его счёт в главной
+// книге. Начальный баланс вносится журнальной записью.
+func (r *WalletRepository) CreateWallet(ctx context.Context, walletID uuid.UUID) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		createdAt := formatTime(now())
+
+		_, err := r.q(ctx).ExecContext(ctx,
+			`INSERT INTO wallets (id, created_at, updated_at) VALUES (?1, ?2, ?2)`,
+			walletID, createdAt,
+		)
+		if err != nil {
+			if isConstraint(err, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY) {
+				return walleterror.ErrWalletAlreadyExists
+			}
+			return fmt.Errorf("create wallet: %w", err)
+		}
+
+		_, err = r.q(ctx).ExecContext(ctx,
+			`INSERT INTO ledger_accounts (id, kind, name, created_at) VALUES (?1, 'WALLET', 'wallet ' || ?1, ?2)`,
+			walletID, createdAt,
+		)
+		if err != nil {
+			return fmt.Errorf("create wallet account: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetBalance ...
+func (r *WalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	var balance int64
+	err := r.q(ctx).QueryRowContext(ctx, `SELECT balance FROM wallets WHERE id = ?1`, walletID).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, walleterror.ErrWalletNotFound
+		}
+		return 0, fmt.Errorf("scan balance: %w", err)
+	}
+
+	return balance, nil
+}
+
+// GetBalanceForUpdate в SQLite совпадает с GetBalance: транзакция уже
+// держит блокировку записи на всю базу (BEGIN IMMEDIATE).
+func (r *WalletRepository) GetBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	return r.GetBalance(ctx, walletID)
+}
+
+// UpdateBalance ...
+func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, newBalance int64) error {
+	query := `UPDATE wallets SET balance = ?1, updated_at = ?2 WHERE id = ?3`
+
+	_, err := r.q(ctx).ExecContext(ctx, query, newBalance, formatTime(now()), walletID)
+	if err != nil {
+		return fmt.Errorf("update balance: %w", err)
+	}
+
+	return nil
+}
+
+// SaveOperation ...
+func (r *WalletRepository) SaveOperation(ctx context.Context, op usecase.Operation) (time.Time, error) {
+	op.CreatedAt = now()
+	if err := r.insertOperation(ctx, &op); err != nil {
+		return time.Time{}, fmt.Errorf("save operation: %w", err)
+	}
+
+	return op.CreatedAt, nil
+}
+
+// insertOperation сохраняет операцию и проставляет op.Seq. Запись в базу
+// одна, поэтому следующий номер берётся как MAX(seq) + 1.
+func (r *WalletRepository) insertOperation(ctx context.Context, op *usecase.Operation) error {
+	query := `
+		INSERT INTO wallet_operations (` + operationColumns + `)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, (SELECT COALESCE(MAX(seq), 0) + 1 FROM wallet_operations))
+		RETURNING seq
+	`
+
+	return r.q(ctx).QueryRowContext(ctx, query,
+		op.ID, op.WalletID, op.Type, op.Amount, op.TransferID,
+		op.BalanceBefore, op.BalanceAfter, formatTime(op.CreatedAt),
+	).Scan(&op.Seq)
+}
+
+// GetOperation ...
+func (r *WalletRepository) GetOperation(ctx context.Context, operationID uuid.UUID) (usecase.Operation, error) {
+	query := `SELECT ` + operationColumns + ` FROM wallet_operations WHERE id = ?1`
+
+	op, err := scanOperation(r.q(ctx).QueryRowContext(ctx, query, operationID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return usecase.Operation{}, walleterror.ErrOperationNotFound
+		}
+		return usecase.Operation{}, fmt.Errorf("get operation: %w", err)
+	}
+
+	return op, nil
+}
+
+// ListOperations ...
+func (r *WalletRepository) ListOperations(ctx context.Context, filter model.OperationFilter) ([]usecase.Operation, error) {
+	var (
+		where = []string{"wallet_id = ?1"}
+		args  = []any{filter.WalletID}
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "?" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.Types) > 0 {
+		in := make([]string, len(filter.Types))
+		for i, typ := range filter.Types {
+			in[i] = arg(typ)
+		}
+		where = append(where, "operation IN ("+strings.Join(in, ", ")+")")
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "created_at >= "+arg(formatTime(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "created_at < "+arg(formatTime(filter.To)))
+	}
+	if filter.After != nil {
+		where = append(where, "(created_at, seq) < ("+arg(formatTime(filter.After.CreatedAt))+", "+arg(filter.After.Seq)+")")
+	}
+
+	query := `
+		SELECT ` + operationColumns + `
+		FROM wallet_operations
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY created_at DESC, seq DESC
+		LIMIT ` + arg(filter.Limit)
+
+	rows, err := r.q(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list operations: %w", err)
+	}
+	defer rows.Close()
+
+	ops := make([]usecase.Operation, 0, filter.Limit)
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list operations: %w", err)
+	}
+
+	return ops, nil
+}
+
+// scanner общий интерфейс *sql.Row и *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOperation(row scanner) (usecase.Operation, error) {
+	var op usecase.Operation
+	err := row.Scan(
+		&op.ID, &op.WalletID, &op.Type, &op.Amount, &op.TransferID,
+		&op.BalanceBefore, &op.BalanceAfter, timeValue{&op.CreatedAt}, &op.Seq,
+	)
+	return op, err
+}
+
+// ClaimIdempotencyKey ...
+func (r *WalletRepository) ClaimIdempotencyKey(
+	ctx context.Context, key, fingerprint string, ttl time.Duration,
+) (model.IdempotencyRecord, bool, error) {
+	// Просроченный ключ перезаписывается, как будто его не было.
+	claim := `
+		INSERT INTO idempotency_keys (key, fingerprint, created_at, expires_at)
+		VALUES (?1, ?2, ?3, ?4)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = excluded.fingerprint,
+		    response = NULL,
+		    created_at = excluded.created_at,
+		    expires_at = excluded.expires_at
+		WHERE idempotency_keys.expires_at <= ?3
+		RETURNING key
+	`
+
+	t := now()
+	var claimed string
+	err := r.q(ctx).QueryRowContext(ctx, claim, key, fingerprint, formatTime(t), formatTime(t.Add(ttl))).Scan(&claimed)
+	if err == nil {
+		return model.IdempotencyRecord{}, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	query := `SELECT key, fingerprint, response FROM idempotency_keys WHERE key = ?1`
+
+	var rec model.IdempotencyRecord
+	err = r.q(ctx).QueryRowContext(ctx, query, key).Scan(&rec.Key, &rec.Fingerprint, &rec.Response)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	return rec, true, nil
+}
+
+// DeleteExpiredIdempotencyKeys ...
+func (r *WalletRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, limit int) (int, error) {
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE key IN (
+			SELECT key FROM idempotency_keys
+			WHERE expires_at <= ?1
+			ORDER BY expires_at
+			LIMIT ?2
+		)
+	`
+
+	res, err := r.q(ctx).ExecContext(ctx, query, formatTime(now()), limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// SaveIdempotencyResponse ...
+func (r *WalletRepository) SaveIdempotencyResponse(ctx context.Context, key string, response []byte) error {
+	query := `UPDATE idempotency_keys SET response = ?1 WHERE key = ?2`
+
+	_, err := r.q(ctx).ExecContext(ctx, query, response, key)
+	if err != nil {
+		return fmt.Errorf("save idempotency response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const webhookColumns = `id, url, event_types, wallet_ids, secret, created_at`
+
+// CreateWebhook ...
+func (r *WalletRepository) CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (id, url, event_types, wallet_ids, secret, created_at)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+		RETURNING ` + webhookColumns
+
+	eventTypes := hook.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	walletIDs := hook.WalletIDs
+	if walletIDs == nil {
+		walletIDs = []uuid.UUID{}
+	}
+
+	eventTypesJSON, err := json.Marshal(eventTypes)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("create webhook: %w", err)
+	}
+	walletIDsJSON, err := json.Marshal(walletIDs)
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("create webhook: %w", err)
+	}
+
+	created, err := scanWebhook(r.q(ctx).QueryRowContext(ctx, query,
+		hook.ID, hook.URL, string(eventTypesJSON), string(walletIDsJSON), hook.Secret, formatTime(now()),
+	))
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("create webhook: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWebhook ...
+func (r *WalletRepository) GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = ?1`
+
+	hook, err := scanWebhook(r.q(ctx).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Webhook{}, walleterror.ErrWebhookNotFound
+		}
+		return model.Webhook{}, fmt.Errorf("get webhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// ListWebhooks ...
+func (r *WalletRepository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY created_at, id`
+
+	rows, err := r.q(ctx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []model.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её доставками и попытками.
+func (r *WalletRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	res, err := r.q(ctx).ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?1`, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	if n == 0 {
+		return walleterror.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries создаёт доставку события для каждой подходящей
+// подписки. Повторный вызов для того же события ничего не добавляет.
+func (r *WalletRepository) EnqueueWebhookDeliveries(ctx context.Context, event model.Event, payload []byte) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, next_attempt_at, created_at, updated_at)
+		SELECT id, ?1, ?2, ?4, ?5, ?5, ?5
+		FROM webhooks
+		WHERE (json_array_length(event_types) = 0 OR ?2 IN (SELECT value FROM json_each(event_types)))
+		  AND (json_array_length(wallet_ids) = 0 OR ?3 IN (SELECT value FROM json_each(wallet_ids)))
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+
+	res, err := r.q(ctx).ExecContext(ctx, query,
+		event.ID, event.Type, event.WalletID.String(), payload, formatTime(now()),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+
+	return int(n), nil
+}
+
+// ClaimDueWebhookDeliveries забирает в аренду на lease до limit доставок,
+// которым пора повторить попытку: следующая попытка переносится на конец
+// аренды, и до него доставку не получит никто другой. Отдельная блокировка
+// строк не нужна: транзакция и так единственный писатель.
+func (r *WalletRepository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?3
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= ?2
+			ORDER BY next_attempt_at, id
+			LIMIT ?1
+		)
+		RETURNING id, webhook_id,
+			(SELECT url FROM webhooks WHERE webhooks.id = webhook_id),
+			(SELECT secret FROM webhooks WHERE webhooks.id = webhook_id),
+			event_id, event_type, payload, attempts
+	`
+
+	t := now()
+	rows, err := r.q(ctx).QueryContext(ctx, query, limit, formatTime(t), formatTime(t.Add(lease)))
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		var d model.WebhookDelivery
+		var payload []byte
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.EventID, &d.EventType, &payload, &d.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt сохраняет попытку и переводит доставку в её статус.
+// Для PENDING следующая попытка назначается через retryIn.
+func (r *WalletRepository) RecordWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt, retryIn time.Duration) error {
+	return r.inTx(ctx, func(ctx context.Context) error {
+		t := now()
+
+		var webhookID uuid.UUID
+		err := r.q(ctx).QueryRowContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = ?2,
+				attempts = ?3,
+				next_attempt_at = ?4,
+				updated_at = ?5
+			WHERE id = ?1
+			RETURNING webhook_id`,
+			attempt.DeliveryID, string(attempt.Status), attempt.Attempt, formatTime(t.Add(retryIn)), formatTime(t),
+		).Scan(&webhookID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("record webhook attempt: %w", err)
+		}
+
+		_, err = r.q(ctx).ExecContext(ctx, `
+			INSERT INTO webhook_attempts (delivery_id, webhook_id, attempt, status_code, error, duration_ms, status, created_at)
+			VALUES (?1, ?2, ?3, NULLIF(?4, 0), NULLIF(?5, ''), ?6, ?7, ?8)`,
+			attempt.DeliveryID, webhookID, attempt.Attempt, attempt.StatusCode, attempt.Error,
+			attempt.Duration.Milliseconds(), string(attempt.Status), formatTime(t),
+		)
+		if err != nil {
+			return fmt.Errorf("record webhook attempt: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ListWebhookAttempts последние попытки доставки по подписке, новые первыми.
+func (r *WalletRepository) ListWebhookAttempts(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookAttempt, error) {
+	query := `
+		SELECT a.id, a.delivery_id, a.webhook_id, d.event_id, d.event_type, a.attempt,
+			COALESCE(a.status_code, 0), COALESCE(a.error, ''), a.duration_ms, a.status, a.created_at
+		FROM webhook_attempts a
+		JOIN webhook_deliveries d ON d.id = a.delivery_id
+		WHERE a.webhook_id = ?1
+		ORDER BY a.id DESC
+		LIMIT ?2
+	`
+
+	rows, err := r.q(ctx).QueryContext(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]model.WebhookAttempt, 0, limit)
+	for rows.Next() {
+		var a model.WebhookAttempt
+		var durationMs int64
+		err := rows.Scan(
+			&a.ID, &a.DeliveryID, &a.WebhookID, &a.EventID, &a.EventType, &a.Attempt,
+			&a.StatusCode, &a.Error, &durationMs, &a.Status, timeValue{&a.CreatedAt},
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook attempt: %w", err)
+		}
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list webhook attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func scanWebhook(row scanner) (model.Webhook, error) {
+	var (
+		hook                  model.Webhook
+		eventTypes, walletIDs string
+	)
+	err := row.Scan(&hook.ID, &hook.URL, &eventTypes, &walletIDs, &hook.Secret, timeValue{&hook.CreatedAt})
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	if err := json.Unmarshal([]byte(eventTypes), &hook.EventTypes); err != nil {
+		return model.Webhook{}, fmt.Errorf("decode event types: %w", err)
+	}
+	if err := json.Unmarshal([]byte(walletIDs), &hook.WalletIDs); err != nil {
+		return model.Webhook{}, fmt.Errorf("decode wallet ids: %w", err)
+	}
+
+	return hook, nil
+}
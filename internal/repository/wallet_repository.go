@@ -5,8 +5,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 	txctx "wallet/internal/driver"
 	walleterror "wallet/internal/error"
+	"wallet/internal/model"
 	"wallet/internal/usecase"
 
 	"github.com/google/uuid"
@@ -15,7 +19,13 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// uniqueViolation ...
+const uniqueViolation = "23505"
+
+const operationColumns = `id, wallet_id, operation, amount, transfer_id, balance_before, balance_after, created_at, seq`
+
 type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
@@ -37,6 +47,30 @@ func (r *WalletRepository) q(ctx context.Context) querier {
 	return r.pool
 }
 
+// CreateWallet создаёт кошелёк с нулевым балансом и его счёт в главной
+// книге. Начальный баланс вносится журнальной записью.
+func (r *WalletRepository) CreateWallet(ctx context.Context, walletID uuid.UUID) error {
+	query := `
+		WITH w AS (
+			INSERT INTO wallets (id) VALUES ($1)
+			RETURNING id, created_at
+		)
+		INSERT INTO ledger_accounts (id, kind, name, created_at)
+		SELECT id, 'WALLET', 'wallet ' || id, created_at FROM w
+	`
+
+	_, err := r.q(ctx).Exec(ctx, query, walletID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return walleterror.ErrWalletAlreadyExists
+		}
+		return fmt.Errorf("create wallet: %w", err)
+	}
+
+	return nil
+}
+
 // GetBalance ...
 func (r *WalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error) {
 	return r.scanBalance(ctx, walletID, false)
@@ -78,15 +112,168 @@ func (r *WalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID
 }
 
 // SaveOperation ...
-func (r *WalletRepository) SaveOperation(ctx context.Context, op usecase.Operation) error {
+func (r *WalletRepository) SaveOperation(ctx context.Context, op usecase.Operation) (time.Time, error) {
 	query := `
-		INSERT INTO wallet_operations (id, wallet_id, operation, amount)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO wallet_operations (id, wallet_id, operation, amount, transfer_id, balance_before, balance_after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING created_at
 	`
 
-	_, err := r.q(ctx).Exec(ctx, query, op.ID, op.WalletID, op.Type, op.Amount)
+	var createdAt time.Time
+	err := r.q(ctx).QueryRow(ctx, query,
+		op.ID, op.WalletID, op.Type, op.Amount, op.TransferID, op.BalanceBefore, op.BalanceAfter,
+	).Scan(&createdAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("save operation: %w", err)
+	}
+
+	return createdAt, nil
+}
+
+// GetOperation ...
+func (r *WalletRepository) GetOperation(ctx context.Context, operationID uuid.UUID) (usecase.Operation, error) {
+	query := `SELECT ` + operationColumns + ` FROM wallet_operations WHERE id = $1`
+
+	op, err := scanOperation(r.q(ctx).QueryRow(ctx, query, operationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return usecase.Operation{}, walleterror.ErrOperationNotFound
+		}
+		return usecase.Operation{}, fmt.Errorf("get operation: %w", err)
+	}
+
+	return op, nil
+}
+
+// ListOperations ...
+func (r *WalletRepository) ListOperations(ctx context.Context, filter model.OperationFilter) ([]usecase.Operation, error) {
+	var (
+		where = []string{"wallet_id = $1"}
+		args  = []any{filter.WalletID}
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.Types) > 0 {
+		where = append(where, "operation::text = ANY("+arg(filter.Types)+")")
+	}
+	if !filter.From.IsZero() {
+		where = append(where, "created_at >= "+arg(filter.From.UTC()))
+	}
+	if !filter.To.IsZero() {
+		where = append(where, "created_at < "+arg(filter.To.UTC()))
+	}
+	if filter.After != nil {
+		where = append(where, "(created_at, seq) < ("+arg(filter.After.CreatedAt.UTC())+", "+arg(filter.After.Seq)+")")
+	}
+
+	query := `
+		SELECT ` + operationColumns + `
+		FROM wallet_operations
+		WHERE ` + strings.Join(where, " AND ") + `
+		ORDER BY created_at DESC, seq DESC
+		LIMIT ` + arg(filter.Limit)
+
+	rows, err := r.q(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list operations: %w", err)
+	}
+	defer rows.Close()
+
+	ops := make([]usecase.Operation, 0, filter.Limit)
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan operation: %w", err)
+		}
+		ops = append(ops, op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list operations: %w", err)
+	}
+
+	return ops, nil
+}
+
+func scanOperation(row pgx.Row) (usecase.Operation, error) {
+	var op usecase.Operation
+	err := row.Scan(
+		&op.ID, &op.WalletID, &op.Type, &op.Amount, &op.TransferID,
+		&op.BalanceBefore, &op.BalanceAfter, &op.CreatedAt, &op.Seq,
+	)
+	return op, err
+}
+
+// ClaimIdempotencyKey ...
+func (r *WalletRepository) ClaimIdempotencyKey(
+	ctx context.Context, key, fingerprint string, ttl time.Duration,
+) (model.IdempotencyRecord, bool, error) {
+	// Просроченный ключ перезаписывается, как будто его не было. Если ключ
+	// держит незавершённая транзакция, INSERT дождётся её окончания.
+	claim := `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint,
+		    response = NULL,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		RETURNING key
+	`
+
+	var claimed string
+	err := r.q(ctx).QueryRow(ctx, claim, key, fingerprint, ttl.Seconds()).Scan(&claimed)
+	if err == nil {
+		return model.IdempotencyRecord{}, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	query := `SELECT key, fingerprint, response FROM idempotency_keys WHERE key = $1`
+
+	var rec model.IdempotencyRecord
+	err = r.q(ctx).QueryRow(ctx, query, key).Scan(&rec.Key, &rec.Fingerprint, &rec.Response)
+	if err != nil {
+		return model.IdempotencyRecord{}, false, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	return rec, true, nil
+}
+
+// DeleteExpiredIdempotencyKeys ...
+func (r *WalletRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, limit int) (int, error) {
+	// Ключ, который сейчас перезаписывает Claim, заблокирован: его
+	// пропускаем, а не ждём.
+	query := `
+		DELETE FROM idempotency_keys
+		WHERE key IN (
+			SELECT key FROM idempotency_keys
+			WHERE expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+
+	tag, err := r.q(ctx).Exec(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// SaveIdempotencyResponse ...
+func (r *WalletRepository) SaveIdempotencyResponse(ctx context.Context, key string, response []byte) error {
+	query := `UPDATE idempotency_keys SET response = $1 WHERE key = $2`
+
+	_, err := r.q(ctx).Exec(ctx, query, response, key)
 	if err != nil {
-		return fmt.Errorf("save operation: %w", err)
+		return fmt.Errorf("save idempotency response: %w", err)
 	}
 
 	return nil
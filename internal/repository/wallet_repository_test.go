@@ -5,8 +5,8 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 	"wallet/internal/driver/sqlstore"
-	walleterror "wallet/internal/error"
 	"wallet/internal/repository"
 	"wallet/internal/usecase"
 
@@ -17,7 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func setupDB(t *testing.T) (*pgxpool.Pool, *sqlstore.Store) {
+func setupDB(t testing.TB) (*pgxpool.Pool, *sqlstore.Store) {
 	t.Helper()
 
 	err := godotenv.Load("../../config.env")
@@ -38,112 +38,68 @@ func setupDB(t *testing.T) (*pgxpool.Pool, *sqlstore.Store) {
 	return store.Pool(), store
 }
 
-func createWallet(t *testing.T, pool *pgxpool.Pool, balance int64) uuid.UUID {
+func createWallet(t testing.TB, pool *pgxpool.Pool, balance int64) uuid.UUID {
 	t.Helper()
 
+	// Фикстура: баланс кладётся напрямую, без журнальной записи.
 	id := uuid.New()
-	_, err := pool.Exec(context.Background(),
-		`INSERT INTO wallets (id, balance) VALUES ($1, $2)`,
+	_, err := pool.Exec(context.Background(), `
+		WITH w AS (
+			INSERT INTO wallets (id, balance) VALUES ($1, $2)
+			RETURNING id, balance
+		)
+		INSERT INTO ledger_accounts (id, kind, name, balance)
+		SELECT id, 'WALLET', 'test wallet', balance FROM w`,
 		id, balance,
 	)
 	require.NoError(t, err)
 
-	t.Cleanup(func() {
-		_, _ = pool.Exec(context.Background(), `DELETE FROM wallets WHERE id = $1`, id)
-	})
+	t.Cleanup(func() { deleteWallet(pool, id) })
 
 	return id
 }
 
-// --- GetBalance ---
-
-func TestRepository_GetBalance_Success(t *testing.T) {
-	pool, _ := setupDB(t)
-	repo := repository.New(pool)
-	ctx := context.Background()
-
-	walletID := createWallet(t, pool, 1000)
-
-	balance, err := repo.GetBalance(ctx, walletID)
-
-	require.NoError(t, err)
-	assert.Equal(t, int64(1000), balance)
-}
-
-func TestRepository_GetBalance_NotFound(t *testing.T) {
-	pool, _ := setupDB(t)
-	repo := repository.New(pool)
-	ctx := context.Background()
-
-	balance, err := repo.GetBalance(ctx, uuid.New())
-
-	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
-	assert.Equal(t, int64(0), balance)
-}
-
-// --- GetBalanceForUpdate ---
-
-func TestRepository_GetBalanceForUpdate_InsideTx(t *testing.T) {
-	pool, store := setupDB(t)
-	repo := repository.New(pool)
-	ctx := context.Background()
-
-	walletID := createWallet(t, pool, 500)
-
-	err := store.RunInTx(ctx, func(ctx context.Context) error {
-		balance, err := repo.GetBalanceForUpdate(ctx, walletID)
-		require.NoError(t, err)
-		assert.Equal(t, int64(500), balance)
-		return nil
-	})
-
-	require.NoError(t, err)
-}
-
-func TestRepository_GetBalanceForUpdate_NotFound(t *testing.T) {
-	pool, store := setupDB(t)
-	repo := repository.New(pool)
+// deleteWallet удаляет кошелёк вместе с его журнальными записями и
+// событиями outbox. Проводки по системным счетам из этих записей тоже
+// удаляются, но кэш их балансов не пересчитывается.
+func deleteWallet(pool *pgxpool.Pool, id uuid.UUID) {
 	ctx := context.Background()
-
-	err := store.RunInTx(ctx, func(ctx context.Context) error {
-		_, err := repo.GetBalanceForUpdate(ctx, uuid.New())
-		return err
-	})
-
-	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	for _, query := range []string{
+		`WITH entries AS (
+			SELECT DISTINCT entry_id FROM ledger_postings WHERE account_id = $1
+		), postings AS (
+			DELETE FROM ledger_postings WHERE entry_id IN (SELECT entry_id FROM entries)
+		)
+		DELETE FROM journal_entries WHERE id IN (SELECT entry_id FROM entries)`,
+		`DELETE FROM outbox WHERE wallet_id = $1`,
+		`DELETE FROM ledger_accounts WHERE id = $1`,
+		`DELETE FROM wallets WHERE id = $1`,
+	} {
+		_, _ = pool.Exec(ctx, query, id)
+	}
 }
 
-// --- UpdateBalance ---
+// --- CreateWallet ---
 
-func TestRepository_UpdateBalance_Success(t *testing.T) {
-	pool, store := setupDB(t)
+func TestRepository_CreateWallet_Success(t *testing.T) {
+	pool, _ := setupDB(t)
 	repo := repository.New(pool)
 	ctx := context.Background()
 
-	walletID := createWallet(t, pool, 0)
+	walletID := uuid.New()
+	t.Cleanup(func() { deleteWallet(pool, walletID) })
 
-	err := store.RunInTx(ctx, func(ctx context.Context) error {
-		return repo.UpdateBalance(ctx, walletID, 750)
-	})
+	err := repo.CreateWallet(ctx, walletID)
 	require.NoError(t, err)
 
 	balance, err := repo.GetBalance(ctx, walletID)
 	require.NoError(t, err)
-	assert.Equal(t, int64(750), balance)
-}
-
-func TestRepository_UpdateBalance_CheckConstraint(t *testing.T) {
-	pool, store := setupDB(t)
-	repo := repository.New(pool)
-	ctx := context.Background()
-
-	walletID := createWallet(t, pool, 100)
-
-	err := store.RunInTx(ctx, func(ctx context.Context) error {
-		return repo.UpdateBalance(ctx, walletID, -1)
-	})
+	assert.Equal(t, int64(0), balance)
 
-	assert.Error(t, err)
+	var kind string
+	err = pool.QueryRow(ctx, `SELECT kind FROM ledger_accounts WHERE id = $1`, walletID).Scan(&kind)
+	require.NoError(t, err)
+	assert.Equal(t, "WALLET", kind)
 }
 
 // --- SaveOperation ---
@@ -157,12 +113,13 @@ func TestRepository_SaveOperation_Success(t *testing.T) {
 	opID := uuid.New()
 
 	err := store.RunInTx(ctx, func(ctx context.Context) error {
-		return repo.SaveOperation(ctx, usecase.Operation{
+		_, err := repo.SaveOperation(ctx, usecase.Operation{
 			ID:       opID,
 			WalletID: walletID,
 			Type:     "DEPOSIT",
 			Amount:   300,
 		})
+		return err
 	})
 	require.NoError(t, err)
 
@@ -175,41 +132,55 @@ func TestRepository_SaveOperation_Success(t *testing.T) {
 	assert.Equal(t, 1, count)
 }
 
-func TestRepository_SaveOperation_InvalidType(t *testing.T) {
+func TestRepository_SaveOperation_Transfer(t *testing.T) {
 	pool, store := setupDB(t)
 	repo := repository.New(pool)
 	ctx := context.Background()
 
 	walletID := createWallet(t, pool, 0)
+	opID := uuid.New()
+	transferID := uuid.New()
 
 	err := store.RunInTx(ctx, func(ctx context.Context) error {
-		return repo.SaveOperation(ctx, usecase.Operation{
-			ID:       uuid.New(),
-			WalletID: walletID,
-			Type:     "INVALID",
-			Amount:   100,
+		_, err := repo.SaveOperation(ctx, usecase.Operation{
+			ID:         opID,
+			WalletID:   walletID,
+			Type:       "TRANSFER_IN",
+			Amount:     300,
+			TransferID: uuid.NullUUID{UUID: transferID, Valid: true},
 		})
+		return err
 	})
+	require.NoError(t, err)
 
-	assert.Error(t, err)
+	var stored uuid.UUID
+	err = pool.QueryRow(ctx,
+		`SELECT transfer_id FROM wallet_operations WHERE id = $1`,
+		opID,
+	).Scan(&stored)
+	require.NoError(t, err)
+	assert.Equal(t, transferID, stored)
 }
 
-// --- Транзакционность ---
+// --- Idempotency ---
 
-func TestRepository_Rollback_OnError(t *testing.T) {
-	pool, store := setupDB(t)
+func TestRepository_ClaimIdempotencyKey_Expired(t *testing.T) {
+	pool, _ := setupDB(t)
 	repo := repository.New(pool)
 	ctx := context.Background()
 
-	walletID := createWallet(t, pool, 100)
-
-	_ = store.RunInTx(ctx, func(ctx context.Context) error {
-		err := repo.UpdateBalance(ctx, walletID, 9999)
-		require.NoError(t, err)
-		return assert.AnError
+	key := uuid.NewString()
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), `DELETE FROM idempotency_keys WHERE key = $1`, key)
 	})
 
-	balance, err := repo.GetBalance(ctx, walletID)
+	_, err := pool.Exec(ctx,
+		`INSERT INTO idempotency_keys (key, fingerprint, expires_at) VALUES ($1, 'old', NOW() - INTERVAL '1 minute')`,
+		key,
+	)
+	require.NoError(t, err)
+
+	_, found, err := repo.ClaimIdempotencyKey(ctx, key, "new", time.Hour)
 	require.NoError(t, err)
-	assert.Equal(t, int64(100), balance)
+	assert.False(t, found)
 }
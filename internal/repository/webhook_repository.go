@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const webhookColumns = `id, url, event_types, wallet_ids, secret, created_at`
+
+// CreateWebhook ...
+func (r *WalletRepository) CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error) {
+	query := `
+		INSERT INTO webhooks (id, url, event_types, wallet_ids, secret)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + webhookColumns
+
+	eventTypes := hook.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	walletIDs := hook.WalletIDs
+	if walletIDs == nil {
+		walletIDs = []uuid.UUID{}
+	}
+
+	created, err := scanWebhook(r.q(ctx).QueryRow(ctx, query, hook.ID, hook.URL, eventTypes, walletIDs, hook.Secret))
+	if err != nil {
+		return model.Webhook{}, fmt.Errorf("create webhook: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetWebhook ...
+func (r *WalletRepository) GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+
+	hook, err := scanWebhook(r.q(ctx).QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Webhook{}, walleterror.ErrWebhookNotFound
+		}
+		return model.Webhook{}, fmt.Errorf("get webhook: %w", err)
+	}
+
+	return hook, nil
+}
+
+// ListWebhooks ...
+func (r *WalletRepository) ListWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks ORDER BY created_at, id`
+
+	rows, err := r.q(ctx).Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	hooks := []model.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list webhooks: %w", err)
+	}
+
+	return hooks, nil
+}
+
+// DeleteWebhook удаляет подписку вместе с её доставками и попытками.
+func (r *WalletRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.q(ctx).Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return walleterror.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries создаёт доставку события для каждой подходящей
+// подписки. Повторный вызов для того же события ничего не добавляет.
+func (r *WalletRepository) EnqueueWebhookDeliveries(ctx context.Context, event model.Event, payload []byte) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $4
+		FROM webhooks
+		WHERE (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+		  AND (cardinality(wallet_ids) = 0 OR $3 = ANY(wallet_ids))
+		ON CONFLICT (webhook_id, event_id) DO NOTHING
+	`
+
+	tag, err := r.q(ctx).Exec(ctx, query, event.ID, event.Type, event.WalletID, payload)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ClaimDueWebhookDeliveries забирает в аренду на lease до limit доставок,
+// которым пора повторить попытку: следующая попытка переносится на конец
+// аренды, и до него доставку не получит никто другой. Доставки, занятые
+// другим воркером, пропускаются.
+func (r *WalletRepository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		  AND d.id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		  )
+		RETURNING d.id, d.webhook_id, w.url, w.secret, d.event_id, d.event_type, d.payload, d.attempts
+	`
+
+	rows, err := r.q(ctx).Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		var d model.WebhookDelivery
+		var payload []byte
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.EventID, &d.EventType, &payload, &d.Attempts)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt сохраняет попытку и переводит доставку в её статус.
+// Для PENDING следующая попытка назначается через retryIn.
+func (r *WalletRepository) RecordWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt, retryIn time.Duration) error {
+	query := `
+		WITH delivery AS (
+			UPDATE webhook_deliveries
+			SET status = $2,
+				attempts = $3,
+				next_attempt_at = NOW() + make_interval(secs => $7),
+				updated_at = NOW()
+			WHERE id = $1
+			RETURNING id, webhook_id
+		)
+		INSERT INTO webhook_attempts (delivery_id, webhook_id, attempt, status_code, error, duration_ms, status)
+		SELECT id, webhook_id, $3, NULLIF($4, 0), NULLIF($5, ''), $6, $2
+		FROM delivery
+	`
+
+	_, err := r.q(ctx).Exec(ctx, query,
+		attempt.DeliveryID, string(attempt.Status), attempt.Attempt,
+		attempt.StatusCode, attempt.Error, attempt.Duration.Milliseconds(), retryIn.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("record webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ListWebhookAttempts последние попытки доставки по подписке, новые первыми.
+func (r *WalletRepository) ListWebhookAttempts(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookAttempt, error) {
+	query := `
+		SELECT a.id, a.delivery_id, a.webhook_id, d.event_id, d.event_type, a.attempt,
+			COALESCE(a.status_code, 0), COALESCE(a.error, ''), a.duration_ms, a.status::text, a.created_at
+		FROM webhook_attempts a
+		JOIN webhook_deliveries d ON d.id = a.delivery_id
+		WHERE a.webhook_id = $1
+		ORDER BY a.id DESC
+		LIMIT $2
+	`
+
+	rows, err := r.q(ctx).Query(ctx, query, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list webhook attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := make([]model.WebhookAttempt, 0, limit)
+	for rows.Next() {
+		var a model.WebhookAttempt
+		var durationMs int64
+		err := rows.Scan(
+			&a.ID, &a.DeliveryID, &a.WebhookID, &a.EventID, &a.EventType, &a.Attempt,
+			&a.StatusCode, &a.Error, &durationMs, &a.Status, &a.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook attempt: %w", err)
+		}
+		a.Duration = time.Duration(durationMs) * time.Millisecond
+		attempts = append(attempts, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list webhook attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+func scanWebhook(row pgx.Row) (model.Webhook, error) {
+	var hook model.Webhook
+	err := row.Scan(&hook.ID, &hook.URL, &hook.EventTypes, &hook.WalletIDs, &hook.Secret, &hook.CreatedAt)
+	return hook, err
+}
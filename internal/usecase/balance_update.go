@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// BalanceUpdateMode как Deposit и Withdraw меняют баланс кошелька.
+type BalanceUpdateMode string
+
+const (
+	// BalanceUpdateLocked баланс читается под FOR UPDATE, проверяется в Go
+	// и записывается следующими запросами. Режим по умолчанию.
+	BalanceUpdateLocked BalanceUpdateMode = "locked"
+	// BalanceUpdateAtomic баланс, операция и журнальная запись меняются
+	// одним условным UPDATE: строка кошелька заблокирована на меньшее число
+	// запросов. Выигрыш по сравнению с locked замерами не подтверждён.
+	BalanceUpdateAtomic BalanceUpdateMode = "atomic"
+)
+
+// ParseBalanceUpdateMode ...
+func ParseBalanceUpdateMode(s string) (BalanceUpdateMode, error) {
+	switch mode := BalanceUpdateMode(s); mode {
+	case BalanceUpdateLocked, BalanceUpdateAtomic:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown balance update mode %q", s)
+	}
+}
+
+// WithBalanceUpdate ...
+func WithBalanceUpdate(mode BalanceUpdateMode) Option {
+	return func(u *WalletUsecase) {
+		if mode != "" {
+			u.balanceUpdate = mode
+		}
+	}
+}
+
+func (u *WalletUsecase) depositLocked(ctx context.Context, walletID uuid.UUID, amount int64) (Operation, error) {
+	balance, err := u.repo.GetBalanceForUpdate(ctx, walletID)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return u.deposit(ctx, walletID, amount, balance)
+}
+
+func (u *WalletUsecase) withdrawLocked(ctx context.Context, walletID uuid.UUID, amount int64) (Operation, error) {
+	balance, err := u.repo.GetBalanceForUpdate(ctx, walletID)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	held, err := u.repo.GetHeldAmount(ctx, walletID)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return u.withdraw(ctx, walletID, amount, balance, held)
+}
+
+func (u *WalletUsecase) depositAtomic(ctx context.Context, walletID uuid.UUID, amount int64) (Operation, error) {
+	op := Operation{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     "DEPOSIT",
+		Amount:   amount,
+	}
+	op, err := u.repo.ApplyOperation(ctx, op, model.JournalEntry{
+		ID:          op.ID,
+		Description: "DEPOSIT",
+		Postings: []model.Posting{
+			{AccountID: walletID, Amount: amount},
+			{AccountID: model.ExternalFundingAccountID, Amount: -amount},
+		},
+	})
+	if err != nil {
+		return Operation{}, err
+	}
+
+	if err := publishOperation(ctx, u.repo, op); err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
+
+func (u *WalletUsecase) withdrawAtomic(ctx context.Context, walletID uuid.UUID, amount int64) (Operation, error) {
+	op := Operation{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     "WITHDRAW",
+		Amount:   amount,
+	}
+	op, err := u.repo.ApplyOperation(ctx, op, model.JournalEntry{
+		ID:          op.ID,
+		Description: "WITHDRAW",
+		Postings: []model.Posting{
+			{AccountID: walletID, Amount: -amount},
+			{AccountID: model.ExternalFundingAccountID, Amount: amount},
+		},
+	})
+	if err != nil {
+		return Operation{}, err
+	}
+
+	// UPDATE проверил только balance >= 0. Холды проверяются после него:
+	// строка кошелька уже заблокирована, новый холд до коммита не появится,
+	// а ошибка откатит списание вместе с транзакцией.
+	held, err := u.repo.GetHeldAmount(ctx, walletID)
+	if err != nil {
+		return Operation{}, err
+	}
+	if op.BalanceAfter < held {
+		return Operation{}, walleterror.ErrInsufficientFunds
+	}
+
+	if err := publishOperation(ctx, u.repo, op); err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
@@ -0,0 +1,131 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- BalanceUpdateAtomic ---
+
+func TestUsecase_DepositAtomic_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.
+		On("ApplyOperation", ctx,
+			mock.MatchedBy(func(op usecase.Operation) bool {
+				return op.WalletID == walletID && op.Type == "DEPOSIT" && op.Amount == 100
+			}),
+			mock.MatchedBy(func(e model.JournalEntry) bool {
+				return e.Balanced() && len(e.Postings) == 2 && e.Postings[0].AccountID == walletID && e.Postings[0].Amount == 100
+			})).
+		Return(func(_ context.Context, op usecase.Operation, _ model.JournalEntry) (usecase.Operation, error) {
+			op.BalanceBefore, op.BalanceAfter = 500, 600
+			return op, nil
+		})
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, walletID).Return(nil)
+
+	u := usecase.New(repo, txm, usecase.WithBalanceUpdate(usecase.BalanceUpdateAtomic))
+	receipt, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 100})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(600), receipt.BalanceAfter)
+	assert.Equal(t, int64(500), receipt.Operation.BalanceBefore)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "GetBalanceForUpdate", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "SaveOperation", mock.Anything, mock.Anything)
+}
+
+func TestUsecase_DepositAtomic_WalletNotFound(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	setupTxManager(txm)
+	repo.On("ApplyOperation", ctx, mock.Anything, mock.Anything).Return(usecase.Operation{}, walleterror.ErrWalletNotFound)
+
+	u := usecase.New(repo, txm, usecase.WithBalanceUpdate(usecase.BalanceUpdateAtomic))
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: testUUID(), Amount: 100})
+
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	repo.AssertNotCalled(t, "SaveEvent", mock.Anything, mock.Anything)
+}
+
+func TestUsecase_WithdrawAtomic_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.
+		On("ApplyOperation", ctx, mock.Anything, mock.MatchedBy(func(e model.JournalEntry) bool {
+			return e.Postings[0].AccountID == walletID && e.Postings[0].Amount == -30
+		})).
+		Return(func(_ context.Context, op usecase.Operation, _ model.JournalEntry) (usecase.Operation, error) {
+			op.BalanceBefore, op.BalanceAfter = 100, 70
+			return op, nil
+		})
+	repo.On("GetHeldAmount", ctx, walletID).Return(int64(70), nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, walletID).Return(nil)
+
+	u := usecase.New(repo, txm, usecase.WithBalanceUpdate(usecase.BalanceUpdateAtomic))
+	receipt, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 30})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(70), receipt.BalanceAfter)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_WithdrawAtomic_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		applyErr error
+		held     int64
+		err      error
+	}{
+		{"insufficient funds", walleterror.ErrInsufficientFunds, 0, walleterror.ErrInsufficientFunds},
+		{"wallet not found", walleterror.ErrWalletNotFound, 0, walleterror.ErrWalletNotFound},
+		{"held funds unavailable", nil, 71, walleterror.ErrInsufficientFunds},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.WalletRepository)
+			txm := new(mocks.TxManager)
+			ctx := context.Background()
+			walletID := testUUID()
+
+			setupTxManager(txm)
+			repo.On("ApplyOperation", ctx, mock.Anything, mock.Anything).Return(usecase.Operation{BalanceAfter: 70}, tt.applyErr)
+			repo.On("GetHeldAmount", ctx, walletID).Return(tt.held, nil)
+
+			u := usecase.New(repo, txm, usecase.WithBalanceUpdate(usecase.BalanceUpdateAtomic))
+			_, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 30})
+
+			assert.ErrorIs(t, err, tt.err)
+			repo.AssertNotCalled(t, "SaveEvent", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestParseBalanceUpdateMode(t *testing.T) {
+	mode, err := usecase.ParseBalanceUpdateMode("atomic")
+	require.NoError(t, err)
+	assert.Equal(t, usecase.BalanceUpdateAtomic, mode)
+
+	_, err = usecase.ParseBalanceUpdateMode("optimistic")
+	assert.Error(t, err)
+}
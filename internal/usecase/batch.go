@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchSize ...
+const MaxBatchSize = 1000
+
+// ExecuteBatch выполняет пакет операций. В атомарном режиме все операции
+// идут в одной транзакции: при первой ошибке пакет откатывается целиком и
+// возвращается *walleterror.BatchItemError с номером операции. Иначе каждая
+// операция выполняется в своей транзакции, а её ошибка попадает в результат.
+func (u *WalletUsecase) ExecuteBatch(ctx context.Context, in model.BatchInput) ([]model.BatchResult, error) {
+	if len(in.Operations) == 0 || len(in.Operations) > MaxBatchSize {
+		return nil, walleterror.ErrInvalidBatchSize
+	}
+
+	for i, op := range in.Operations {
+		if op.Type != "DEPOSIT" && op.Type != "WITHDRAW" {
+			return nil, &walleterror.BatchItemError{Index: i, Err: walleterror.ErrInvalidOperationType}
+		}
+	}
+
+	if in.Atomic {
+		return u.executeAtomicBatch(ctx, in.Operations)
+	}
+
+	results := make([]model.BatchResult, len(in.Operations))
+	for i, op := range in.Operations {
+		var (
+			receipt model.Receipt
+			err     error
+		)
+		switch op.Type {
+		case "DEPOSIT":
+			receipt, err = u.Deposit(ctx, model.DepositInput{WalletID: op.WalletID, Amount: op.Amount})
+		case "WITHDRAW":
+			receipt, err = u.Withdraw(ctx, model.WithdrawInput{WalletID: op.WalletID, Amount: op.Amount})
+		}
+		results[i] = model.BatchResult{Receipt: receipt, Err: err}
+	}
+
+	return results, nil
+}
+
+func (u *WalletUsecase) executeAtomicBatch(ctx context.Context, ops []model.BatchOperation) ([]model.BatchResult, error) {
+	// Номер первой операции по каждому кошельку: к ней относится ошибка
+	// блокировки кошелька.
+	firstIndex := make(map[uuid.UUID]int, len(ops))
+	for i, op := range ops {
+		if _, ok := firstIndex[op.WalletID]; !ok {
+			firstIndex[op.WalletID] = i
+		}
+	}
+
+	// Как и в Transfer, кошельки блокируются в порядке возрастания ID:
+	// пересекающиеся пакеты не ловят дедлок.
+	lockOrder := make([]uuid.UUID, 0, len(firstIndex))
+	for walletID := range firstIndex {
+		lockOrder = append(lockOrder, walletID)
+	}
+	slices.SortFunc(lockOrder, func(a, b uuid.UUID) int {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	results := make([]model.BatchResult, len(ops))
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		balances := make(map[uuid.UUID]int64, len(lockOrder))
+		for _, walletID := range lockOrder {
+			balance, err := u.repo.GetBalanceForUpdate(ctx, walletID)
+			if err != nil {
+				return &walleterror.BatchItemError{Index: firstIndex[walletID], Err: err}
+			}
+			balances[walletID] = balance
+		}
+
+		// Холды кошелька не меняются, пока он заблокирован: читаем их один раз.
+		held := make(map[uuid.UUID]int64)
+		for i, in := range ops {
+			var (
+				op  Operation
+				err error
+			)
+			switch in.Type {
+			case "DEPOSIT":
+				op, err = u.deposit(ctx, in.WalletID, in.Amount, balances[in.WalletID])
+			case "WITHDRAW":
+				h, ok := held[in.WalletID]
+				if !ok {
+					h, err = u.repo.GetHeldAmount(ctx, in.WalletID)
+					if err != nil {
+						return &walleterror.BatchItemError{Index: i, Err: err}
+					}
+					held[in.WalletID] = h
+				}
+				op, err = u.withdraw(ctx, in.WalletID, in.Amount, balances[in.WalletID], h)
+			}
+			if err != nil {
+				return &walleterror.BatchItemError{Index: i, Err: err}
+			}
+
+			balances[in.WalletID] = op.BalanceAfter
+			results[i] = model.BatchResult{Receipt: model.Receipt{Operation: op, BalanceAfter: op.BalanceAfter}}
+		}
+
+		return nil
+	})
+	u.observeAtomicBatch(ops, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// observeAtomicBatch записывает исходы атомарного пакета после его
+// транзакции. Если пакет откатился из-за операции, записывается только её
+// исход: остальные операции не проведены.
+func (u *WalletUsecase) observeAtomicBatch(ops []model.BatchOperation, err error) {
+	var itemErr *walleterror.BatchItemError
+	if errors.As(err, &itemErr) {
+		u.observeOperation(ops[itemErr.Index].Type, err)
+		return
+	}
+
+	for _, op := range ops {
+		u.observeOperation(op.Type, err)
+	}
+}
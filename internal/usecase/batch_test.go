@@ -0,0 +1,176 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- ExecuteBatch ---
+
+func TestUsecase_ExecuteBatch_AtomicSuccess(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	a := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	b := testUUID()
+
+	setupTxManager(txm)
+	// Кошельки блокируются по возрастанию ID, а не в порядке операций.
+	mock.InOrder(
+		repo.On("GetBalanceForUpdate", ctx, b).Return(int64(50), nil).Once(),
+		repo.On("GetBalanceForUpdate", ctx, a).Return(int64(100), nil).Once(),
+	)
+	repo.On("GetHeldAmount", ctx, a).Return(int64(20), nil).Once()
+
+	var saved []usecase.Operation
+	repo.
+		On("SaveOperation", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			saved = append(saved, args.Get(1).(usecase.Operation))
+		}).
+		Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.On("PostEntry", ctx, mock.Anything).Return(nil)
+
+	u := usecase.New(repo, txm)
+	results, err := u.ExecuteBatch(ctx, model.BatchInput{
+		Atomic: true,
+		Operations: []model.BatchOperation{
+			{WalletID: a, Type: "DEPOSIT", Amount: 30},
+			{WalletID: b, Type: "DEPOSIT", Amount: 10},
+			// Списание видит зачисление из того же пакета: 100 + 30 - 20 холд.
+			{WalletID: a, Type: "WITHDRAW", Amount: 110},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, int64(130), results[0].Receipt.BalanceAfter)
+	assert.Equal(t, int64(60), results[1].Receipt.BalanceAfter)
+	assert.Equal(t, int64(20), results[2].Receipt.BalanceAfter)
+	require.Len(t, saved, 3)
+	assert.Equal(t, int64(130), saved[2].BalanceBefore)
+	repo.AssertExpectations(t)
+	txm.AssertNumberOfCalls(t, "RunInTx", 1)
+}
+
+func TestUsecase_ExecuteBatch_AtomicFailureReportsIndex(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(100), nil)
+	repo.On("GetHeldAmount", ctx, walletID).Return(int64(0), nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.On("PostEntry", ctx, mock.Anything).Return(nil)
+
+	u := usecase.New(repo, txm)
+	results, err := u.ExecuteBatch(ctx, model.BatchInput{
+		Atomic: true,
+		Operations: []model.BatchOperation{
+			{WalletID: walletID, Type: "WITHDRAW", Amount: 60},
+			{WalletID: walletID, Type: "WITHDRAW", Amount: 60},
+			{WalletID: walletID, Type: "DEPOSIT", Amount: 10},
+		},
+	})
+
+	var itemErr *walleterror.BatchItemError
+	require.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+	assert.Nil(t, results)
+	// Следующие операции не выполняются.
+	repo.AssertNumberOfCalls(t, "SaveOperation", 1)
+}
+
+func TestUsecase_ExecuteBatch_AtomicWalletNotFound(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	a := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	missing := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, missing).Return(int64(0), walleterror.ErrWalletNotFound)
+
+	u := usecase.New(repo, txm)
+	_, err := u.ExecuteBatch(ctx, model.BatchInput{
+		Atomic: true,
+		Operations: []model.BatchOperation{
+			{WalletID: a, Type: "DEPOSIT", Amount: 1},
+			{WalletID: missing, Type: "DEPOSIT", Amount: 1},
+			{WalletID: missing, Type: "WITHDRAW", Amount: 1},
+		},
+	})
+
+	var itemErr *walleterror.BatchItemError
+	require.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Index)
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	repo.AssertNotCalled(t, "SaveOperation", mock.Anything, mock.Anything)
+}
+
+func TestUsecase_ExecuteBatch_NonAtomicPerItem(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	ok := testUUID()
+	missing := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, ok).Return(int64(100), nil)
+	repo.On("GetBalanceForUpdate", ctx, missing).Return(int64(0), walleterror.ErrWalletNotFound)
+	repo.On("GetHeldAmount", ctx, ok).Return(int64(0), nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.On("PostEntry", ctx, mock.Anything).Return(nil)
+
+	u := usecase.New(repo, txm)
+	results, err := u.ExecuteBatch(ctx, model.BatchInput{
+		Operations: []model.BatchOperation{
+			{WalletID: ok, Type: "DEPOSIT", Amount: 10},
+			{WalletID: missing, Type: "DEPOSIT", Amount: 10},
+			{WalletID: ok, Type: "WITHDRAW", Amount: 1000},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, int64(110), results[0].Receipt.BalanceAfter)
+	assert.ErrorIs(t, results[1].Err, walleterror.ErrWalletNotFound)
+	assert.ErrorIs(t, results[2].Err, walleterror.ErrInsufficientFunds)
+	// Каждая операция - в своей транзакции.
+	txm.AssertNumberOfCalls(t, "RunInTx", 3)
+}
+
+func TestUsecase_ExecuteBatch_InvalidSize(t *testing.T) {
+	u := usecase.New(new(mocks.WalletRepository), new(mocks.TxManager))
+
+	_, err := u.ExecuteBatch(context.Background(), model.BatchInput{Atomic: true})
+	assert.ErrorIs(t, err, walleterror.ErrInvalidBatchSize)
+
+	_, err = u.ExecuteBatch(context.Background(), model.BatchInput{
+		Operations: make([]model.BatchOperation, usecase.MaxBatchSize+1),
+	})
+	assert.ErrorIs(t, err, walleterror.ErrInvalidBatchSize)
+}
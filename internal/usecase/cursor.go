@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+)
+
+// encodeCursor ...
+func encodeCursor(c model.OperationCursor) string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(c.Seq, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor ...
+func decodeCursor(s string) (model.OperationCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return model.OperationCursor{}, walleterror.ErrInvalidCursor
+	}
+
+	tsStr, seqStr, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return model.OperationCursor{}, walleterror.ErrInvalidCursor
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, tsStr)
+	if err != nil {
+		return model.OperationCursor{}, walleterror.ErrInvalidCursor
+	}
+
+	seq, err := strconv.ParseInt(seqStr, 10, 64)
+	if err != nil {
+		return model.OperationCursor{}, walleterror.ErrInvalidCursor
+	}
+
+	return model.OperationCursor{CreatedAt: ts.UTC(), Seq: seq}, nil
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultGroupCommitWindow ...
+	DefaultGroupCommitWindow = 2 * time.Millisecond
+	// DefaultGroupCommitMaxSize ...
+	DefaultGroupCommitMaxSize = 100
+)
+
+// GroupCommit собирает пополнения и списания, пришедшие на один кошелёк в
+// течение окна, и проводит их одной транзакцией: кошелёк блокируется один
+// раз, а не на каждый запрос. Операции применяются в порядке поступления,
+// списание, которому не хватает средств, отклоняется отдельно и не мешает
+// остальным. Прочие методы выполняются WalletUsecase как есть.
+type GroupCommit struct {
+	*WalletUsecase
+
+	window  time.Duration
+	maxSize int
+
+	mu     sync.Mutex
+	groups map[uuid.UUID]*commitGroup
+}
+
+// GroupCommitOption ...
+type GroupCommitOption func(*GroupCommit)
+
+// WithGroupCommitWindow сколько ждать попутные операции после первой.
+func WithGroupCommitWindow(d time.Duration) GroupCommitOption {
+	return func(g *GroupCommit) {
+		if d > 0 {
+			g.window = d
+		}
+	}
+}
+
+// WithGroupCommitMaxSize после стольких операций группа проводится, не
+// дожидаясь конца окна.
+func WithGroupCommitMaxSize(n int) GroupCommitOption {
+	return func(g *GroupCommit) {
+		if n > 0 {
+			g.maxSize = n
+		}
+	}
+}
+
+// NewGroupCommit ...
+func NewGroupCommit(u *WalletUsecase, opts ...GroupCommitOption) *GroupCommit {
+	g := &GroupCommit{
+		WalletUsecase: u,
+		window:        DefaultGroupCommitWindow,
+		maxSize:       DefaultGroupCommitMaxSize,
+		groups:        make(map[uuid.UUID]*commitGroup),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+type commitGroup struct {
+	requests []*commitRequest
+	// full закрывается, когда группа набрала maxSize операций.
+	full chan struct{}
+}
+
+type commitRequest struct {
+	// ctx запрос, отменённый до проведения группы, в неё не попадает.
+	ctx    context.Context
+	opType string
+	amount int64
+	done   chan commitResult
+}
+
+type commitResult struct {
+	receipt model.Receipt
+	err     error
+}
+
+// Deposit ...
+func (g *GroupCommit) Deposit(ctx context.Context, in model.DepositInput) (model.Receipt, error) {
+	// Ключ идемпотентности захватывается в транзакции операции: такие
+	// запросы идут мимо группы.
+	if in.IdempotencyKey != "" {
+		return g.WalletUsecase.Deposit(ctx, in)
+	}
+
+	receipt, err := g.submit(ctx, in.WalletID, "DEPOSIT", in.Amount)
+	g.observeOperation("DEPOSIT", err)
+	return receipt, err
+}
+
+// Withdraw ...
+func (g *GroupCommit) Withdraw(ctx context.Context, in model.WithdrawInput) (model.Receipt, error) {
+	if in.IdempotencyKey != "" {
+		return g.WalletUsecase.Withdraw(ctx, in)
+	}
+
+	receipt, err := g.submit(ctx, in.WalletID, "WITHDRAW", in.Amount)
+	g.observeOperation("WITHDRAW", err)
+	return receipt, err
+}
+
+// submit ставит операцию в группу кошелька и ждёт её результат. Первая
+// операция группы запускает её проведение.
+func (g *GroupCommit) submit(ctx context.Context, walletID uuid.UUID, opType string, amount int64) (model.Receipt, error) {
+	req := &commitRequest{ctx: ctx, opType: opType, amount: amount, done: make(chan commitResult, 1)}
+
+	g.mu.Lock()
+	grp, ok := g.groups[walletID]
+	if !ok {
+		grp = &commitGroup{full: make(chan struct{})}
+		g.groups[walletID] = grp
+		// Группа проводится без отмены: отказ первого клиента не должен
+		// отменять операции остальных.
+		go g.flush(context.WithoutCancel(ctx), walletID, grp)
+	}
+	grp.requests = append(grp.requests, req)
+	if len(grp.requests) >= g.maxSize {
+		delete(g.groups, walletID)
+		close(grp.full)
+	}
+	g.mu.Unlock()
+
+	// Результат ждём и после отмены ctx: если группа уже проводится,
+	// операция будет записана, и клиент должен узнать об этом, а не
+	// повторить её.
+	res := <-req.done
+	return res.receipt, res.err
+}
+
+func (g *GroupCommit) flush(ctx context.Context, walletID uuid.UUID, grp *commitGroup) {
+	timer := time.NewTimer(g.window)
+	select {
+	case <-timer.C:
+	case <-grp.full:
+		timer.Stop()
+	}
+
+	// После этого новые операции кошелька попадают в следующую группу.
+	g.mu.Lock()
+	if g.groups[walletID] == grp {
+		delete(g.groups, walletID)
+	}
+	queued := grp.requests
+	g.mu.Unlock()
+
+	// Отменённые к этому моменту операции не проводятся.
+	requests := make([]*commitRequest, 0, len(queued))
+	for _, req := range queued {
+		if err := req.ctx.Err(); err != nil {
+			req.done <- commitResult{err: err}
+			continue
+		}
+		requests = append(requests, req)
+	}
+	if len(requests) == 0 {
+		return
+	}
+
+	results := g.commit(ctx, walletID, requests)
+	for i, req := range requests {
+		req.done <- results[i]
+	}
+}
+
+// commit проводит операции группы одной транзакцией. Ошибка транзакции
+// достаётся всем операциям группы.
+func (g *GroupCommit) commit(ctx context.Context, walletID uuid.UUID, requests []*commitRequest) []commitResult {
+	var results []commitResult
+	err := g.txm.RunInTx(ctx, func(ctx context.Context) error {
+		// TxManager может повторить замыкание: результаты собираются заново.
+		results = make([]commitResult, len(requests))
+
+		balance, err := g.repo.GetBalanceForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+
+		// Холды кошелька не меняются, пока он заблокирован: читаем их один раз.
+		var (
+			held       int64
+			heldLoaded bool
+		)
+		for i, req := range requests {
+			var op Operation
+			switch req.opType {
+			case "DEPOSIT":
+				op, err = g.deposit(ctx, walletID, req.amount, balance)
+			case "WITHDRAW":
+				if !heldLoaded {
+					held, err = g.repo.GetHeldAmount(ctx, walletID)
+					if err != nil {
+						return err
+					}
+					heldLoaded = true
+				}
+				// Списание, уводящее в минус, отклоняется до записи: транзакция
+				// и остальные операции группы его не замечают.
+				if balance-held < req.amount {
+					results[i] = commitResult{err: walleterror.ErrInsufficientFunds}
+					continue
+				}
+				op, err = g.withdraw(ctx, walletID, req.amount, balance, held)
+			}
+			if err != nil {
+				return err
+			}
+
+			balance = op.BalanceAfter
+			results[i] = commitResult{receipt: model.Receipt{Operation: op, BalanceAfter: op.BalanceAfter}}
+		}
+
+		return nil
+	})
+	if err != nil {
+		results = make([]commitResult, len(requests))
+		for i := range results {
+			results[i] = commitResult{err: err}
+		}
+	}
+
+	return results
+}
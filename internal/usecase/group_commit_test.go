@@ -0,0 +1,221 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupGroupRepo настраивает репозиторий для проведения групп: балансом
+// кошелька управляет GetBalanceForUpdate, запись всегда успешна.
+func setupGroupRepo(repo *mocks.WalletRepository, balance, held int64) {
+	repo.On("GetBalanceForUpdate", mock.Anything, testUUID()).Return(balance, nil)
+	repo.On("GetHeldAmount", mock.Anything, testUUID()).Return(held, nil)
+	repo.On("SaveOperation", mock.Anything, mock.Anything).Return(time.Time{}, nil)
+	repo.On("SaveEvent", mock.Anything, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", mock.Anything, mock.Anything).Return(nil)
+	repo.On("PostEntry", mock.Anything, mock.Anything).Return(nil)
+}
+
+// submitAll запускает операции одновременно и собирает их результаты.
+func submitAll(g *usecase.GroupCommit, ops []model.BatchOperation) ([]model.Receipt, []error) {
+	receipts := make([]model.Receipt, len(ops))
+	errs := make([]error, len(ops))
+
+	var wg sync.WaitGroup
+	for i, op := range ops {
+		wg.Go(func() {
+			if op.Type == "DEPOSIT" {
+				receipts[i], errs[i] = g.Deposit(context.Background(), model.DepositInput{WalletID: op.WalletID, Amount: op.Amount})
+			} else {
+				receipts[i], errs[i] = g.Withdraw(context.Background(), model.WithdrawInput{WalletID: op.WalletID, Amount: op.Amount})
+			}
+		})
+	}
+	wg.Wait()
+
+	return receipts, errs
+}
+
+// --- GroupCommit ---
+
+func TestGroupCommit_CoalescesConcurrentOperations(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 100, 0)
+
+	g := usecase.NewGroupCommit(usecase.New(repo, txm), usecase.WithGroupCommitWindow(100*time.Millisecond))
+
+	ops := make([]model.BatchOperation, 10)
+	for i := range ops {
+		ops[i] = model.BatchOperation{WalletID: testUUID(), Type: "DEPOSIT", Amount: 1}
+	}
+	receipts, errs := submitAll(g, ops)
+
+	balances := make(map[int64]bool)
+	for i := range ops {
+		require.NoError(t, errs[i])
+		balances[receipts[i].BalanceAfter] = true
+	}
+	// Каждый получил свой результат: балансы после 101..110 без повторов.
+	assert.Len(t, balances, 10)
+	assert.True(t, balances[101])
+	assert.True(t, balances[110])
+	txm.AssertNumberOfCalls(t, "RunInTx", 1)
+	repo.AssertNumberOfCalls(t, "GetBalanceForUpdate", 1)
+	repo.AssertNumberOfCalls(t, "SaveOperation", 10)
+}
+
+func TestGroupCommit_RejectsOverdrawingWithdrawal(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	setupTxManager(txm)
+	// Доступно 12 - 2 = 10: из трёх списаний по 4 проходят два.
+	setupGroupRepo(repo, 12, 2)
+
+	g := usecase.NewGroupCommit(usecase.New(repo, txm), usecase.WithGroupCommitWindow(100*time.Millisecond))
+
+	op := model.BatchOperation{WalletID: testUUID(), Type: "WITHDRAW", Amount: 4}
+	receipts, errs := submitAll(g, []model.BatchOperation{op, op, op})
+
+	var ok, rejected int
+	for i := range errs {
+		if errs[i] == nil {
+			ok++
+			assert.Contains(t, []int64{8, 4}, receipts[i].BalanceAfter)
+			continue
+		}
+		assert.ErrorIs(t, errs[i], walleterror.ErrInsufficientFunds)
+		rejected++
+	}
+	assert.Equal(t, 2, ok)
+	assert.Equal(t, 1, rejected)
+	txm.AssertNumberOfCalls(t, "RunInTx", 1)
+	repo.AssertNumberOfCalls(t, "GetHeldAmount", 1)
+	repo.AssertNumberOfCalls(t, "SaveOperation", 2)
+}
+
+func TestGroupCommit_TxErrorFailsWholeGroup(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", mock.Anything, testUUID()).Return(int64(0), walleterror.ErrWalletNotFound)
+
+	g := usecase.NewGroupCommit(usecase.New(repo, txm), usecase.WithGroupCommitWindow(100*time.Millisecond))
+
+	_, errs := submitAll(g, []model.BatchOperation{
+		{WalletID: testUUID(), Type: "DEPOSIT", Amount: 1},
+		{WalletID: testUUID(), Type: "WITHDRAW", Amount: 1},
+	})
+
+	for _, err := range errs {
+		assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	}
+	repo.AssertNotCalled(t, "SaveOperation", mock.Anything, mock.Anything)
+}
+
+func TestGroupCommit_FlushesWhenFull(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 0, 0)
+
+	// Окно не успеет закончиться: группу проводит заполнение.
+	g := usecase.NewGroupCommit(usecase.New(repo, txm),
+		usecase.WithGroupCommitWindow(time.Hour),
+		usecase.WithGroupCommitMaxSize(3),
+	)
+
+	op := model.BatchOperation{WalletID: testUUID(), Type: "DEPOSIT", Amount: 1}
+	_, errs := submitAll(g, []model.BatchOperation{op, op, op})
+
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	txm.AssertNumberOfCalls(t, "RunInTx", 1)
+}
+
+func TestGroupCommit_CancelledBeforeFlushIsDropped(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 100, 0)
+
+	g := usecase.NewGroupCommit(usecase.New(repo, txm), usecase.WithGroupCommitWindow(50*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var (
+		wg           sync.WaitGroup
+		cancelledErr error
+		receipt      model.Receipt
+		err          error
+	)
+	wg.Go(func() {
+		_, cancelledErr = g.Deposit(ctx, model.DepositInput{WalletID: testUUID(), Amount: 5})
+	})
+	wg.Go(func() {
+		receipt, err = g.Deposit(context.Background(), model.DepositInput{WalletID: testUUID(), Amount: 1})
+	})
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	require.ErrorIs(t, cancelledErr, context.Canceled)
+	require.NoError(t, err)
+	assert.Equal(t, int64(101), receipt.BalanceAfter)
+	repo.AssertNumberOfCalls(t, "SaveOperation", 1)
+}
+
+func TestGroupCommit_CancelledDuringCommitGetsResult(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	setupTxManager(txm)
+	// Клиент отменяет запрос, когда группа уже проводится.
+	repo.On("GetBalanceForUpdate", mock.Anything, testUUID()).Run(func(mock.Arguments) { cancel() }).Return(int64(100), nil)
+	setupGroupRepo(repo, 100, 0)
+
+	g := usecase.NewGroupCommit(usecase.New(repo, txm), usecase.WithGroupCommitWindow(time.Millisecond))
+
+	receipt, err := g.Deposit(ctx, model.DepositInput{WalletID: testUUID(), Amount: 5})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(105), receipt.BalanceAfter)
+	repo.AssertNumberOfCalls(t, "SaveOperation", 1)
+}
+
+func TestGroupCommit_IdempotentRequestBypassesGroup(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 100, 0)
+	repo.On("ClaimIdempotencyKey", ctx, "key-1", mock.Anything, mock.Anything).Return(model.IdempotencyRecord{}, false, nil)
+	repo.On("SaveIdempotencyResponse", ctx, "key-1", mock.Anything).Return(nil)
+
+	// С часовым окном группа ответила бы только через час.
+	g := usecase.NewGroupCommit(usecase.New(repo, txm), usecase.WithGroupCommitWindow(time.Hour))
+	receipt, err := g.Deposit(ctx, model.DepositInput{WalletID: testUUID(), Amount: 5, IdempotencyKey: "key-1"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(105), receipt.BalanceAfter)
+}
@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultHoldTTL ...
+	DefaultHoldTTL = 15 * time.Minute
+	// MaxHoldTTL ...
+	MaxHoldTTL = 7 * 24 * time.Hour
+)
+
+// WithHoldTTL ...
+func WithHoldTTL(ttl time.Duration) Option {
+	return func(u *WalletUsecase) {
+		if ttl > 0 && ttl <= MaxHoldTTL {
+			u.holdTTL = ttl
+		}
+	}
+}
+
+// CreateHold резервирует сумму на кошельке. Баланс не меняется, уменьшается
+// только доступный баланс.
+func (u *WalletUsecase) CreateHold(ctx context.Context, in model.CreateHoldInput) (model.Hold, error) {
+	ttl := in.TTL
+	if ttl == 0 {
+		ttl = u.holdTTL
+	}
+	if ttl < 0 || ttl > MaxHoldTTL {
+		return model.Hold{}, walleterror.ErrInvalidHoldTTL
+	}
+
+	var hold model.Hold
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		available, err := u.availableForUpdate(ctx, in.WalletID)
+		if err != nil {
+			return err
+		}
+
+		if available < in.Amount {
+			return walleterror.ErrInsufficientFunds
+		}
+
+		hold, err = u.repo.CreateHold(ctx, model.Hold{
+			ID:       uuid.New(),
+			WalletID: in.WalletID,
+			Amount:   in.Amount,
+		}, ttl)
+		return err
+	})
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// CaptureHold списывает весь холд или его часть операцией WITHDRAW.
+// Несписанный остаток освобождается, холд закрывается.
+func (u *WalletUsecase) CaptureHold(ctx context.Context, in model.CaptureHoldInput) (model.Receipt, error) {
+	var receipt model.Receipt
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		// Кошелёк блокируется раньше холда в том же порядке, что и в
+		// Withdraw, чтобы не получить дедлок.
+		balance, err := u.repo.GetBalanceForUpdate(ctx, in.WalletID)
+		if err != nil {
+			return err
+		}
+
+		hold, err := u.activeHoldForUpdate(ctx, in.WalletID, in.HoldID)
+		if err != nil {
+			return err
+		}
+
+		amount := in.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return walleterror.ErrCaptureExceedsHold
+		}
+
+		op := Operation{
+			ID:       uuid.New(),
+			WalletID: in.WalletID,
+			Type:     "WITHDRAW",
+			Amount:   amount,
+
+			BalanceBefore: balance,
+			BalanceAfter:  balance - amount,
+		}
+		op, err = u.saveOperation(ctx, op)
+		if err != nil {
+			return err
+		}
+
+		err = u.postEntry(ctx, op.ID, "WITHDRAW",
+			model.Posting{AccountID: in.WalletID, Amount: -amount},
+			model.Posting{AccountID: model.ExternalFundingAccountID, Amount: amount},
+		)
+		if err != nil {
+			return err
+		}
+
+		hold.Status = model.HoldCaptured
+		hold.CapturedAmount = amount
+		hold.OperationID = uuid.NullUUID{UUID: op.ID, Valid: true}
+		if err := u.repo.UpdateHold(ctx, hold); err != nil {
+			return err
+		}
+
+		receipt = model.Receipt{Operation: op, BalanceAfter: op.BalanceAfter}
+		return nil
+	})
+	u.observeOperation("WITHDRAW", err)
+	if err != nil {
+		return model.Receipt{}, err
+	}
+
+	return receipt, nil
+}
+
+// VoidHold освобождает холд без списания.
+func (u *WalletUsecase) VoidHold(ctx context.Context, in model.VoidHoldInput) (model.Hold, error) {
+	var hold model.Hold
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		hold, err = u.activeHoldForUpdate(ctx, in.WalletID, in.HoldID)
+		if err != nil {
+			return err
+		}
+
+		hold.Status = model.HoldVoided
+		return u.repo.UpdateHold(ctx, hold)
+	})
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// activeHoldForUpdate блокирует холд и проверяет, что он принадлежит
+// кошельку и ещё активен.
+func (u *WalletUsecase) activeHoldForUpdate(ctx context.Context, walletID, holdID uuid.UUID) (model.Hold, error) {
+	hold, err := u.repo.GetHoldForUpdate(ctx, holdID)
+	if err != nil {
+		return model.Hold{}, err
+	}
+
+	if hold.WalletID != walletID {
+		return model.Hold{}, walleterror.ErrHoldNotFound
+	}
+	if hold.Status != model.HoldActive {
+		return model.Hold{}, walleterror.ErrHoldNotActive
+	}
+
+	return hold, nil
+}
+
+// availableForUpdate блокирует кошелёк и возвращает баланс за вычетом
+// активных холдов.
+func (u *WalletUsecase) availableForUpdate(ctx context.Context, walletID uuid.UUID) (int64, error) {
+	balance, err := u.repo.GetBalanceForUpdate(ctx, walletID)
+	if err != nil {
+		return 0, err
+	}
+
+	held, err := u.repo.GetHeldAmount(ctx, walletID)
+	if err != nil {
+		return 0, err
+	}
+
+	return balance - held, nil
+}
@@ -0,0 +1,234 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func activeHold(walletID uuid.UUID, amount int64) model.Hold {
+	return model.Hold{
+		ID:        uuid.New(),
+		WalletID:  walletID,
+		Amount:    amount,
+		Status:    model.HoldActive,
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+}
+
+// --- CreateHold ---
+
+func TestUsecase_CreateHold_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(1000), nil)
+	repo.On("GetHeldAmount", ctx, walletID).Return(int64(400), nil)
+	repo.
+		On("CreateHold", ctx, mock.MatchedBy(func(h model.Hold) bool {
+			return h.WalletID == walletID && h.Amount == 600 && h.ID != uuid.Nil
+		}), usecase.DefaultHoldTTL).
+		Return(activeHold(walletID, 600), nil)
+
+	u := usecase.New(repo, txm)
+	hold, err := u.CreateHold(ctx, model.CreateHoldInput{WalletID: walletID, Amount: 600})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.HoldActive, hold.Status)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_CreateHold_InsufficientAvailable(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(1000), nil)
+	repo.On("GetHeldAmount", ctx, walletID).Return(int64(400), nil)
+
+	u := usecase.New(repo, txm)
+	_, err := u.CreateHold(ctx, model.CreateHoldInput{WalletID: walletID, Amount: 601})
+
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+	repo.AssertNotCalled(t, "CreateHold")
+}
+
+func TestUsecase_CreateHold_InvalidTTL(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	u := usecase.New(repo, txm)
+	_, err := u.CreateHold(context.Background(), model.CreateHoldInput{
+		WalletID: testUUID(),
+		Amount:   1,
+		TTL:      usecase.MaxHoldTTL + time.Second,
+	})
+
+	require.ErrorIs(t, err, walleterror.ErrInvalidHoldTTL)
+	txm.AssertNotCalled(t, "RunInTx")
+}
+
+// --- CaptureHold ---
+
+func TestUsecase_CaptureHold_Partial(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	hold := activeHold(walletID, 500)
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(1000), nil)
+	repo.On("GetHoldForUpdate", ctx, hold.ID).Return(hold, nil)
+	repo.
+		On("SaveOperation", ctx, mock.MatchedBy(func(op usecase.Operation) bool {
+			return op.Type == "WITHDRAW" && op.Amount == 200 && op.WalletID == walletID
+		})).
+		Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.On("PostEntry", ctx, entryPosting(walletID, -200)).Return(nil)
+	repo.
+		On("UpdateHold", ctx, mock.MatchedBy(func(h model.Hold) bool {
+			return h.ID == hold.ID &&
+				h.Status == model.HoldCaptured &&
+				h.CapturedAmount == 200 &&
+				h.OperationID.Valid
+		})).
+		Return(nil)
+
+	u := usecase.New(repo, txm)
+	receipt, err := u.CaptureHold(ctx, model.CaptureHoldInput{WalletID: walletID, HoldID: hold.ID, Amount: 200})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(800), receipt.BalanceAfter)
+	assert.Equal(t, "WITHDRAW", receipt.Operation.Type)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_CaptureHold_DefaultsToFullAmount(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	hold := activeHold(walletID, 500)
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(1000), nil)
+	repo.On("GetHoldForUpdate", ctx, hold.ID).Return(hold, nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.On("PostEntry", ctx, entryPosting(walletID, -500)).Return(nil)
+	repo.On("UpdateHold", ctx, mock.Anything).Return(nil)
+
+	u := usecase.New(repo, txm)
+	receipt, err := u.CaptureHold(ctx, model.CaptureHoldInput{WalletID: walletID, HoldID: hold.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), receipt.Operation.Amount)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_CaptureHold_Rejected(t *testing.T) {
+	walletID := testUUID()
+
+	expired := activeHold(walletID, 500)
+	expired.Status = model.HoldExpired
+
+	foreign := activeHold(uuid.New(), 500)
+
+	cases := []struct {
+		name   string
+		hold   model.Hold
+		amount int64
+		err    error
+	}{
+		{name: "expired", hold: expired, err: walleterror.ErrHoldNotActive},
+		{name: "other wallet", hold: foreign, err: walleterror.ErrHoldNotFound},
+		{name: "exceeds hold", hold: activeHold(walletID, 500), amount: 501, err: walleterror.ErrCaptureExceedsHold},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(mocks.WalletRepository)
+			txm := new(mocks.TxManager)
+			ctx := context.Background()
+
+			setupTxManager(txm)
+			repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(1000), nil)
+			repo.On("GetHoldForUpdate", ctx, tc.hold.ID).Return(tc.hold, nil)
+
+			u := usecase.New(repo, txm)
+			_, err := u.CaptureHold(ctx, model.CaptureHoldInput{WalletID: walletID, HoldID: tc.hold.ID, Amount: tc.amount})
+
+			require.ErrorIs(t, err, tc.err)
+			repo.AssertNotCalled(t, "SaveOperation")
+			repo.AssertNotCalled(t, "UpdateHold")
+		})
+	}
+}
+
+// --- VoidHold ---
+
+func TestUsecase_VoidHold_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	hold := activeHold(walletID, 500)
+
+	setupTxManager(txm)
+	repo.On("GetHoldForUpdate", ctx, hold.ID).Return(hold, nil)
+	repo.
+		On("UpdateHold", ctx, mock.MatchedBy(func(h model.Hold) bool {
+			return h.ID == hold.ID && h.Status == model.HoldVoided && h.CapturedAmount == 0
+		})).
+		Return(nil)
+
+	u := usecase.New(repo, txm)
+	voided, err := u.VoidHold(ctx, model.VoidHoldInput{WalletID: walletID, HoldID: hold.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.HoldVoided, voided.Status)
+	repo.AssertNotCalled(t, "SaveOperation")
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_VoidHold_AlreadyCaptured(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	hold := activeHold(walletID, 500)
+	hold.Status = model.HoldCaptured
+
+	setupTxManager(txm)
+	repo.On("GetHoldForUpdate", ctx, hold.ID).Return(hold, nil)
+
+	u := usecase.New(repo, txm)
+	_, err := u.VoidHold(ctx, model.VoidHoldInput{WalletID: walletID, HoldID: hold.ID})
+
+	require.ErrorIs(t, err, walleterror.ErrHoldNotActive)
+	repo.AssertNotCalled(t, "UpdateHold")
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	walleterror "wallet/internal/error"
+
+	"github.com/google/uuid"
+)
+
+// fingerprint ...
+func fingerprint(opType string, walletID uuid.UUID, amount int64) string {
+	sum := sha256.Sum256([]byte(opType + "|" + walletID.String() + "|" + strconv.FormatInt(amount, 10)))
+	return hex.EncodeToString(sum[:])
+}
+
+// claimIdempotencyKey резервирует ключ в текущей транзакции. Если ключ уже
+// использовался тем же запросом, сохранённый ответ раскладывается в replay,
+// возвращается replayed = true и операцию выполнять не нужно.
+func (u *WalletUsecase) claimIdempotencyKey(ctx context.Context, key, fp string, replay any) (bool, error) {
+	if key == "" {
+		return false, nil
+	}
+
+	rec, found, err := u.repo.ClaimIdempotencyKey(ctx, key, fp, u.idempotencyTTL)
+	if err != nil {
+		return false, err
+	}
+	if !found {
+		return false, nil
+	}
+
+	if rec.Fingerprint != fp {
+		return false, walleterror.ErrIdempotencyKeyReused
+	}
+
+	if err := json.Unmarshal(rec.Response, replay); err != nil {
+		return false, fmt.Errorf("unmarshal idempotency response: %w", err)
+	}
+
+	return true, nil
+}
+
+// saveIdempotencyResponse ...
+func (u *WalletUsecase) saveIdempotencyResponse(ctx context.Context, key string, response any) error {
+	if key == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("marshal idempotency response: %w", err)
+	}
+
+	return u.repo.SaveIdempotencyResponse(ctx, key, data)
+}
+
+// DefaultIdempotencyCleanupBatchSize ...
+const DefaultIdempotencyCleanupBatchSize = 1000
+
+// IdempotencyRepository ...
+type IdempotencyRepository interface {
+	// DeleteExpiredIdempotencyKeys удаляет до limit просроченных ключей и
+	// возвращает их число.
+	DeleteExpiredIdempotencyKeys(ctx context.Context, limit int) (int, error)
+}
+
+// IdempotencyCleanup удаляет просроченные ключи идемпотентности. Claim
+// перезаписывает просроченный ключ только при его повторном использовании,
+// поэтому без чистки таблица растёт бесконечно.
+type IdempotencyCleanup struct {
+	repo      IdempotencyRepository
+	batchSize int
+}
+
+// NewIdempotencyCleanup ...
+func NewIdempotencyCleanup(repo IdempotencyRepository, batchSize int) *IdempotencyCleanup {
+	if batchSize <= 0 {
+		batchSize = DefaultIdempotencyCleanupBatchSize
+	}
+	return &IdempotencyCleanup{repo: repo, batchSize: batchSize}
+}
+
+// BatchSize ...
+func (c *IdempotencyCleanup) BatchSize() int {
+	return c.batchSize
+}
+
+// Purge удаляет одну пачку просроченных ключей и возвращает её размер.
+// Короткие пачки не держат блокировки таблицы подолгу.
+func (c *IdempotencyCleanup) Purge(ctx context.Context) (int, error) {
+	n, err := c.repo.DeleteExpiredIdempotencyKeys(ctx, c.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return n, nil
+}
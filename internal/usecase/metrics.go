@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"errors"
+	walleterror "wallet/internal/error"
+)
+
+// Исходы операций для OperationMetrics.
+const (
+	OutcomeSuccess           = "success"
+	OutcomeInsufficientFunds = "insufficient_funds"
+	OutcomeError             = "error"
+)
+
+// OperationMetrics считает пополнения и списания по исходам.
+type OperationMetrics interface {
+	ObserveOperation(opType, outcome string)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveOperation(string, string) {}
+
+// WithMetrics ...
+func WithMetrics(m OperationMetrics) Option {
+	return func(u *WalletUsecase) {
+		if m != nil {
+			u.metrics = m
+		}
+	}
+}
+
+// observeOperation записывает исход операции opType по её ошибке.
+func (u *WalletUsecase) observeOperation(opType string, err error) {
+	outcome := OutcomeSuccess
+	switch {
+	case errors.Is(err, walleterror.ErrInsufficientFunds):
+		outcome = OutcomeInsufficientFunds
+	case err != nil:
+		outcome = OutcomeError
+	}
+	u.metrics.ObserveOperation(opType, outcome)
+}
@@ -0,0 +1,150 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingMetrics считает операции по "тип/исход".
+type recordingMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counts: make(map[string]int)}
+}
+
+func (m *recordingMetrics) ObserveOperation(opType, outcome string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[opType+"/"+outcome]++
+}
+
+// --- Metrics ---
+
+func TestUsecase_Metrics_OperationOutcomes(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	missingID := walletID
+	missingID[0] = 0x22
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 100, 0)
+	repo.On("GetBalanceForUpdate", ctx, missingID).Return(int64(0), walleterror.ErrWalletNotFound)
+
+	m := newRecordingMetrics()
+	u := usecase.New(repo, txm, usecase.WithMetrics(m))
+
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 10})
+	require.NoError(t, err)
+	_, err = u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 1000})
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+	_, err = u.Withdraw(ctx, model.WithdrawInput{WalletID: missingID, Amount: 1})
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+
+	assert.Equal(t, map[string]int{
+		"DEPOSIT/" + usecase.OutcomeSuccess:            1,
+		"WITHDRAW/" + usecase.OutcomeInsufficientFunds: 1,
+		"WITHDRAW/" + usecase.OutcomeError:             1,
+	}, m.counts)
+}
+
+func TestGroupCommit_Metrics_CountsEachOperation(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 12, 2)
+
+	m := newRecordingMetrics()
+	g := usecase.NewGroupCommit(usecase.New(repo, txm, usecase.WithMetrics(m)),
+		usecase.WithGroupCommitWindow(100*time.Millisecond),
+	)
+
+	op := model.BatchOperation{WalletID: testUUID(), Type: "WITHDRAW", Amount: 4}
+	submitAll(g, []model.BatchOperation{op, op, op})
+
+	assert.Equal(t, map[string]int{
+		"WITHDRAW/" + usecase.OutcomeSuccess:           2,
+		"WITHDRAW/" + usecase.OutcomeInsufficientFunds: 1,
+	}, m.counts)
+}
+
+func TestUsecase_Metrics_AtomicBatch(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 100, 0)
+
+	m := newRecordingMetrics()
+	u := usecase.New(repo, txm, usecase.WithMetrics(m))
+
+	_, err := u.ExecuteBatch(ctx, model.BatchInput{
+		Atomic: true,
+		Operations: []model.BatchOperation{
+			{WalletID: testUUID(), Type: "DEPOSIT", Amount: 10},
+			{WalletID: testUUID(), Type: "WITHDRAW", Amount: 20},
+		},
+	})
+	require.NoError(t, err)
+
+	// Пакет откатывается на второй операции: записывается только её исход.
+	_, err = u.ExecuteBatch(ctx, model.BatchInput{
+		Atomic: true,
+		Operations: []model.BatchOperation{
+			{WalletID: testUUID(), Type: "DEPOSIT", Amount: 10},
+			{WalletID: testUUID(), Type: "WITHDRAW", Amount: 1000},
+			{WalletID: testUUID(), Type: "DEPOSIT", Amount: 10},
+		},
+	})
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+
+	assert.Equal(t, map[string]int{
+		"DEPOSIT/" + usecase.OutcomeSuccess:            1,
+		"WITHDRAW/" + usecase.OutcomeSuccess:           1,
+		"WITHDRAW/" + usecase.OutcomeInsufficientFunds: 1,
+	}, m.counts)
+}
+
+func TestUsecase_Metrics_CaptureHold(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	hold := activeHold(walletID, 50)
+
+	setupTxManager(txm)
+	setupGroupRepo(repo, 100, 0)
+	repo.On("GetHoldForUpdate", ctx, hold.ID).Return(hold, nil)
+	repo.On("UpdateHold", ctx, mock.Anything).Return(nil)
+
+	m := newRecordingMetrics()
+	u := usecase.New(repo, txm, usecase.WithMetrics(m))
+
+	_, err := u.CaptureHold(ctx, model.CaptureHoldInput{WalletID: walletID, HoldID: hold.ID, Amount: 60})
+	require.ErrorIs(t, err, walleterror.ErrCaptureExceedsHold)
+	_, err = u.CaptureHold(ctx, model.CaptureHoldInput{WalletID: walletID, HoldID: hold.ID, Amount: 30})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		"WITHDRAW/" + usecase.OutcomeSuccess: 1,
+		"WITHDRAW/" + usecase.OutcomeError:   1,
+	}, m.counts)
+}
@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"wallet/internal/model"
+)
+
+const (
+	// DefaultOutboxBatchSize ...
+	DefaultOutboxBatchSize = 100
+	// DefaultOutboxMinBackoff ...
+	DefaultOutboxMinBackoff = time.Second
+	// DefaultOutboxMaxBackoff ...
+	DefaultOutboxMaxBackoff = 5 * time.Minute
+	// DefaultOutboxLease ...
+	DefaultOutboxLease = time.Minute
+	// DefaultOutboxMaxAttempts ...
+	DefaultOutboxMaxAttempts = 10
+	// DefaultOutboxRetention ...
+	DefaultOutboxRetention = 7 * 24 * time.Hour
+	// DefaultOutboxCleanupBatchSize ...
+	DefaultOutboxCleanupBatchSize = 1000
+)
+
+// OutboxRepository ...
+type OutboxRepository interface {
+	// ClaimPendingEvents ...
+	ClaimPendingEvents(ctx context.Context, limit int, lease time.Duration) ([]model.Event, error)
+	// MarkEventDelivered ...
+	MarkEventDelivered(ctx context.Context, sequence int64) error
+	// MarkEventFailed ...
+	MarkEventFailed(ctx context.Context, sequence int64, retryIn time.Duration, reason string) error
+	// MarkEventDead увеличивает счётчик попыток и убирает событие из
+	// очереди: захват его больше не возвращает и не ждёт.
+	MarkEventDead(ctx context.Context, sequence int64, reason string) error
+	// DeleteDeliveredEvents удаляет до limit событий, доставленных раньше
+	// чем olderThan назад и уже разосланных подпискам вебхуков, и
+	// возвращает их число.
+	DeleteDeliveredEvents(ctx context.Context, olderThan time.Duration, limit int) (int, error)
+}
+
+// Publisher доставляет событие получателю. Ошибка означает, что событие
+// не доставлено и будет отправлено повторно.
+type Publisher interface {
+	Publish(ctx context.Context, event model.Event) error
+}
+
+// OutboxUsecase доставляет события из outbox через Publisher.
+type OutboxUsecase struct {
+	repo        OutboxRepository
+	txm         TxManager
+	publisher   Publisher
+	batchSize   int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	lease       time.Duration
+	maxAttempts int
+}
+
+// OutboxOption ...
+type OutboxOption func(*OutboxUsecase)
+
+// WithOutboxBatchSize ...
+func WithOutboxBatchSize(n int) OutboxOption {
+	return func(u *OutboxUsecase) {
+		if n > 0 {
+			u.batchSize = n
+		}
+	}
+}
+
+// WithOutboxBackoff задаёт задержку перед первым повтором и её предел.
+// Каждая следующая неудачная попытка удваивает задержку.
+func WithOutboxBackoff(minBackoff, maxBackoff time.Duration) OutboxOption {
+	return func(u *OutboxUsecase) {
+		if minBackoff > 0 {
+			u.minBackoff = minBackoff
+		}
+		if maxBackoff > 0 {
+			u.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithOutboxLease задаёт, на сколько диспетчер забирает пачку событий.
+// Публикация пачки не длится дольше аренды, после неё неотправленные
+// события может забрать другой диспетчер.
+func WithOutboxLease(d time.Duration) OutboxOption {
+	return func(u *OutboxUsecase) {
+		if d > 0 {
+			u.lease = d
+		}
+	}
+}
+
+// WithOutboxMaxAttempts число попыток, после которого событие уходит в
+// dead letter и перестаёт задерживать следующие.
+func WithOutboxMaxAttempts(n int) OutboxOption {
+	return func(u *OutboxUsecase) {
+		if n > 0 {
+			u.maxAttempts = n
+		}
+	}
+}
+
+// NewOutbox ...
+func NewOutbox(repo OutboxRepository, txm TxManager, publisher Publisher, opts ...OutboxOption) *OutboxUsecase {
+	u := &OutboxUsecase{
+		repo:        repo,
+		txm:         txm,
+		publisher:   publisher,
+		batchSize:   DefaultOutboxBatchSize,
+		minBackoff:  DefaultOutboxMinBackoff,
+		maxBackoff:  DefaultOutboxMaxBackoff,
+		lease:       DefaultOutboxLease,
+		maxAttempts: DefaultOutboxMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// BatchSize ...
+func (u *OutboxUsecase) BatchSize() int {
+	return u.batchSize
+}
+
+// Dispatch публикует очередную пачку событий по порядку и возвращает число
+// доставленных. На первой ошибке публикации событие откладывается с
+// экспоненциальной задержкой, а пачка прерывается, чтобы не нарушить
+// порядок. Событие, исчерпавшее попытки, уходит в dead letter: порядок
+// ради него больше не держится, и очередь идёт дальше.
+//
+// Пачка забирается в аренду короткой транзакцией, а публикуется уже вне
+// её: медленный получатель не держит ни транзакцию, ни блокировки строк.
+// Каждое событие отмечается доставленным сразу после публикации. Если
+// диспетчер упадёт посреди пачки, по истечении аренды её заберёт другой,
+// и уже опубликованное, но не отмеченное событие уйдёт повторно.
+func (u *OutboxUsecase) Dispatch(ctx context.Context) (int, error) {
+	// Срок считается до захвата, чтобы публикация гарантированно
+	// закончилась раньше аренды в базе.
+	deadline := time.Now().Add(u.lease)
+
+	var events []model.Event
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		events, err = u.repo.ClaimPendingEvents(ctx, u.batchSize, u.lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	publishCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var delivered int
+	for _, event := range events {
+		if err := u.publisher.Publish(publishCtx, event); err != nil {
+			publishErr := fmt.Errorf("publish event %d: %w", event.Sequence, err)
+			if publishCtx.Err() != nil {
+				// Аренда кончилась или диспетчер останавливается: событие
+				// не виновато, его заберут заново без задержки.
+				return delivered, publishErr
+			}
+			if err := u.markFailed(ctx, event, err); err != nil {
+				return delivered, err
+			}
+			return delivered, publishErr
+		}
+
+		if err := u.repo.MarkEventDelivered(ctx, event.Sequence); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// markFailed откладывает событие после неудачной публикации или, если
+// попытки кончились, уводит его в dead letter.
+func (u *OutboxUsecase) markFailed(ctx context.Context, event model.Event, publishErr error) error {
+	if event.Attempts+1 >= u.maxAttempts {
+		return u.repo.MarkEventDead(ctx, event.Sequence, publishErr.Error())
+	}
+	return u.repo.MarkEventFailed(ctx, event.Sequence, u.backoff(event.Attempts), publishErr.Error())
+}
+
+// backoff задержка после attempts предыдущих неудачных попыток.
+func (u *OutboxUsecase) backoff(attempts int) time.Duration {
+	d := u.minBackoff
+	for range attempts {
+		d *= 2
+		if d >= u.maxBackoff {
+			return u.maxBackoff
+		}
+	}
+	return min(d, u.maxBackoff)
+}
+
+// OutboxCleanup удаляет доставленные события старше срока хранения. Без
+// неё outbox растёт бесконечно: доставка только отмечает события.
+type OutboxCleanup struct {
+	repo      OutboxRepository
+	retention time.Duration
+	batchSize int
+}
+
+// NewOutboxCleanup ...
+func NewOutboxCleanup(repo OutboxRepository, retention time.Duration, batchSize int) *OutboxCleanup {
+	if retention <= 0 {
+		retention = DefaultOutboxRetention
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultOutboxCleanupBatchSize
+	}
+	return &OutboxCleanup{repo: repo, retention: retention, batchSize: batchSize}
+}
+
+// BatchSize ...
+func (c *OutboxCleanup) BatchSize() int {
+	return c.batchSize
+}
+
+// Purge удаляет одну пачку доставленных событий и возвращает её размер.
+func (c *OutboxCleanup) Purge(ctx context.Context) (int, error) {
+	n, err := c.repo.DeleteDeliveredEvents(ctx, c.retention, c.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("delete delivered events: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,264 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func pendingEvents(n int) []model.Event {
+	events := make([]model.Event, n)
+	for i := range events {
+		events[i] = model.Event{
+			Sequence: int64(i + 1),
+			ID:       uuid.New(),
+			Type:     "DEPOSIT",
+			WalletID: testUUID(),
+		}
+	}
+	return events
+}
+
+// --- Dispatch ---
+
+func TestOutbox_Dispatch_DeliversInOrder(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(3)
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, 10, usecase.DefaultOutboxLease).Return(events, nil)
+
+	var published []int64
+	pub.
+		On("Publish", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			published = append(published, args.Get(1).(model.Event).Sequence)
+		}).
+		Return(nil)
+	for _, e := range events {
+		repo.On("MarkEventDelivered", ctx, e.Sequence).Return(nil).Once()
+	}
+
+	u := usecase.NewOutbox(repo, txm, pub, usecase.WithOutboxBatchSize(10))
+	n, err := u.Dispatch(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{1, 2, 3}, published)
+	repo.AssertExpectations(t)
+	pub.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_Empty(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, usecase.DefaultOutboxLease).Return([]model.Event{}, nil)
+
+	u := usecase.NewOutbox(repo, txm, pub)
+	n, err := u.Dispatch(ctx)
+
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	pub.AssertNotCalled(t, "Publish")
+}
+
+func TestOutbox_Dispatch_PublishErrorStopsBatch(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(3)
+	events[1].Attempts = 2
+	pubErr := errors.New("connection refused")
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, usecase.DefaultOutboxLease).Return(events, nil)
+	pub.On("Publish", mock.Anything, events[0]).Return(nil)
+	pub.On("Publish", mock.Anything, events[1]).Return(pubErr)
+	repo.On("MarkEventDelivered", ctx, int64(1)).Return(nil)
+	// Две прошлые неудачи: 1s -> 2s -> 4s.
+	repo.On("MarkEventFailed", ctx, int64(2), 4*time.Second, "connection refused").Return(nil)
+
+	u := usecase.NewOutbox(repo, txm, pub, usecase.WithOutboxBackoff(time.Second, time.Minute))
+	n, err := u.Dispatch(ctx)
+
+	assert.ErrorIs(t, err, pubErr)
+	assert.Equal(t, 1, n)
+	pub.AssertNotCalled(t, "Publish", mock.Anything, events[2])
+	repo.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_BackoffCapped(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(1)
+	events[0].Attempts = 50
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, usecase.DefaultOutboxLease).Return(events, nil)
+	pub.On("Publish", mock.Anything, events[0]).Return(errors.New("timeout"))
+	repo.On("MarkEventFailed", ctx, int64(1), time.Minute, "timeout").Return(nil)
+
+	u := usecase.NewOutbox(repo, txm, pub,
+		usecase.WithOutboxBackoff(time.Second, time.Minute),
+		usecase.WithOutboxMaxAttempts(100),
+	)
+	_, err := u.Dispatch(ctx)
+
+	require.Error(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_DeadLetterAfterMaxAttempts(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(1)
+	events[0].Attempts = 2
+	pubErr := errors.New("rejected")
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, usecase.DefaultOutboxLease).Return(events, nil)
+	pub.On("Publish", mock.Anything, events[0]).Return(pubErr)
+	repo.On("MarkEventDead", ctx, int64(1), "rejected").Return(nil)
+
+	u := usecase.NewOutbox(repo, txm, pub, usecase.WithOutboxMaxAttempts(3))
+	n, err := u.Dispatch(ctx)
+
+	assert.ErrorIs(t, err, pubErr)
+	assert.Zero(t, n)
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "MarkEventFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutbox_Dispatch_PublishesOutsideTx(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(2)
+
+	var inTx bool
+	txm.
+		On("RunInTx", mock.Anything, mock.AnythingOfType("func(context.Context) error")).
+		Return(func(ctx context.Context, fn func(context.Context) error, _ ...usecase.TxOption) error {
+			inTx = true
+			defer func() { inTx = false }()
+			return fn(ctx)
+		})
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, time.Second).Return(events, nil)
+	pub.
+		On("Publish", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			assert.False(t, inTx, "publish must not run inside the claim transaction")
+			deadline, ok := args.Get(0).(context.Context).Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+		}).
+		Return(nil)
+	repo.On("MarkEventDelivered", ctx, mock.Anything).Return(nil)
+
+	u := usecase.NewOutbox(repo, txm, pub, usecase.WithOutboxLease(time.Second))
+	n, err := u.Dispatch(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	txm.AssertNumberOfCalls(t, "RunInTx", 1)
+}
+
+func TestOutbox_Dispatch_LeaseExpiredKeepsAttempts(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(1)
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, time.Millisecond).Return(events, nil)
+	pub.
+		On("Publish", mock.Anything, events[0]).
+		Return(func(ctx context.Context, _ model.Event) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+
+	u := usecase.NewOutbox(repo, txm, pub, usecase.WithOutboxLease(time.Millisecond))
+	n, err := u.Dispatch(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, n)
+	repo.AssertNotCalled(t, "MarkEventFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutbox_Dispatch_ClaimError(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	pub := new(mocks.Publisher)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	dbErr := errors.New("db is down")
+
+	setupTxManager(txm)
+	repo.On("ClaimPendingEvents", ctx, usecase.DefaultOutboxBatchSize, usecase.DefaultOutboxLease).Return(nil, dbErr)
+
+	u := usecase.NewOutbox(repo, txm, pub)
+	n, err := u.Dispatch(ctx)
+
+	assert.ErrorIs(t, err, dbErr)
+	assert.Zero(t, n)
+	pub.AssertNotCalled(t, "Publish")
+}
+
+// --- OutboxCleanup ---
+
+func TestOutboxCleanup_Purge(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	ctx := context.Background()
+
+	repo.On("DeleteDeliveredEvents", ctx, usecase.DefaultOutboxRetention, usecase.DefaultOutboxCleanupBatchSize).Return(7, nil)
+
+	c := usecase.NewOutboxCleanup(repo, 0, 0)
+	n, err := c.Purge(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 7, n)
+	assert.Equal(t, usecase.DefaultOutboxCleanupBatchSize, c.BatchSize())
+}
+
+func TestOutboxCleanup_Purge_Error(t *testing.T) {
+	repo := new(mocks.OutboxRepository)
+	ctx := context.Background()
+
+	dbErr := errors.New("db is down")
+	repo.On("DeleteDeliveredEvents", ctx, time.Hour, 10).Return(0, dbErr)
+
+	n, err := usecase.NewOutboxCleanup(repo, time.Hour, 10).Purge(ctx)
+
+	assert.ErrorIs(t, err, dbErr)
+	assert.Zero(t, n)
+}
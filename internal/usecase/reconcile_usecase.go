@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"time"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ReconcileRepository ...
+type ReconcileRepository interface {
+	// StreamLedgerBalances ...
+	StreamLedgerBalances(ctx context.Context, fn func(model.LedgerBalance) error) error
+	// GetLedgerBalanceForUpdate ...
+	GetLedgerBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (model.LedgerBalance, error)
+	// SaveOperation ...
+	SaveOperation(ctx context.Context, op Operation) (time.Time, error)
+	// SaveEvent ...
+	SaveEvent(ctx context.Context, event model.Event) error
+	// NotifyWalletChanged ...
+	NotifyWalletChanged(ctx context.Context, walletID uuid.UUID) error
+}
+
+// ReconcileUsecase сверяет wallets.balance с суммой операций в wallet_operations.
+type ReconcileUsecase struct {
+	repo ReconcileRepository
+	txm  TxManager
+}
+
+// NewReconcile ...
+func NewReconcile(repo ReconcileRepository, txm TxManager) *ReconcileUsecase {
+	return &ReconcileUsecase{
+		repo: repo,
+		txm:  txm,
+	}
+}
+
+// Reconcile проходит по всем кошелькам в одном снимке базы и вызывает fn
+// для каждого расхождения.
+func (u *ReconcileUsecase) Reconcile(ctx context.Context, fn func(model.LedgerBalance) error) (model.ReconcileSummary, error) {
+	var summary model.ReconcileSummary
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		// Повтор пересчитал бы сводку и заново вызвал fn, поэтому summary
+		// сбрасывается, а попытка разрешена только одна.
+		summary = model.ReconcileSummary{}
+		return u.repo.StreamLedgerBalances(ctx, func(b model.LedgerBalance) error {
+			summary.Checked++
+			if b.Difference() == 0 {
+				return nil
+			}
+			summary.Mismatched++
+			return fn(b)
+		})
+	}, WithIsolation(RepeatableRead), ReadOnly(), WithMaxAttempts(1))
+	if err != nil {
+		return model.ReconcileSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// Adjust записывает корректирующую операцию ADJUSTMENT на текущую разницу
+// между балансом кошелька и суммой его операций. Если расхождения уже нет,
+// возвращает нулевую операцию. Как и остальные операции, корректировка
+// публикуется в outbox и подписчикам кошелька.
+//
+// Журнальная запись при этом не проводится: баланс кошелька верен, догоняет
+// его только журнал операций. Главная книга получила такие остатки записью
+// OPENING_BALANCE при миграции.
+func (u *ReconcileUsecase) Adjust(ctx context.Context, walletID uuid.UUID) (Operation, error) {
+	var op Operation
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		op = Operation{}
+
+		b, err := u.repo.GetLedgerBalanceForUpdate(ctx, walletID)
+		if err != nil {
+			return err
+		}
+
+		diff := b.Difference()
+		if diff == 0 {
+			return nil
+		}
+
+		op = Operation{
+			ID:       uuid.New(),
+			WalletID: walletID,
+			Type:     "ADJUSTMENT",
+			Amount:   diff,
+
+			// Баланс кошелька не меняется: корректировка доводит до него
+			// сумму операций, а цепочка balance_after -> balance_before
+			// соседних операций не рвётся.
+			BalanceBefore: b.Balance,
+			BalanceAfter:  b.Balance,
+		}
+		op.CreatedAt, err = u.repo.SaveOperation(ctx, op)
+		if err != nil {
+			return err
+		}
+
+		return publishOperation(ctx, u.repo, op)
+	})
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
@@ -0,0 +1,173 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReadOnlyTxManager Reconcile передаёт три опции транзакции.
+func setupReadOnlyTxManager(txm *mocks.TxManager) {
+	txm.
+		On("RunInTx", mock.Anything, mock.AnythingOfType("func(context.Context) error"),
+			mock.Anything, mock.Anything, mock.Anything).
+		Return(func(ctx context.Context, fn func(context.Context) error, _ ...usecase.TxOption) error {
+			return fn(ctx)
+		})
+}
+
+// --- Reconcile ---
+
+func TestReconcile_ReportsOnlyMismatches(t *testing.T) {
+	repo := new(mocks.ReconcileRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	balances := []model.LedgerBalance{
+		{WalletID: uuid.New(), Balance: 100, LedgerSum: 100},
+		{WalletID: testUUID(), Balance: 100000, LedgerSum: 0},
+		{WalletID: uuid.New(), Balance: 0, LedgerSum: 0},
+	}
+
+	setupReadOnlyTxManager(txm)
+	repo.On("StreamLedgerBalances", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, fn func(model.LedgerBalance) error) error {
+			for _, b := range balances {
+				if err := fn(b); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+	var got []model.LedgerBalance
+	u := usecase.NewReconcile(repo, txm)
+	summary, err := u.Reconcile(ctx, func(b model.LedgerBalance) error {
+		got = append(got, b)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, model.ReconcileSummary{Checked: 3, Mismatched: 1}, summary)
+	require.Len(t, got, 1)
+	assert.Equal(t, testUUID(), got[0].WalletID)
+	assert.Equal(t, int64(100000), got[0].Difference())
+	repo.AssertExpectations(t)
+}
+
+func TestReconcile_CallbackErrorStopsStream(t *testing.T) {
+	repo := new(mocks.ReconcileRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	writeErr := errors.New("disk full")
+
+	setupReadOnlyTxManager(txm)
+	repo.On("StreamLedgerBalances", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, fn func(model.LedgerBalance) error) error {
+			return fn(model.LedgerBalance{WalletID: testUUID(), Balance: 1})
+		})
+
+	u := usecase.NewReconcile(repo, txm)
+	summary, err := u.Reconcile(ctx, func(model.LedgerBalance) error {
+		return writeErr
+	})
+
+	require.ErrorIs(t, err, writeErr)
+	assert.Zero(t, summary)
+}
+
+// --- Adjust ---
+
+func TestAdjust_WritesSignedDifference(t *testing.T) {
+	cases := []struct {
+		name      string
+		balance   int64
+		ledgerSum int64
+		want      int64
+	}{
+		{name: "balance above ledger", balance: 100000, ledgerSum: 0, want: 100000},
+		{name: "balance below ledger", balance: 50, ledgerSum: 80, want: -30},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := new(mocks.ReconcileRepository)
+			txm := new(mocks.TxManager)
+			ctx := context.Background()
+
+			walletID := testUUID()
+			createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			setupTxManager(txm)
+			repo.On("GetLedgerBalanceForUpdate", mock.Anything, walletID).
+				Return(model.LedgerBalance{WalletID: walletID, Balance: tc.balance, LedgerSum: tc.ledgerSum}, nil)
+			repo.On("SaveOperation", mock.Anything, mock.MatchedBy(func(op usecase.Operation) bool {
+				return op.WalletID == walletID && op.Type == "ADJUSTMENT" && op.Amount == tc.want
+			})).Return(createdAt, nil)
+			repo.On("SaveEvent", mock.Anything, mock.MatchedBy(func(e model.Event) bool {
+				return e.WalletID == walletID && e.Type == "ADJUSTMENT"
+			})).Return(nil)
+			repo.On("NotifyWalletChanged", mock.Anything, walletID).Return(nil)
+
+			u := usecase.NewReconcile(repo, txm)
+			op, err := u.Adjust(ctx, walletID)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, op.Amount)
+			// Баланс кошелька корректировка не меняет.
+			assert.Equal(t, tc.balance, op.BalanceBefore)
+			assert.Equal(t, tc.balance, op.BalanceAfter)
+			assert.Equal(t, createdAt, op.CreatedAt)
+			assert.NotEqual(t, uuid.Nil, op.ID)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdjust_NoMismatch(t *testing.T) {
+	repo := new(mocks.ReconcileRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetLedgerBalanceForUpdate", mock.Anything, walletID).
+		Return(model.LedgerBalance{WalletID: walletID, Balance: 10, LedgerSum: 10}, nil)
+
+	u := usecase.NewReconcile(repo, txm)
+	op, err := u.Adjust(ctx, walletID)
+
+	require.NoError(t, err)
+	assert.Zero(t, op)
+	repo.AssertNotCalled(t, "SaveOperation", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "SaveEvent", mock.Anything, mock.Anything)
+}
+
+func TestAdjust_WalletNotFound(t *testing.T) {
+	repo := new(mocks.ReconcileRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetLedgerBalanceForUpdate", mock.Anything, walletID).
+		Return(model.LedgerBalance{}, walleterror.ErrWalletNotFound)
+
+	u := usecase.NewReconcile(repo, txm)
+	_, err := u.Adjust(ctx, walletID)
+
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+}
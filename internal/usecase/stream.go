@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// DefaultStreamBatchSize сколько событий читается из outbox за один запрос.
+const DefaultStreamBatchSize = 100
+
+// EventRepository ...
+type EventRepository interface {
+	// GetWalletSnapshot ...
+	GetWalletSnapshot(ctx context.Context, walletID uuid.UUID) (model.WalletSnapshot, error)
+	// ListWalletEvents ...
+	ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterSequence int64, limit int) ([]model.Event, error)
+}
+
+// WalletNotifier сообщает, что у кошелька появились новые события. Сигналы
+// схлопываются: получив его, подписчик сам дочитывает события из outbox.
+// Сигнал без новых событий допустим.
+type WalletNotifier interface {
+	Subscribe(walletID uuid.UUID) (<-chan struct{}, func())
+}
+
+// StreamUsecase отдаёт события кошелька для живого потока.
+type StreamUsecase struct {
+	repo      EventRepository
+	notifier  WalletNotifier
+	batchSize int
+}
+
+// NewStream ...
+func NewStream(repo EventRepository, notifier WalletNotifier) *StreamUsecase {
+	return &StreamUsecase{
+		repo:      repo,
+		notifier:  notifier,
+		batchSize: DefaultStreamBatchSize,
+	}
+}
+
+// BatchSize ...
+func (u *StreamUsecase) BatchSize() int {
+	return u.batchSize
+}
+
+// Subscribe подписывается на события кошелька и возвращает снимок баланса.
+// Подписка оформляется до чтения снимка, поэтому событие, закоммиченное
+// между ними, не теряется: на него придёт сигнал. Вызывающий обязан вызвать
+// возвращённую функцию отписки.
+func (u *StreamUsecase) Subscribe(ctx context.Context, walletID uuid.UUID) (model.WalletSnapshot, <-chan struct{}, func(), error) {
+	notify, unsubscribe := u.notifier.Subscribe(walletID)
+
+	snapshot, err := u.repo.GetWalletSnapshot(ctx, walletID)
+	if err != nil {
+		unsubscribe()
+		return model.WalletSnapshot{}, nil, nil, err
+	}
+
+	return snapshot, notify, unsubscribe, nil
+}
+
+// EventsAfter события кошелька с номером больше afterSequence, не больше
+// BatchSize за вызов. Операции кошелька сериализуются блокировкой его строки,
+// поэтому номера его событий растут в порядке коммита и пропусков при
+// чтении "после последнего отданного" не бывает.
+func (u *StreamUsecase) EventsAfter(ctx context.Context, walletID uuid.UUID, afterSequence int64) ([]model.Event, error) {
+	return u.repo.ListWalletEvents(ctx, walletID, afterSequence, u.batchSize)
+}
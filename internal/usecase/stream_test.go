@@ -0,0 +1,83 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// --- Subscribe ---
+
+func TestStream_Subscribe_BeforeSnapshot(t *testing.T) {
+	repo := new(mocks.EventRepository)
+	notifier := new(mocks.WalletNotifier)
+	ctx := context.Background()
+	walletID := testUUID()
+
+	var subscribed bool
+	ch := make(chan struct{})
+	notifier.
+		On("Subscribe", walletID).
+		Run(func(_ mock.Arguments) { subscribed = true }).
+		Return((<-chan struct{})(ch), func() {})
+	snapshot := model.WalletSnapshot{WalletBalance: model.WalletBalance{Balance: 100, AvailableBalance: 80}, Sequence: 7}
+	repo.
+		On("GetWalletSnapshot", ctx, walletID).
+		Run(func(_ mock.Arguments) {
+			// Иначе событие между снимком и подпиской потеряется.
+			assert.True(t, subscribed, "snapshot read before subscribe")
+		}).
+		Return(snapshot, nil)
+
+	u := usecase.NewStream(repo, notifier)
+	got, notify, unsubscribe, err := u.Subscribe(ctx, walletID)
+
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, got)
+	assert.Equal(t, (<-chan struct{})(ch), notify)
+	assert.NotNil(t, unsubscribe)
+}
+
+func TestStream_Subscribe_NotFoundUnsubscribes(t *testing.T) {
+	repo := new(mocks.EventRepository)
+	notifier := new(mocks.WalletNotifier)
+	ctx := context.Background()
+	walletID := testUUID()
+
+	var unsubscribed bool
+	notifier.
+		On("Subscribe", walletID).
+		Return((<-chan struct{})(make(chan struct{})), func() { unsubscribed = true })
+	repo.On("GetWalletSnapshot", ctx, walletID).Return(model.WalletSnapshot{}, walleterror.ErrWalletNotFound)
+
+	u := usecase.NewStream(repo, notifier)
+	_, _, _, err := u.Subscribe(ctx, walletID)
+
+	assert.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	assert.True(t, unsubscribed)
+}
+
+// --- EventsAfter ---
+
+func TestStream_EventsAfter(t *testing.T) {
+	repo := new(mocks.EventRepository)
+	ctx := context.Background()
+	walletID := testUUID()
+
+	events := []model.Event{{Sequence: 8, WalletID: walletID}}
+	repo.On("ListWalletEvents", ctx, walletID, int64(7), usecase.DefaultStreamBatchSize).Return(events, nil)
+
+	u := usecase.NewStream(repo, new(mocks.WalletNotifier))
+	got, err := u.EventsAfter(ctx, walletID, 7)
+
+	require.NoError(t, err)
+	assert.Equal(t, events, got)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,54 @@
+package usecase
+
+// IsolationLevel ...
+type IsolationLevel string
+
+const (
+	// ReadCommitted ...
+	ReadCommitted IsolationLevel = "read committed"
+	// RepeatableRead ...
+	RepeatableRead IsolationLevel = "repeatable read"
+	// Serializable ...
+	Serializable IsolationLevel = "serializable"
+)
+
+// TxOptions ...
+type TxOptions struct {
+	Isolation  IsolationLevel
+	ReadOnly   bool
+	Deferrable bool
+	// MaxAttempts сколько раз TxManager выполнит замыкание целиком при
+	// ошибках сериализации и дедлоках. 0 - значение по умолчанию TxManager.
+	MaxAttempts int
+}
+
+// TxOption ...
+type TxOption func(*TxOptions)
+
+// WithIsolation ...
+func WithIsolation(level IsolationLevel) TxOption {
+	return func(o *TxOptions) {
+		o.Isolation = level
+	}
+}
+
+// ReadOnly ...
+func ReadOnly() TxOption {
+	return func(o *TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// Deferrable ...
+func Deferrable() TxOption {
+	return func(o *TxOptions) {
+		o.Deferrable = true
+	}
+}
+
+// WithMaxAttempts ...
+func WithMaxAttempts(n int) TxOption {
+	return func(o *TxOptions) {
+		o.MaxAttempts = n
+	}
+}
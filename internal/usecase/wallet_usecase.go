@@ -2,7 +2,9 @@
 package usecase
 
 import (
+	"bytes"
 	"context"
+	"time"
 	walleterror "wallet/internal/error"
 	"wallet/internal/model"
 
@@ -11,101 +13,469 @@ import (
 
 // TxManager ...
 type TxManager interface {
-	RunInTx(ctx context.Context, fn func(ctx context.Context) error) error
+	RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error
 }
 
 // WalletRepository ...
 type WalletRepository interface {
+	// CreateWallet ...
+	CreateWallet(ctx context.Context, walletID uuid.UUID) error
 	// GetBalance ...
 	GetBalance(ctx context.Context, walletID uuid.UUID) (int64, error)
+	// GetWalletBalance ...
+	GetWalletBalance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error)
+	// GetBalanceAt ...
+	GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, time.Time, error)
 	// GetBalanceForUpdate ...
 	GetBalanceForUpdate(ctx context.Context, walletID uuid.UUID) (int64, error)
-	// UpdateBalance ...
-	UpdateBalance(ctx context.Context, walletID uuid.UUID, newBalance int64) error
 	// SaveOperation ...
-	SaveOperation(ctx context.Context, op Operation) error
+	SaveOperation(ctx context.Context, op Operation) (time.Time, error)
+	// GetOperation ...
+	GetOperation(ctx context.Context, operationID uuid.UUID) (Operation, error)
+	// ListOperations ...
+	ListOperations(ctx context.Context, filter model.OperationFilter) ([]Operation, error)
+	// ClaimIdempotencyKey ...
+	ClaimIdempotencyKey(ctx context.Context, key, fingerprint string, ttl time.Duration) (model.IdempotencyRecord, bool, error)
+	// SaveIdempotencyResponse ...
+	SaveIdempotencyResponse(ctx context.Context, key string, response []byte) error
+	// PostEntry ...
+	PostEntry(ctx context.Context, entry model.JournalEntry) error
+	// CreateHold ...
+	CreateHold(ctx context.Context, hold model.Hold, ttl time.Duration) (model.Hold, error)
+	// GetHoldForUpdate ...
+	GetHoldForUpdate(ctx context.Context, holdID uuid.UUID) (model.Hold, error)
+	// UpdateHold ...
+	UpdateHold(ctx context.Context, hold model.Hold) error
+	// GetHeldAmount ...
+	GetHeldAmount(ctx context.Context, walletID uuid.UUID) (int64, error)
+	// SaveEvent ...
+	SaveEvent(ctx context.Context, event model.Event) error
+	// NotifyWalletChanged ...
+	NotifyWalletChanged(ctx context.Context, walletID uuid.UUID) error
+	// ApplyOperation ...
+	ApplyOperation(ctx context.Context, op Operation, entry model.JournalEntry) (Operation, error)
 }
 
+const (
+	// DefaultPageLimit ...
+	DefaultPageLimit = 50
+	// MaxPageLimit ...
+	MaxPageLimit = 200
+	// DefaultIdempotencyTTL ...
+	DefaultIdempotencyTTL = 24 * time.Hour
+)
+
 // WalletUsecase ...
 type WalletUsecase struct {
-	repo WalletRepository
-	txm  TxManager
+	repo           WalletRepository
+	txm            TxManager
+	idempotencyTTL time.Duration
+	holdTTL        time.Duration
+	balanceUpdate  BalanceUpdateMode
+	metrics        OperationMetrics
+}
+
+// Option ...
+type Option func(*WalletUsecase)
+
+// WithIdempotencyTTL ...
+func WithIdempotencyTTL(ttl time.Duration) Option {
+	return func(u *WalletUsecase) {
+		if ttl > 0 {
+			u.idempotencyTTL = ttl
+		}
+	}
 }
 
 // New ...
-func New(repo WalletRepository, txm TxManager) *WalletUsecase {
-	return &WalletUsecase{
-		repo: repo,
-		txm:  txm,
+func New(repo WalletRepository, txm TxManager, opts ...Option) *WalletUsecase {
+	u := &WalletUsecase{
+		repo:           repo,
+		txm:            txm,
+		idempotencyTTL: DefaultIdempotencyTTL,
+		holdTTL:        DefaultHoldTTL,
+		balanceUpdate:  BalanceUpdateLocked,
+		metrics:        nopMetrics{},
 	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
 }
 
 // Operation ...
-type Operation struct {
-	ID       uuid.UUID
-	WalletID uuid.UUID
-	Type     string // "DEPOSIT" или "WITHDRAW"
-	Amount   int64
-}
+type Operation = model.Operation
 
 // Balance ...
-func (u *WalletUsecase) Balance(ctx context.Context, walletID uuid.UUID) (int64, error) {
-	balance, err := u.repo.GetBalance(ctx, walletID)
+func (u *WalletUsecase) Balance(ctx context.Context, walletID uuid.UUID) (model.WalletBalance, error) {
+	balance, err := u.repo.GetWalletBalance(ctx, walletID)
+	if err != nil {
+		return model.WalletBalance{}, err
+	}
+
+	return balance, nil
+}
+
+// BalanceAt баланс кошелька на момент at по журналу операций: учитываются
+// операции с created_at <= at.
+func (u *WalletUsecase) BalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (int64, error) {
+	balance, createdAt, err := u.repo.GetBalanceAt(ctx, walletID, at)
 	if err != nil {
 		return 0, err
 	}
 
+	if at.Before(createdAt) {
+		return 0, walleterror.ErrAsOfBeforeCreation
+	}
+
 	return balance, nil
 }
 
-// Deposit ...
-func (u *WalletUsecase) Deposit(ctx context.Context, in model.DepositInput) error {
-	return u.txm.RunInTx(ctx, func(ctx context.Context) error {
-		balance, err := u.repo.GetBalanceForUpdate(ctx, in.WalletID)
-		if err != nil {
+// CreateWallet ...
+func (u *WalletUsecase) CreateWallet(ctx context.Context, in model.CreateWalletInput) (uuid.UUID, error) {
+	walletID := in.WalletID
+	if walletID == uuid.Nil {
+		walletID = uuid.New()
+	}
+
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		if err := u.repo.CreateWallet(ctx, walletID); err != nil {
 			return err
 		}
 
-		newBalance := balance + in.Amount
-
-		if err := u.repo.UpdateBalance(ctx, in.WalletID, newBalance); err != nil {
-			return err
+		if in.Balance == 0 {
+			return nil
 		}
 
 		op := Operation{
 			ID:       uuid.New(),
-			WalletID: in.WalletID,
+			WalletID: walletID,
 			Type:     "DEPOSIT",
-			Amount:   in.Amount,
+			Amount:   in.Balance,
+
+			BalanceBefore: 0,
+			BalanceAfter:  in.Balance,
+		}
+		if _, err := u.saveOperation(ctx, op); err != nil {
+			return err
+		}
+
+		return u.postEntry(ctx, op.ID, "DEPOSIT",
+			model.Posting{AccountID: walletID, Amount: in.Balance},
+			model.Posting{AccountID: model.ExternalFundingAccountID, Amount: -in.Balance},
+		)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return walletID, nil
+}
+
+// Operation ...
+func (u *WalletUsecase) Operation(ctx context.Context, operationID uuid.UUID) (Operation, error) {
+	op, err := u.repo.GetOperation(ctx, operationID)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
+
+// ListOperations ...
+func (u *WalletUsecase) ListOperations(ctx context.Context, in model.ListOperationsInput) (model.OperationsPage, error) {
+	limit := in.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return model.OperationsPage{}, walleterror.ErrInvalidLimit
+	}
+
+	if !in.From.IsZero() && !in.To.IsZero() && !in.From.Before(in.To) {
+		return model.OperationsPage{}, walleterror.ErrInvalidTimeRange
+	}
+
+	filter := model.OperationFilter{
+		WalletID: in.WalletID,
+		Types:    in.Types,
+		From:     in.From,
+		To:       in.To,
+		// Берём на одну запись больше, чтобы понять, есть ли следующая страница.
+		Limit: limit + 1,
+	}
+
+	if in.Cursor != "" {
+		cursor, err := decodeCursor(in.Cursor)
+		if err != nil {
+			return model.OperationsPage{}, err
+		}
+		filter.After = &cursor
+	}
+
+	if _, err := u.repo.GetBalance(ctx, in.WalletID); err != nil {
+		return model.OperationsPage{}, err
+	}
+
+	ops, err := u.repo.ListOperations(ctx, filter)
+	if err != nil {
+		return model.OperationsPage{}, err
+	}
+
+	page := model.OperationsPage{Operations: ops}
+	if len(ops) > limit {
+		page.Operations = ops[:limit]
+		last := page.Operations[limit-1]
+		page.NextCursor = encodeCursor(model.OperationCursor{
+			CreatedAt: last.CreatedAt,
+			Seq:       last.Seq,
+		})
+	}
+
+	return page, nil
+}
+
+// Deposit ...
+func (u *WalletUsecase) Deposit(ctx context.Context, in model.DepositInput) (model.Receipt, error) {
+	var receipt model.Receipt
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		fp := fingerprint("DEPOSIT", in.WalletID, in.Amount)
+		replayed, err := u.claimIdempotencyKey(ctx, in.IdempotencyKey, fp, &receipt)
+		if err != nil || replayed {
+			return err
+		}
+
+		var op Operation
+		if u.balanceUpdate == BalanceUpdateAtomic {
+			op, err = u.depositAtomic(ctx, in.WalletID, in.Amount)
+		} else {
+			op, err = u.depositLocked(ctx, in.WalletID, in.Amount)
 		}
-		return u.repo.SaveOperation(ctx, op)
+		if err != nil {
+			return err
+		}
+
+		receipt = model.Receipt{Operation: op, BalanceAfter: op.BalanceAfter}
+		return u.saveIdempotencyResponse(ctx, in.IdempotencyKey, receipt)
 	})
+	u.observeOperation("DEPOSIT", err)
+	if err != nil {
+		return model.Receipt{}, err
+	}
+
+	return receipt, nil
 }
 
 // Withdraw ...
-func (u *WalletUsecase) Withdraw(ctx context.Context, in model.WithdrawInput) error {
-	return u.txm.RunInTx(ctx, func(ctx context.Context) error {
-		balance, err := u.repo.GetBalanceForUpdate(ctx, in.WalletID)
+func (u *WalletUsecase) Withdraw(ctx context.Context, in model.WithdrawInput) (model.Receipt, error) {
+	var receipt model.Receipt
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		fp := fingerprint("WITHDRAW", in.WalletID, in.Amount)
+		replayed, err := u.claimIdempotencyKey(ctx, in.IdempotencyKey, fp, &receipt)
+		if err != nil || replayed {
+			return err
+		}
+
+		var op Operation
+		if u.balanceUpdate == BalanceUpdateAtomic {
+			op, err = u.withdrawAtomic(ctx, in.WalletID, in.Amount)
+		} else {
+			op, err = u.withdrawLocked(ctx, in.WalletID, in.Amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		receipt = model.Receipt{Operation: op, BalanceAfter: op.BalanceAfter}
+		return u.saveIdempotencyResponse(ctx, in.IdempotencyKey, receipt)
+	})
+	u.observeOperation("WITHDRAW", err)
+	if err != nil {
+		return model.Receipt{}, err
+	}
+
+	return receipt, nil
+}
+
+// Transfer ...
+func (u *WalletUsecase) Transfer(ctx context.Context, in model.TransferInput) (uuid.UUID, error) {
+	if in.FromWalletID == in.ToWalletID {
+		return uuid.Nil, walleterror.ErrSameWallet
+	}
+
+	transferID := uuid.New()
+
+	// Кошельки блокируются в порядке возрастания ID, чтобы встречные
+	// переводы A->B и B->A не ловили дедлок.
+	lockOrder := []uuid.UUID{in.FromWalletID, in.ToWalletID}
+	if bytes.Compare(lockOrder[0][:], lockOrder[1][:]) > 0 {
+		lockOrder[0], lockOrder[1] = lockOrder[1], lockOrder[0]
+	}
+
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		balances := make(map[uuid.UUID]int64, len(lockOrder))
+		for _, walletID := range lockOrder {
+			balance, err := u.repo.GetBalanceForUpdate(ctx, walletID)
+			if err != nil {
+				return err
+			}
+			balances[walletID] = balance
+		}
+
+		held, err := u.repo.GetHeldAmount(ctx, in.FromWalletID)
 		if err != nil {
 			return err
 		}
 
-		if balance < in.Amount {
+		if balances[in.FromWalletID]-held < in.Amount {
 			return walleterror.ErrInsufficientFunds
 		}
 
-		newBalance := balance - in.Amount
+		out := Operation{
+			ID:         uuid.New(),
+			WalletID:   in.FromWalletID,
+			Type:       "TRANSFER_OUT",
+			Amount:     in.Amount,
+			TransferID: uuid.NullUUID{UUID: transferID, Valid: true},
 
-		if err := u.repo.UpdateBalance(ctx, in.WalletID, newBalance); err != nil {
+			BalanceBefore: balances[in.FromWalletID],
+			BalanceAfter:  balances[in.FromWalletID] - in.Amount,
+		}
+		if _, err := u.saveOperation(ctx, out); err != nil {
 			return err
 		}
 
-		op := Operation{
-			ID:       uuid.New(),
-			WalletID: in.WalletID,
-			Type:     "WITHDRAW",
-			Amount:   in.Amount,
+		inc := Operation{
+			ID:         uuid.New(),
+			WalletID:   in.ToWalletID,
+			Type:       "TRANSFER_IN",
+			Amount:     in.Amount,
+			TransferID: uuid.NullUUID{UUID: transferID, Valid: true},
+
+			BalanceBefore: balances[in.ToWalletID],
+			BalanceAfter:  balances[in.ToWalletID] + in.Amount,
+		}
+		if _, err := u.saveOperation(ctx, inc); err != nil {
+			return err
 		}
-		return u.repo.SaveOperation(ctx, op)
+
+		return u.postEntry(ctx, transferID, "TRANSFER",
+			model.Posting{AccountID: in.FromWalletID, Amount: -in.Amount},
+			model.Posting{AccountID: in.ToWalletID, Amount: in.Amount},
+		)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return transferID, nil
+}
+
+// deposit зачисляет amount на кошелёк, уже заблокированный в текущей
+// транзакции, с балансом balance.
+func (u *WalletUsecase) deposit(ctx context.Context, walletID uuid.UUID, amount, balance int64) (Operation, error) {
+	op := Operation{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     "DEPOSIT",
+		Amount:   amount,
+
+		BalanceBefore: balance,
+		BalanceAfter:  balance + amount,
+	}
+	op, err := u.saveOperation(ctx, op)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	err = u.postEntry(ctx, op.ID, "DEPOSIT",
+		model.Posting{AccountID: walletID, Amount: amount},
+		model.Posting{AccountID: model.ExternalFundingAccountID, Amount: -amount},
+	)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
+
+// withdraw списывает amount с кошелька, уже заблокированного в текущей
+// транзакции. held - сумма активных холдов: их списывать нельзя.
+func (u *WalletUsecase) withdraw(ctx context.Context, walletID uuid.UUID, amount, balance, held int64) (Operation, error) {
+	if balance-held < amount {
+		return Operation{}, walleterror.ErrInsufficientFunds
+	}
+
+	op := Operation{
+		ID:       uuid.New(),
+		WalletID: walletID,
+		Type:     "WITHDRAW",
+		Amount:   amount,
+
+		BalanceBefore: balance,
+		BalanceAfter:  balance - amount,
+	}
+	op, err := u.saveOperation(ctx, op)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	err = u.postEntry(ctx, op.ID, "WITHDRAW",
+		model.Posting{AccountID: walletID, Amount: -amount},
+		model.Posting{AccountID: model.ExternalFundingAccountID, Amount: amount},
+	)
+	if err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
+
+// saveOperation сохраняет операцию и событие о ней в outbox и уведомляет
+// подписчиков кошелька. Вызывается внутри RunInTx, поэтому событие и
+// уведомление появляются тогда и только тогда, когда закоммичена операция.
+func (u *WalletUsecase) saveOperation(ctx context.Context, op Operation) (Operation, error) {
+	createdAt, err := u.repo.SaveOperation(ctx, op)
+	if err != nil {
+		return Operation{}, err
+	}
+	op.CreatedAt = createdAt
+
+	if err := publishOperation(ctx, u.repo, op); err != nil {
+		return Operation{}, err
+	}
+
+	return op, nil
+}
+
+// eventPublisher часть репозитория, через которую публикуются операции.
+type eventPublisher interface {
+	SaveEvent(ctx context.Context, event model.Event) error
+	NotifyWalletChanged(ctx context.Context, walletID uuid.UUID) error
+}
+
+// publishOperation пишет событие о сохранённой операции в outbox и
+// уведомляет подписчиков кошелька.
+func publishOperation(ctx context.Context, repo eventPublisher, op Operation) error {
+	event, err := model.NewOperationEvent(op)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.SaveEvent(ctx, event); err != nil {
+		return err
+	}
+
+	return repo.NotifyWalletChanged(ctx, op.WalletID)
+}
+
+// postEntry проводит журнальную запись. Балансы кошельков меняются только
+// через проводки, поэтому каждая операция сопровождается записью с тем же ID
+// (для перевода - с ID перевода).
+func (u *WalletUsecase) postEntry(ctx context.Context, entryID uuid.UUID, description string, postings ...model.Posting) error {
+	return u.repo.PostEntry(ctx, model.JournalEntry{
+		ID:          entryID,
+		Description: description,
+		Postings:    postings,
 	})
 }
@@ -3,8 +3,11 @@ package usecase_test
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 	walleterror "wallet/internal/error"
 	"wallet/internal/mocks"
 	"wallet/internal/model"
@@ -24,11 +27,27 @@ func testUUID() uuid.UUID {
 func setupTxManager(txm *mocks.TxManager) {
 	txm.
 		On("RunInTx", mock.Anything, mock.AnythingOfType("func(context.Context) error")).
-		Return(func(ctx context.Context, fn func(context.Context) error) error {
+		Return(func(ctx context.Context, fn func(context.Context) error, _ ...usecase.TxOption) error {
 			return fn(ctx)
 		})
 }
 
+// entryPosting матчит сбалансированную журнальную запись с проводкой
+// amount по счёту account.
+func entryPosting(account uuid.UUID, amount int64) any {
+	return mock.MatchedBy(func(e model.JournalEntry) bool {
+		if !e.Balanced() {
+			return false
+		}
+		for _, p := range e.Postings {
+			if p.AccountID == account && p.Amount == amount {
+				return true
+			}
+		}
+		return false
+	})
+}
+
 // --- Balance ---
 
 func TestUsecase_Balance_Success(t *testing.T) {
@@ -37,9 +56,9 @@ func TestUsecase_Balance_Success(t *testing.T) {
 	ctx := context.Background()
 
 	walletID := testUUID()
-	expected := int64(1000)
+	expected := model.WalletBalance{Balance: 1000, AvailableBalance: 700}
 
-	repo.On("GetBalance", ctx, walletID).Return(expected, nil)
+	repo.On("GetWalletBalance", ctx, walletID).Return(expected, nil)
 
 	u := usecase.New(repo, txm)
 	balance, err := u.Balance(ctx, walletID)
@@ -56,13 +75,13 @@ func TestUsecase_Balance_NotFound(t *testing.T) {
 
 	walletID := testUUID()
 
-	repo.On("GetBalance", ctx, walletID).Return(int64(0), walleterror.ErrWalletNotFound)
+	repo.On("GetWalletBalance", ctx, walletID).Return(model.WalletBalance{}, walleterror.ErrWalletNotFound)
 
 	u := usecase.New(repo, txm)
 	balance, err := u.Balance(ctx, walletID)
 
 	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
-	assert.Equal(t, int64(0), balance)
+	assert.Zero(t, balance)
 	repo.AssertExpectations(t)
 }
 
@@ -74,13 +93,107 @@ func TestUsecase_Balance_InternalError(t *testing.T) {
 	walletID := testUUID()
 	dbErr := errors.New("connection refused")
 
-	repo.On("GetBalance", ctx, walletID).Return(int64(0), dbErr)
+	repo.On("GetWalletBalance", ctx, walletID).Return(model.WalletBalance{}, dbErr)
 
 	u := usecase.New(repo, txm)
 	balance, err := u.Balance(ctx, walletID)
 
 	require.ErrorIs(t, err, dbErr)
-	assert.Equal(t, int64(0), balance)
+	assert.Zero(t, balance)
+	repo.AssertExpectations(t)
+}
+
+// --- BalanceAt ---
+
+func TestUsecase_BalanceAt_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	asOf := time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	repo.On("GetBalanceAt", ctx, walletID, asOf).Return(int64(750), createdAt, nil)
+
+	u := usecase.New(repo, txm)
+	balance, err := u.BalanceAt(ctx, walletID, asOf)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(750), balance)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_BalanceAt_BeforeCreation(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	asOf := createdAt.Add(-time.Second)
+
+	repo.On("GetBalanceAt", ctx, walletID, asOf).Return(int64(0), createdAt, nil)
+
+	u := usecase.New(repo, txm)
+	_, err := u.BalanceAt(ctx, walletID, asOf)
+
+	require.ErrorIs(t, err, walleterror.ErrAsOfBeforeCreation)
+}
+
+func TestUsecase_BalanceAt_WalletNotFound(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	asOf := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo.On("GetBalanceAt", ctx, walletID, asOf).Return(int64(0), time.Time{}, walleterror.ErrWalletNotFound)
+
+	u := usecase.New(repo, txm)
+	_, err := u.BalanceAt(ctx, walletID, asOf)
+
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+}
+
+// --- Operation ---
+
+func TestUsecase_Operation_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	expected := usecase.Operation{
+		ID:       uuid.New(),
+		WalletID: testUUID(),
+		Type:     "DEPOSIT",
+		Amount:   100,
+	}
+
+	repo.On("GetOperation", ctx, expected.ID).Return(expected, nil)
+
+	u := usecase.New(repo, txm)
+	op, err := u.Operation(ctx, expected.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, expected, op)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_Operation_NotFound(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	operationID := uuid.New()
+
+	repo.On("GetOperation", ctx, operationID).Return(usecase.Operation{}, walleterror.ErrOperationNotFound)
+
+	u := usecase.New(repo, txm)
+	_, err := u.Operation(ctx, operationID)
+
+	require.ErrorIs(t, err, walleterror.ErrOperationNotFound)
 	repo.AssertExpectations(t)
 }
 
@@ -94,27 +207,46 @@ func TestUsecase_Deposit_Success(t *testing.T) {
 	walletID := testUUID()
 	amount := int64(500)
 	currentBalance := int64(1000)
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 
 	setupTxManager(txm)
 	repo.
 		On("GetBalanceForUpdate", ctx, walletID).
 		Return(currentBalance, nil)
-	repo.
-		On("UpdateBalance", ctx, walletID, currentBalance+amount).
-		Return(nil)
 	repo.
 		On("SaveOperation", ctx, mock.MatchedBy(func(op usecase.Operation) bool {
 			return op.WalletID == walletID &&
 				op.Type == "DEPOSIT" &&
 				op.Amount == amount &&
+				op.BalanceBefore == currentBalance &&
+				op.BalanceAfter == currentBalance+amount &&
 				op.ID != uuid.Nil
 		})).
+		Return(createdAt, nil)
+	repo.
+		On("SaveEvent", ctx, mock.MatchedBy(func(e model.Event) bool {
+			var payload model.OperationResponse
+			return e.Type == "DEPOSIT" &&
+				e.WalletID == walletID &&
+				json.Unmarshal(e.Payload, &payload) == nil &&
+				payload.ID == e.ID &&
+				payload.BalanceAfter == currentBalance+amount &&
+				payload.CreatedAt.Equal(createdAt)
+		})).
+		Return(nil)
+	repo.On("NotifyWalletChanged", ctx, walletID).Return(nil)
+	repo.
+		On("PostEntry", ctx, entryPosting(walletID, amount)).
 		Return(nil)
 
 	u := usecase.New(repo, txm)
-	err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
+	receipt, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
 
 	require.NoError(t, err)
+	assert.Equal(t, currentBalance+amount, receipt.BalanceAfter)
+	assert.Equal(t, walletID, receipt.Operation.WalletID)
+	assert.Equal(t, "DEPOSIT", receipt.Operation.Type)
+	assert.Equal(t, createdAt, receipt.Operation.CreatedAt)
 	repo.AssertExpectations(t)
 	txm.AssertExpectations(t)
 }
@@ -133,15 +265,15 @@ func TestUsecase_Deposit_WalletNotFound(t *testing.T) {
 		Return(int64(0), walleterror.ErrWalletNotFound)
 
 	u := usecase.New(repo, txm)
-	err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
 
 	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
-	repo.AssertNotCalled(t, "UpdateBalance")
+	repo.AssertNotCalled(t, "PostEntry")
 	repo.AssertNotCalled(t, "SaveOperation")
 	repo.AssertExpectations(t)
 }
 
-func TestUsecase_Deposit_UpdateBalanceError(t *testing.T) {
+func TestUsecase_Deposit_PostEntryError(t *testing.T) {
 	repo := new(mocks.WalletRepository)
 	txm := new(mocks.TxManager)
 	ctx := context.Background()
@@ -156,14 +288,39 @@ func TestUsecase_Deposit_UpdateBalanceError(t *testing.T) {
 		On("GetBalanceForUpdate", ctx, walletID).
 		Return(currentBalance, nil)
 	repo.
-		On("UpdateBalance", ctx, walletID, currentBalance+amount).
+		On("SaveOperation", ctx, mock.Anything).
+		Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.
+		On("PostEntry", ctx, entryPosting(walletID, amount)).
 		Return(dbErr)
 
 	u := usecase.New(repo, txm)
-	err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
 
 	require.ErrorIs(t, err, dbErr)
-	repo.AssertNotCalled(t, "SaveOperation")
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_Deposit_SaveEventError(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	dbErr := errors.New("outbox is unavailable")
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(100), nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(dbErr)
+
+	u := usecase.New(repo, txm)
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 50})
+
+	assert.ErrorIs(t, err, dbErr)
+	repo.AssertNotCalled(t, "PostEntry")
 	repo.AssertExpectations(t)
 }
 
@@ -181,19 +338,17 @@ func TestUsecase_Deposit_SaveOperationError(t *testing.T) {
 	repo.
 		On("GetBalanceForUpdate", ctx, walletID).
 		Return(currentBalance, nil)
-	repo.
-		On("UpdateBalance", ctx, walletID, currentBalance+amount).
-		Return(nil)
 	repo.
 		On("SaveOperation", ctx, mock.MatchedBy(func(op usecase.Operation) bool {
 			return op.WalletID == walletID && op.Type == "DEPOSIT"
 		})).
-		Return(dbErr)
+		Return(time.Time{}, dbErr)
 
 	u := usecase.New(repo, txm)
-	err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: amount})
 
 	require.ErrorIs(t, err, dbErr)
+	repo.AssertNotCalled(t, "PostEntry")
 	repo.AssertExpectations(t)
 }
 
@@ -207,27 +362,38 @@ func TestUsecase_Withdraw_Success(t *testing.T) {
 	walletID := testUUID()
 	amount := int64(200)
 	currentBalance := int64(1000)
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 
 	setupTxManager(txm)
 	repo.
 		On("GetBalanceForUpdate", ctx, walletID).
 		Return(currentBalance, nil)
 	repo.
-		On("UpdateBalance", ctx, walletID, currentBalance-amount).
-		Return(nil)
+		On("GetHeldAmount", ctx, walletID).
+		Return(int64(0), nil)
 	repo.
 		On("SaveOperation", ctx, mock.MatchedBy(func(op usecase.Operation) bool {
 			return op.WalletID == walletID &&
 				op.Type == "WITHDRAW" &&
 				op.Amount == amount &&
+				op.BalanceBefore == currentBalance &&
+				op.BalanceAfter == currentBalance-amount &&
 				op.ID != uuid.Nil
 		})).
+		Return(createdAt, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.
+		On("PostEntry", ctx, entryPosting(walletID, -amount)).
 		Return(nil)
 
 	u := usecase.New(repo, txm)
-	err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
+	receipt, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
 
 	require.NoError(t, err)
+	assert.Equal(t, currentBalance-amount, receipt.BalanceAfter)
+	assert.Equal(t, "WITHDRAW", receipt.Operation.Type)
+	assert.Equal(t, createdAt, receipt.Operation.CreatedAt)
 	repo.AssertExpectations(t)
 	txm.AssertExpectations(t)
 }
@@ -245,12 +411,34 @@ func TestUsecase_Withdraw_InsufficientFunds(t *testing.T) {
 	repo.
 		On("GetBalanceForUpdate", ctx, walletID).
 		Return(currentBalance, nil)
+	repo.
+		On("GetHeldAmount", ctx, walletID).
+		Return(int64(0), nil)
+
+	u := usecase.New(repo, txm)
+	_, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
+
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+	repo.AssertNotCalled(t, "PostEntry")
+	repo.AssertNotCalled(t, "SaveOperation")
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_Withdraw_HeldFundsUnavailable(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(1000), nil)
+	repo.On("GetHeldAmount", ctx, walletID).Return(int64(900), nil)
 
 	u := usecase.New(repo, txm)
-	err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
+	_, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 200})
 
 	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
-	repo.AssertNotCalled(t, "UpdateBalance")
 	repo.AssertNotCalled(t, "SaveOperation")
 	repo.AssertExpectations(t)
 }
@@ -269,15 +457,15 @@ func TestUsecase_Withdraw_WalletNotFound(t *testing.T) {
 		Return(int64(0), walleterror.ErrWalletNotFound)
 
 	u := usecase.New(repo, txm)
-	err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
+	_, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
 
 	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
-	repo.AssertNotCalled(t, "UpdateBalance")
+	repo.AssertNotCalled(t, "PostEntry")
 	repo.AssertNotCalled(t, "SaveOperation")
 	repo.AssertExpectations(t)
 }
 
-func TestUsecase_Withdraw_UpdateBalanceError(t *testing.T) {
+func TestUsecase_Withdraw_PostEntryError(t *testing.T) {
 	repo := new(mocks.WalletRepository)
 	txm := new(mocks.TxManager)
 	ctx := context.Background()
@@ -285,20 +473,447 @@ func TestUsecase_Withdraw_UpdateBalanceError(t *testing.T) {
 	walletID := testUUID()
 	amount := int64(200)
 	currentBalance := int64(1000)
-	dbErr := errors.New("update failed")
 
 	setupTxManager(txm)
 	repo.
 		On("GetBalanceForUpdate", ctx, walletID).
 		Return(currentBalance, nil)
 	repo.
-		On("UpdateBalance", ctx, walletID, currentBalance-amount).
-		Return(dbErr)
+		On("GetHeldAmount", ctx, walletID).
+		Return(int64(0), nil)
+	repo.
+		On("SaveOperation", ctx, mock.Anything).
+		Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	// Ограничение CHECK (balance >= 0) сработало при проводке.
+	repo.
+		On("PostEntry", ctx, entryPosting(walletID, -amount)).
+		Return(walleterror.ErrInsufficientFunds)
 
 	u := usecase.New(repo, txm)
-	err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
+	_, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: amount})
 
-	require.ErrorIs(t, err, dbErr)
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+	repo.AssertExpectations(t)
+}
+
+// --- CreateWallet ---
+
+func TestUsecase_CreateWallet_WithOpeningBalance(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	amount := int64(500)
+
+	setupTxManager(txm)
+	repo.
+		On("CreateWallet", ctx, walletID).
+		Return(nil)
+	repo.
+		On("SaveOperation", ctx, mock.MatchedBy(func(op usecase.Operation) bool {
+			return op.WalletID == walletID &&
+				op.Type == "DEPOSIT" &&
+				op.Amount == amount &&
+				op.ID != uuid.Nil
+		})).
+		Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.
+		On("PostEntry", ctx, entryPosting(model.ExternalFundingAccountID, -amount)).
+		Return(nil)
+
+	u := usecase.New(repo, txm)
+	id, err := u.CreateWallet(ctx, model.CreateWalletInput{WalletID: walletID, Balance: amount})
+
+	require.NoError(t, err)
+	assert.Equal(t, walletID, id)
+	repo.AssertExpectations(t)
+	txm.AssertExpectations(t)
+}
+
+func TestUsecase_CreateWallet_GeneratesID(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	setupTxManager(txm)
+	repo.
+		On("CreateWallet", ctx, mock.AnythingOfType("uuid.UUID")).
+		Return(nil)
+
+	u := usecase.New(repo, txm)
+	id, err := u.CreateWallet(ctx, model.CreateWalletInput{})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, id)
+	repo.AssertNotCalled(t, "SaveOperation")
+	repo.AssertNotCalled(t, "PostEntry")
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_CreateWallet_AlreadyExists(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.
+		On("CreateWallet", ctx, walletID).
+		Return(walleterror.ErrWalletAlreadyExists)
+
+	u := usecase.New(repo, txm)
+	id, err := u.CreateWallet(ctx, model.CreateWalletInput{WalletID: walletID, Balance: 100})
+
+	require.ErrorIs(t, err, walleterror.ErrWalletAlreadyExists)
+	assert.Equal(t, uuid.Nil, id)
+	repo.AssertNotCalled(t, "SaveOperation")
+	repo.AssertExpectations(t)
+}
+
+// --- Transfer ---
+
+func TestUsecase_Transfer_Success(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	// from > to по байтам, поэтому первым должен блокироваться получатель.
+	from := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	to := testUUID()
+	amount := int64(300)
+
+	setupTxManager(txm)
+	mock.InOrder(
+		repo.On("GetBalanceForUpdate", ctx, to).Return(int64(100), nil),
+		repo.On("GetBalanceForUpdate", ctx, from).Return(int64(1000), nil),
+	)
+	repo.On("GetHeldAmount", ctx, from).Return(int64(0), nil)
+
+	var saved []usecase.Operation
+	repo.
+		On("SaveOperation", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			saved = append(saved, args.Get(1).(usecase.Operation))
+		}).
+		Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+
+	var entry model.JournalEntry
+	repo.
+		On("PostEntry", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			entry = args.Get(1).(model.JournalEntry)
+		}).
+		Return(nil)
+
+	u := usecase.New(repo, txm)
+	transferID, err := u.Transfer(ctx, model.TransferInput{FromWalletID: from, ToWalletID: to, Amount: amount})
+
+	require.NoError(t, err)
+	assert.Equal(t, transferID, entry.ID)
+	assert.True(t, entry.Balanced())
+	assert.ElementsMatch(t, []model.Posting{
+		{AccountID: from, Amount: -amount},
+		{AccountID: to, Amount: amount},
+	}, entry.Postings)
+	require.Len(t, saved, 2)
+	assert.Equal(t, from, saved[0].WalletID)
+	assert.Equal(t, "TRANSFER_OUT", saved[0].Type)
+	assert.Equal(t, to, saved[1].WalletID)
+	assert.Equal(t, "TRANSFER_IN", saved[1].Type)
+	assert.Equal(t, int64(1000), saved[0].BalanceBefore)
+	assert.Equal(t, int64(700), saved[0].BalanceAfter)
+	assert.Equal(t, int64(100), saved[1].BalanceBefore)
+	assert.Equal(t, int64(400), saved[1].BalanceAfter)
+	for _, op := range saved {
+		assert.Equal(t, amount, op.Amount)
+		assert.Equal(t, uuid.NullUUID{UUID: transferID, Valid: true}, op.TransferID)
+	}
+	repo.AssertExpectations(t)
+	txm.AssertExpectations(t)
+}
+
+func TestUsecase_Transfer_InsufficientFunds(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	from := testUUID()
+	to := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, from).Return(int64(100), nil)
+	repo.On("GetBalanceForUpdate", ctx, to).Return(int64(0), nil)
+	repo.On("GetHeldAmount", ctx, from).Return(int64(0), nil)
+
+	u := usecase.New(repo, txm)
+	transferID, err := u.Transfer(ctx, model.TransferInput{FromWalletID: from, ToWalletID: to, Amount: 500})
+
+	require.ErrorIs(t, err, walleterror.ErrInsufficientFunds)
+	assert.Equal(t, uuid.Nil, transferID)
+	repo.AssertNotCalled(t, "PostEntry")
 	repo.AssertNotCalled(t, "SaveOperation")
 	repo.AssertExpectations(t)
 }
+
+func TestUsecase_Transfer_WalletNotFound(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	from := testUUID()
+	to := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+
+	setupTxManager(txm)
+	repo.On("GetBalanceForUpdate", ctx, from).Return(int64(100), nil)
+	repo.On("GetBalanceForUpdate", ctx, to).Return(int64(0), walleterror.ErrWalletNotFound)
+
+	u := usecase.New(repo, txm)
+	_, err := u.Transfer(ctx, model.TransferInput{FromWalletID: from, ToWalletID: to, Amount: 50})
+
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	repo.AssertNotCalled(t, "PostEntry")
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_Transfer_SameWallet(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	u := usecase.New(repo, txm)
+	_, err := u.Transfer(ctx, model.TransferInput{FromWalletID: walletID, ToWalletID: walletID, Amount: 50})
+
+	require.ErrorIs(t, err, walleterror.ErrSameWallet)
+	txm.AssertNotCalled(t, "RunInTx")
+}
+
+// --- ListOperations ---
+
+func testOperations(walletID uuid.UUID, n int) []usecase.Operation {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ops := make([]usecase.Operation, 0, n)
+	for i := 0; i < n; i++ {
+		ops = append(ops, usecase.Operation{
+			ID:        uuid.New(),
+			WalletID:  walletID,
+			Type:      "DEPOSIT",
+			Amount:    int64(i + 1),
+			CreatedAt: base.Add(-time.Duration(i) * time.Minute),
+			Seq:       int64(n - i),
+		})
+	}
+	return ops
+}
+
+func TestUsecase_ListOperations_NextCursor(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	ops := testOperations(walletID, 3)
+
+	repo.On("GetBalance", ctx, walletID).Return(int64(0), nil)
+	repo.
+		On("ListOperations", ctx, model.OperationFilter{WalletID: walletID, Limit: 3}).
+		Return(ops, nil).
+		Once()
+
+	u := usecase.New(repo, txm)
+	page, err := u.ListOperations(ctx, model.ListOperationsInput{WalletID: walletID, Limit: 2})
+
+	require.NoError(t, err)
+	assert.Equal(t, ops[:2], page.Operations)
+	require.NotEmpty(t, page.NextCursor)
+
+	repo.
+		On("ListOperations", ctx, model.OperationFilter{
+			WalletID: walletID,
+			After:    &model.OperationCursor{CreatedAt: ops[1].CreatedAt, Seq: ops[1].Seq},
+			Limit:    3,
+		}).
+		Return(ops[2:], nil).
+		Once()
+
+	page, err = u.ListOperations(ctx, model.ListOperationsInput{WalletID: walletID, Limit: 2, Cursor: page.NextCursor})
+
+	require.NoError(t, err)
+	assert.Equal(t, ops[2:], page.Operations)
+	assert.Empty(t, page.NextCursor)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_ListOperations_InvalidCursor(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	u := usecase.New(repo, txm)
+	_, err := u.ListOperations(ctx, model.ListOperationsInput{WalletID: testUUID(), Cursor: "garbage"})
+
+	require.ErrorIs(t, err, walleterror.ErrInvalidCursor)
+	repo.AssertNotCalled(t, "ListOperations")
+}
+
+func TestUsecase_ListOperations_LegacyCursor(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	// Курсор прежнего формата "время|id операции".
+	legacy := base64.RawURLEncoding.EncodeToString([]byte("2025-01-01T12:00:00Z|" + uuid.NewString()))
+
+	u := usecase.New(repo, txm)
+	_, err := u.ListOperations(ctx, model.ListOperationsInput{WalletID: testUUID(), Cursor: legacy})
+
+	require.ErrorIs(t, err, walleterror.ErrInvalidCursor)
+	repo.AssertNotCalled(t, "ListOperations")
+}
+
+func TestUsecase_ListOperations_InvalidLimit(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	u := usecase.New(repo, txm)
+	_, err := u.ListOperations(ctx, model.ListOperationsInput{WalletID: testUUID(), Limit: usecase.MaxPageLimit + 1})
+
+	require.ErrorIs(t, err, walleterror.ErrInvalidLimit)
+	repo.AssertNotCalled(t, "ListOperations")
+}
+
+func TestUsecase_ListOperations_InvalidTimeRange(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	now := time.Now()
+
+	u := usecase.New(repo, txm)
+	_, err := u.ListOperations(ctx, model.ListOperationsInput{WalletID: testUUID(), From: now, To: now.Add(-time.Hour)})
+
+	require.ErrorIs(t, err, walleterror.ErrInvalidTimeRange)
+	repo.AssertNotCalled(t, "ListOperations")
+}
+
+func TestUsecase_ListOperations_WalletNotFound(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	repo.On("GetBalance", ctx, walletID).Return(int64(0), walleterror.ErrWalletNotFound)
+
+	u := usecase.New(repo, txm)
+	_, err := u.ListOperations(ctx, model.ListOperationsInput{WalletID: walletID})
+
+	require.ErrorIs(t, err, walleterror.ErrWalletNotFound)
+	repo.AssertNotCalled(t, "ListOperations")
+}
+
+// --- Idempotency ---
+
+func TestUsecase_Deposit_IdempotencyKeyClaimed(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	ttl := time.Hour
+
+	setupTxManager(txm)
+	repo.
+		On("ClaimIdempotencyKey", ctx, "key-1", mock.AnythingOfType("string"), ttl).
+		Return(model.IdempotencyRecord{}, false, nil)
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(100), nil)
+	repo.On("PostEntry", ctx, entryPosting(walletID, 50)).Return(nil)
+	repo.On("SaveOperation", ctx, mock.Anything).Return(time.Time{}, nil)
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil)
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil)
+	repo.On("SaveIdempotencyResponse", ctx, "key-1", mock.AnythingOfType("[]uint8")).Return(nil)
+
+	u := usecase.New(repo, txm, usecase.WithIdempotencyTTL(ttl))
+	_, err := u.Deposit(ctx, model.DepositInput{WalletID: walletID, Amount: 50, IdempotencyKey: "key-1"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestUsecase_Deposit_IdempotencyKeyReplayed(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+	in := model.DepositInput{WalletID: walletID, Amount: 50, IdempotencyKey: "key-1"}
+
+	var (
+		fp       string
+		response []byte
+	)
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	setupTxManager(txm)
+	repo.
+		On("ClaimIdempotencyKey", ctx, "key-1", mock.AnythingOfType("string"), usecase.DefaultIdempotencyTTL).
+		Run(func(args mock.Arguments) { fp = args.String(2) }).
+		Return(model.IdempotencyRecord{}, false, nil).
+		Once()
+	repo.On("GetBalanceForUpdate", ctx, walletID).Return(int64(100), nil).Once()
+	repo.On("SaveOperation", ctx, mock.Anything).Return(createdAt, nil).Once()
+	repo.On("SaveEvent", ctx, mock.Anything).Return(nil).Once()
+	repo.On("NotifyWalletChanged", ctx, mock.Anything).Return(nil).Once()
+	repo.On("PostEntry", ctx, entryPosting(walletID, 50)).Return(nil).Once()
+	repo.
+		On("SaveIdempotencyResponse", ctx, "key-1", mock.Anything).
+		Run(func(args mock.Arguments) { response = args.Get(2).([]byte) }).
+		Return(nil).
+		Once()
+
+	u := usecase.New(repo, txm)
+	first, err := u.Deposit(ctx, in)
+	require.NoError(t, err)
+
+	repo.
+		On("ClaimIdempotencyKey", ctx, "key-1", fp, usecase.DefaultIdempotencyTTL).
+		Return(model.IdempotencyRecord{Key: "key-1", Fingerprint: fp, Response: response}, true, nil).
+		Once()
+
+	replayed, err := u.Deposit(ctx, in)
+	require.NoError(t, err)
+	assert.Equal(t, first, replayed)
+	repo.AssertExpectations(t)
+	repo.AssertNumberOfCalls(t, "PostEntry", 1)
+}
+
+func TestUsecase_Withdraw_IdempotencyKeyReused(t *testing.T) {
+	repo := new(mocks.WalletRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	walletID := testUUID()
+
+	setupTxManager(txm)
+	repo.
+		On("ClaimIdempotencyKey", ctx, "key-1", mock.AnythingOfType("string"), usecase.DefaultIdempotencyTTL).
+		Return(model.IdempotencyRecord{Key: "key-1", Fingerprint: "other"}, true, nil)
+
+	u := usecase.New(repo, txm)
+	_, err := u.Withdraw(ctx, model.WithdrawInput{WalletID: walletID, Amount: 50, IdempotencyKey: "key-1"})
+
+	require.ErrorIs(t, err, walleterror.ErrIdempotencyKeyReused)
+	repo.AssertNotCalled(t, "GetBalanceForUpdate")
+	repo.AssertNotCalled(t, "PostEntry")
+	repo.AssertExpectations(t)
+}
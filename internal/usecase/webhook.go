@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultWebhookMaxAttempts ...
+	DefaultWebhookMaxAttempts = 10
+	// DefaultWebhookMinBackoff ...
+	DefaultWebhookMinBackoff = 5 * time.Second
+	// DefaultWebhookMaxBackoff ...
+	DefaultWebhookMaxBackoff = time.Hour
+	// DefaultWebhookBatchSize ...
+	DefaultWebhookBatchSize = 50
+	// DefaultWebhookAttemptsLimit ...
+	DefaultWebhookAttemptsLimit = 50
+	// MaxWebhookAttemptsLimit ...
+	MaxWebhookAttemptsLimit = 200
+	// DefaultWebhookLease ...
+	DefaultWebhookLease = 5 * time.Minute
+
+	webhookSecretBytes = 32
+)
+
+// WebhookRepository ...
+type WebhookRepository interface {
+	// CreateWebhook ...
+	CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error)
+	// GetWebhook ...
+	GetWebhook(ctx context.Context, id uuid.UUID) (model.Webhook, error)
+	// ListWebhooks ...
+	ListWebhooks(ctx context.Context) ([]model.Webhook, error)
+	// DeleteWebhook ...
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	// EnqueueWebhookDeliveries ...
+	EnqueueWebhookDeliveries(ctx context.Context, event model.Event, payload []byte) (int, error)
+	// ClaimDueWebhookDeliveries ...
+	ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	// RecordWebhookAttempt ...
+	RecordWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt, retryIn time.Duration) error
+	// ListWebhookAttempts ...
+	ListWebhookAttempts(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookAttempt, error)
+	// ClaimWebhookEvents забирает до limit событий outbox, ещё не
+	// разосланных подпискам, и отмечает их разосланными. Отметка
+	// откатывается вместе с транзакцией.
+	ClaimWebhookEvents(ctx context.Context, limit int) ([]model.Event, error)
+}
+
+// WebhookSender отправляет подписанный payload на url. Возвращает код ответа
+// (0, если ответа не было) и ошибку, если доставка не удалась.
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, payload []byte) (int, error)
+}
+
+// WebhookUsecase управляет подписками и доставляет им события.
+type WebhookUsecase struct {
+	repo        WebhookRepository
+	txm         TxManager
+	sender      WebhookSender
+	maxAttempts int
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	batchSize   int
+	lease       time.Duration
+}
+
+// WebhookOption ...
+type WebhookOption func(*WebhookUsecase)
+
+// WithWebhookMaxAttempts число попыток, после которого доставка уходит в DEAD.
+func WithWebhookMaxAttempts(n int) WebhookOption {
+	return func(u *WebhookUsecase) {
+		if n > 0 {
+			u.maxAttempts = n
+		}
+	}
+}
+
+// WithWebhookBackoff ...
+func WithWebhookBackoff(minBackoff, maxBackoff time.Duration) WebhookOption {
+	return func(u *WebhookUsecase) {
+		if minBackoff > 0 {
+			u.minBackoff = minBackoff
+		}
+		if maxBackoff > 0 {
+			u.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// WithWebhookBatchSize ...
+func WithWebhookBatchSize(n int) WebhookOption {
+	return func(u *WebhookUsecase) {
+		if n > 0 {
+			u.batchSize = n
+		}
+	}
+}
+
+// WithWebhookLease задаёт, на сколько воркер забирает пачку доставок.
+// Отправка пачки не длится дольше аренды.
+func WithWebhookLease(d time.Duration) WebhookOption {
+	return func(u *WebhookUsecase) {
+		if d > 0 {
+			u.lease = d
+		}
+	}
+}
+
+// NewWebhook ...
+func NewWebhook(repo WebhookRepository, txm TxManager, sender WebhookSender, opts ...WebhookOption) *WebhookUsecase {
+	u := &WebhookUsecase{
+		repo:        repo,
+		txm:         txm,
+		sender:      sender,
+		maxAttempts: DefaultWebhookMaxAttempts,
+		minBackoff:  DefaultWebhookMinBackoff,
+		maxBackoff:  DefaultWebhookMaxBackoff,
+		batchSize:   DefaultWebhookBatchSize,
+		lease:       DefaultWebhookLease,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+// BatchSize ...
+func (u *WebhookUsecase) BatchSize() int {
+	return u.batchSize
+}
+
+// CreateWebhook ...
+func (u *WebhookUsecase) CreateWebhook(ctx context.Context, in model.CreateWebhookInput) (model.Webhook, error) {
+	for _, id := range in.WalletIDs {
+		if id == uuid.Nil {
+			return model.Webhook{}, walleterror.ErrInvalidValletID
+		}
+	}
+
+	secret := in.Secret
+	if secret == "" {
+		var err error
+		secret, err = newWebhookSecret()
+		if err != nil {
+			return model.Webhook{}, err
+		}
+	}
+
+	return u.repo.CreateWebhook(ctx, model.Webhook{
+		ID:         uuid.New(),
+		URL:        in.URL,
+		EventTypes: in.EventTypes,
+		WalletIDs:  in.WalletIDs,
+		Secret:     secret,
+	})
+}
+
+// Webhooks ...
+func (u *WebhookUsecase) Webhooks(ctx context.Context) ([]model.Webhook, error) {
+	return u.repo.ListWebhooks(ctx)
+}
+
+// DeleteWebhook ...
+func (u *WebhookUsecase) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	return u.repo.DeleteWebhook(ctx, id)
+}
+
+// WebhookAttempts последние попытки доставки по подписке.
+func (u *WebhookUsecase) WebhookAttempts(ctx context.Context, webhookID uuid.UUID, limit int) ([]model.WebhookAttempt, error) {
+	if limit == 0 {
+		limit = DefaultWebhookAttemptsLimit
+	}
+	if limit < 0 || limit > MaxWebhookAttemptsLimit {
+		return nil, walleterror.ErrInvalidLimit
+	}
+
+	if _, err := u.repo.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	return u.repo.ListWebhookAttempts(ctx, webhookID, limit)
+}
+
+// EnqueuePending рассылает подпискам очередную пачку событий outbox и
+// возвращает её размер. Рассылка идёт своим курсором, независимо от
+// доставки внешнему publisher: его сбой не задерживает вебхуки. Захват
+// пачки и постановка доставок идут в одной транзакции.
+func (u *WebhookUsecase) EnqueuePending(ctx context.Context) (int, error) {
+	var n int
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		events, err := u.repo.ClaimWebhookEvents(ctx, u.batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := u.Publish(ctx, event); err != nil {
+				return fmt.Errorf("enqueue event %d: %w", event.Sequence, err)
+			}
+		}
+		n = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// Publish реализует Publisher: ставит событие в очередь доставки каждой
+// подходящей подписке. Повторная постановка того же события доставок не
+// создаёт.
+func (u *WebhookUsecase) Publish(ctx context.Context, event model.Event) error {
+	payload, err := json.Marshal(event.Message())
+	if err != nil {
+		return fmt.Errorf("marshal webhook payload: %w", err)
+	}
+
+	_, err = u.repo.EnqueueWebhookDeliveries(ctx, event, payload)
+	return err
+}
+
+// DeliverPending отправляет доставки, которым пора, и возвращает число
+// сделанных попыток. Неудачная попытка не считается ошибкой: она
+// записывается, а доставка откладывается с экспоненциальной задержкой или,
+// если попытки кончились, уходит в DEAD.
+//
+// Пачка забирается в аренду короткой транзакцией, а запросы к получателям
+// идут уже вне её, и каждая попытка записывается сразу после отправки.
+// Доставки, до которых не дошла очередь за время аренды, после неё
+// забирает следующий захват.
+func (u *WebhookUsecase) DeliverPending(ctx context.Context) (int, error) {
+	// Срок считается до захвата, чтобы отправка гарантированно
+	// закончилась раньше аренды в базе.
+	deadline := time.Now().Add(u.lease)
+
+	var deliveries []model.WebhookDelivery
+	err := u.txm.RunInTx(ctx, func(ctx context.Context) error {
+		var err error
+		deliveries, err = u.repo.ClaimDueWebhookDeliveries(ctx, u.batchSize, u.lease)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sendCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	var attempted int
+	for _, d := range deliveries {
+		if err := u.deliver(ctx, sendCtx, d); err != nil {
+			return attempted, err
+		}
+		attempted++
+	}
+
+	return attempted, nil
+}
+
+// deliver отправляет доставку с контекстом аренды sendCtx и записывает
+// попытку. Оборванная концом аренды отправка попыткой не считается.
+func (u *WebhookUsecase) deliver(ctx, sendCtx context.Context, d model.WebhookDelivery) error {
+	started := time.Now()
+	code, sendErr := u.sender.Send(sendCtx, d.URL, d.Secret, d.Payload)
+	if sendErr != nil && sendCtx.Err() != nil {
+		return fmt.Errorf("send delivery %d: %w", d.ID, sendCtx.Err())
+	}
+
+	attempt := model.WebhookAttempt{
+		DeliveryID: d.ID,
+		WebhookID:  d.WebhookID,
+		EventID:    d.EventID,
+		EventType:  d.EventType,
+		Attempt:    d.Attempts + 1,
+		StatusCode: code,
+		Duration:   time.Since(started),
+		Status:     model.WebhookDeliveryDelivered,
+	}
+
+	var retryIn time.Duration
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+		attempt.Status = model.WebhookDeliveryPending
+		if attempt.Attempt >= u.maxAttempts {
+			attempt.Status = model.WebhookDeliveryDead
+		}
+		retryIn = u.backoff(d.Attempts)
+	}
+
+	return u.repo.RecordWebhookAttempt(ctx, attempt, retryIn)
+}
+
+// backoff задержка после attempts предыдущих неудачных попыток.
+func (u *WebhookUsecase) backoff(attempts int) time.Duration {
+	d := u.minBackoff
+	for range attempts {
+		d *= 2
+		if d >= u.maxBackoff {
+			return u.maxBackoff
+		}
+	}
+	return min(d, u.maxBackoff)
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,329 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	walleterror "wallet/internal/error"
+	"wallet/internal/mocks"
+	"wallet/internal/model"
+	"wallet/internal/usecase"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func dueDelivery(attempts int) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:        1,
+		WebhookID: uuid.New(),
+		URL:       "https://partner.example/hook",
+		Secret:    "secret",
+		EventID:   uuid.New(),
+		EventType: "DEPOSIT",
+		Payload:   json.RawMessage(`{}`),
+		Attempts:  attempts,
+	}
+}
+
+// --- CreateWebhook ---
+
+func TestWebhook_CreateWebhook_GeneratesSecret(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	ctx := context.Background()
+
+	repo.
+		On("CreateWebhook", ctx, mock.MatchedBy(func(h model.Webhook) bool {
+			return h.ID != uuid.Nil &&
+				h.URL == "https://partner.example/hook" &&
+				len(h.Secret) == 64
+		})).
+		Return(func(_ context.Context, h model.Webhook) (model.Webhook, error) {
+			return h, nil
+		})
+
+	u := usecase.NewWebhook(repo, new(mocks.TxManager), new(mocks.WebhookSender))
+	hook, err := u.CreateWebhook(ctx, model.CreateWebhookInput{URL: "https://partner.example/hook"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, hook.Secret)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_CreateWebhook_KeepsSecret(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	ctx := context.Background()
+
+	repo.
+		On("CreateWebhook", ctx, mock.MatchedBy(func(h model.Webhook) bool {
+			return h.Secret == "my-secret"
+		})).
+		Return(model.Webhook{Secret: "my-secret"}, nil)
+
+	u := usecase.NewWebhook(repo, new(mocks.TxManager), new(mocks.WebhookSender))
+	_, err := u.CreateWebhook(ctx, model.CreateWebhookInput{URL: "https://partner.example/hook", Secret: "my-secret"})
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+// --- WebhookAttempts ---
+
+func TestWebhook_WebhookAttempts_NotFound(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	ctx := context.Background()
+	id := uuid.New()
+
+	repo.On("GetWebhook", ctx, id).Return(model.Webhook{}, walleterror.ErrWebhookNotFound)
+
+	u := usecase.NewWebhook(repo, new(mocks.TxManager), new(mocks.WebhookSender))
+	_, err := u.WebhookAttempts(ctx, id, 0)
+
+	assert.ErrorIs(t, err, walleterror.ErrWebhookNotFound)
+	repo.AssertNotCalled(t, "ListWebhookAttempts")
+}
+
+func TestWebhook_WebhookAttempts_DefaultLimit(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	ctx := context.Background()
+	id := uuid.New()
+
+	repo.On("GetWebhook", ctx, id).Return(model.Webhook{ID: id}, nil)
+	repo.On("ListWebhookAttempts", ctx, id, usecase.DefaultWebhookAttemptsLimit).Return([]model.WebhookAttempt{}, nil)
+
+	u := usecase.NewWebhook(repo, new(mocks.TxManager), new(mocks.WebhookSender))
+	_, err := u.WebhookAttempts(ctx, id, 0)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_WebhookAttempts_LimitTooLarge(t *testing.T) {
+	u := usecase.NewWebhook(new(mocks.WebhookRepository), new(mocks.TxManager), new(mocks.WebhookSender))
+	_, err := u.WebhookAttempts(context.Background(), uuid.New(), usecase.MaxWebhookAttemptsLimit+1)
+
+	assert.ErrorIs(t, err, walleterror.ErrInvalidLimit)
+}
+
+// --- Publish ---
+
+func TestWebhook_Publish_EnqueuesMessage(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	ctx := context.Background()
+
+	event := model.Event{
+		Sequence: 3,
+		ID:       uuid.New(),
+		Type:     "WITHDRAW",
+		WalletID: testUUID(),
+		Payload:  json.RawMessage(`{"amount":10}`),
+	}
+
+	repo.
+		On("EnqueueWebhookDeliveries", ctx, event, mock.MatchedBy(func(payload []byte) bool {
+			var msg model.EventMessage
+			return json.Unmarshal(payload, &msg) == nil &&
+				msg.ID == event.ID &&
+				msg.Sequence == 3 &&
+				msg.Type == "WITHDRAW"
+		})).
+		Return(1, nil)
+
+	u := usecase.NewWebhook(repo, new(mocks.TxManager), new(mocks.WebhookSender))
+
+	require.NoError(t, u.Publish(ctx, event))
+	repo.AssertExpectations(t)
+}
+
+// --- EnqueuePending ---
+
+func TestWebhook_EnqueuePending_EnqueuesInTx(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(2)
+
+	var inTx bool
+	txm.
+		On("RunInTx", mock.Anything, mock.AnythingOfType("func(context.Context) error")).
+		Return(func(ctx context.Context, fn func(context.Context) error, _ ...usecase.TxOption) error {
+			inTx = true
+			defer func() { inTx = false }()
+			return fn(ctx)
+		})
+	repo.On("ClaimWebhookEvents", ctx, usecase.DefaultWebhookBatchSize).Return(events, nil)
+	for _, e := range events {
+		repo.
+			On("EnqueueWebhookDeliveries", ctx, e, mock.Anything).
+			Run(func(mock.Arguments) {
+				assert.True(t, inTx, "deliveries must be enqueued in the claim transaction")
+			}).
+			Return(1, nil).
+			Once()
+	}
+
+	u := usecase.NewWebhook(repo, txm, new(mocks.WebhookSender))
+	n, err := u.EnqueuePending(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_EnqueuePending_Error(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	events := pendingEvents(2)
+	dbErr := errors.New("db is down")
+
+	setupTxManager(txm)
+	repo.On("ClaimWebhookEvents", ctx, usecase.DefaultWebhookBatchSize).Return(events, nil)
+	repo.On("EnqueueWebhookDeliveries", ctx, events[0], mock.Anything).Return(0, dbErr)
+
+	u := usecase.NewWebhook(repo, txm, new(mocks.WebhookSender))
+	n, err := u.EnqueuePending(ctx)
+
+	assert.ErrorIs(t, err, dbErr)
+	assert.Zero(t, n)
+	repo.AssertNotCalled(t, "EnqueueWebhookDeliveries", ctx, events[1], mock.Anything)
+}
+
+// --- DeliverPending ---
+
+func TestWebhook_DeliverPending_Success(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	sender := new(mocks.WebhookSender)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	d := dueDelivery(0)
+
+	setupTxManager(txm)
+	repo.On("ClaimDueWebhookDeliveries", ctx, usecase.DefaultWebhookBatchSize, usecase.DefaultWebhookLease).Return([]model.WebhookDelivery{d}, nil)
+	sender.On("Send", mock.Anything, d.URL, d.Secret, []byte(d.Payload)).Return(200, nil)
+	repo.
+		On("RecordWebhookAttempt", ctx, mock.MatchedBy(func(a model.WebhookAttempt) bool {
+			return a.DeliveryID == d.ID &&
+				a.Attempt == 1 &&
+				a.StatusCode == 200 &&
+				a.Error == "" &&
+				a.Status == model.WebhookDeliveryDelivered
+		}), time.Duration(0)).
+		Return(nil)
+
+	u := usecase.NewWebhook(repo, txm, sender)
+	n, err := u.DeliverPending(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_DeliverPending_RetryWithBackoff(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	sender := new(mocks.WebhookSender)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	d := dueDelivery(3)
+
+	setupTxManager(txm)
+	repo.On("ClaimDueWebhookDeliveries", ctx, usecase.DefaultWebhookBatchSize, usecase.DefaultWebhookLease).Return([]model.WebhookDelivery{d}, nil)
+	sender.On("Send", mock.Anything, d.URL, d.Secret, []byte(d.Payload)).Return(503, errors.New("unexpected status 503"))
+	// Три прошлые неудачи: 1s -> 2s -> 4s -> 8s.
+	repo.
+		On("RecordWebhookAttempt", ctx, mock.MatchedBy(func(a model.WebhookAttempt) bool {
+			return a.Attempt == 4 &&
+				a.StatusCode == 503 &&
+				a.Error == "unexpected status 503" &&
+				a.Status == model.WebhookDeliveryPending
+		}), 8*time.Second).
+		Return(nil)
+
+	u := usecase.NewWebhook(repo, txm, sender, usecase.WithWebhookBackoff(time.Second, time.Minute))
+	n, err := u.DeliverPending(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_DeliverPending_DeadLetter(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	sender := new(mocks.WebhookSender)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	d := dueDelivery(4)
+
+	setupTxManager(txm)
+	repo.On("ClaimDueWebhookDeliveries", ctx, usecase.DefaultWebhookBatchSize, usecase.DefaultWebhookLease).Return([]model.WebhookDelivery{d}, nil)
+	sender.On("Send", mock.Anything, d.URL, d.Secret, []byte(d.Payload)).Return(0, errors.New("connection refused"))
+	repo.
+		On("RecordWebhookAttempt", ctx, mock.MatchedBy(func(a model.WebhookAttempt) bool {
+			return a.Attempt == 5 && a.Status == model.WebhookDeliveryDead
+		}), mock.Anything).
+		Return(nil)
+
+	u := usecase.NewWebhook(repo, txm, sender, usecase.WithWebhookMaxAttempts(5))
+	_, err := u.DeliverPending(ctx)
+
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestWebhook_DeliverPending_RecordError(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	sender := new(mocks.WebhookSender)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	d := dueDelivery(0)
+	dbErr := errors.New("db is down")
+
+	setupTxManager(txm)
+	repo.On("ClaimDueWebhookDeliveries", ctx, usecase.DefaultWebhookBatchSize, usecase.DefaultWebhookLease).Return([]model.WebhookDelivery{d, d}, nil)
+	sender.On("Send", mock.Anything, d.URL, d.Secret, []byte(d.Payload)).Return(200, nil).Once()
+	repo.On("RecordWebhookAttempt", ctx, mock.Anything, mock.Anything).Return(dbErr).Once()
+
+	u := usecase.NewWebhook(repo, txm, sender)
+	n, err := u.DeliverPending(ctx)
+
+	assert.ErrorIs(t, err, dbErr)
+	assert.Zero(t, n)
+	sender.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestWebhook_DeliverPending_LeaseExpired(t *testing.T) {
+	repo := new(mocks.WebhookRepository)
+	sender := new(mocks.WebhookSender)
+	txm := new(mocks.TxManager)
+	ctx := context.Background()
+
+	d := dueDelivery(0)
+
+	setupTxManager(txm)
+	repo.On("ClaimDueWebhookDeliveries", ctx, usecase.DefaultWebhookBatchSize, time.Millisecond).Return([]model.WebhookDelivery{d, d}, nil)
+	sender.
+		On("Send", mock.Anything, d.URL, d.Secret, []byte(d.Payload)).
+		Return(func(ctx context.Context, _, _ string, _ []byte) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}, nil)
+
+	u := usecase.NewWebhook(repo, txm, sender, usecase.WithWebhookLease(time.Millisecond))
+	n, err := u.DeliverPending(ctx)
+
+	// Оборванная отправка не записывается: доставку заберёт следующий захват.
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Zero(t, n)
+	sender.AssertNumberOfCalls(t, "Send", 1)
+	repo.AssertNotCalled(t, "RecordWebhookAttempt", mock.Anything, mock.Anything, mock.Anything)
+}
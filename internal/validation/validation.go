@@ -2,14 +2,18 @@
 package validation
 
 import (
+	"net/url"
 	"strings"
 	walleterror "wallet/internal/error"
 )
 
 var (
 	// DepositType ...
-	DepositType  = "DEPOSIT"
-	WithdrawType = "WITHDRAW"
+	DepositType     = "DEPOSIT"
+	WithdrawType    = "WITHDRAW"
+	TransferOutType = "TRANSFER_OUT"
+	TransferInType  = "TRANSFER_IN"
+	AdjustmentType  = "ADJUSTMENT"
 )
 
 func ValidationOperationType(t string) (string, error) {
@@ -26,3 +30,47 @@ func ValidationOperationType(t string) (string, error) {
 
 	}
 }
+
+// ValidationOperationTypeFilter ...
+func ValidationOperationTypeFilter(types []string) ([]string, error) {
+	var res []string
+	for _, raw := range types {
+		for _, t := range strings.Split(raw, ",") {
+			tStr := strings.TrimSpace(t)
+			switch tStr {
+			case "":
+				continue
+			case DepositType, WithdrawType, TransferOutType, TransferInType, AdjustmentType:
+				res = append(res, tStr)
+			default:
+				return nil, walleterror.ErrInvalidOperationType
+			}
+		}
+	}
+	return res, nil
+}
+
+// ValidationEventTypes проверяет типы событий подписки. Событие порождает
+// каждая операция, включая ADJUSTMENT.
+func ValidationEventTypes(types []string) ([]string, error) {
+	res := make([]string, 0, len(types))
+	for _, t := range types {
+		tStr := strings.TrimSpace(t)
+		switch tStr {
+		case DepositType, WithdrawType, TransferOutType, TransferInType, AdjustmentType:
+			res = append(res, tStr)
+		default:
+			return nil, walleterror.ErrInvalidEventType
+		}
+	}
+	return res, nil
+}
+
+// ValidationWebhookURL ...
+func ValidationWebhookURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "https" || u.Host == "" || u.User != nil {
+		return "", walleterror.ErrInvalidWebhookURL
+	}
+	return u.String(), nil
+}
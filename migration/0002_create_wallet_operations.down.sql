@@ -1 +1,2 @@
-DROP TABLE wallet_operations;
\ No newline at end of file
+DROP TABLE wallet_operations;
+DROP TYPE operation_type;
\ No newline at end of file
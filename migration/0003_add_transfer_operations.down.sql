@@ -0,0 +1,11 @@
+DROP INDEX idx_wallet_operations_transfer_id;
+
+ALTER TABLE wallet_operations DROP COLUMN transfer_id;
+
+DELETE FROM wallet_operations WHERE operation IN ('TRANSFER_OUT', 'TRANSFER_IN');
+
+ALTER TYPE operation_type RENAME TO operation_type_old;
+CREATE TYPE operation_type AS ENUM ('DEPOSIT', 'WITHDRAW');
+ALTER TABLE wallet_operations
+    ALTER COLUMN operation TYPE operation_type USING operation::text::operation_type;
+DROP TYPE operation_type_old;
\ No newline at end of file
@@ -0,0 +1,7 @@
+ALTER TYPE operation_type ADD VALUE 'TRANSFER_OUT';
+ALTER TYPE operation_type ADD VALUE 'TRANSFER_IN';
+
+ALTER TABLE wallet_operations ADD COLUMN transfer_id UUID;
+
+CREATE INDEX idx_wallet_operations_transfer_id 
+    ON wallet_operations(transfer_id);
\ No newline at end of file
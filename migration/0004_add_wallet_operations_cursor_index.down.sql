@@ -0,0 +1,4 @@
+DROP INDEX idx_wallet_operations_wallet_id_created_at;
+
+CREATE INDEX idx_wallet_operations_wallet_id 
+    ON wallet_operations(wallet_id);
\ No newline at end of file
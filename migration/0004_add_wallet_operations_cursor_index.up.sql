@@ -0,0 +1,4 @@
+DROP INDEX idx_wallet_operations_wallet_id;
+
+CREATE INDEX idx_wallet_operations_wallet_id_created_at 
+    ON wallet_operations(wallet_id, created_at DESC, id DESC);
\ No newline at end of file
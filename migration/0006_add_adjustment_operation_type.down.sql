@@ -0,0 +1,7 @@
+DELETE FROM wallet_operations WHERE operation = 'ADJUSTMENT';
+
+ALTER TYPE operation_type RENAME TO operation_type_old;
+CREATE TYPE operation_type AS ENUM ('DEPOSIT', 'WITHDRAW', 'TRANSFER_OUT', 'TRANSFER_IN');
+ALTER TABLE wallet_operations
+    ALTER COLUMN operation TYPE operation_type USING operation::text::operation_type;
+DROP TYPE operation_type_old;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TYPE operation_type ADD VALUE 'ADJUSTMENT';
\ No newline at end of file
@@ -0,0 +1,5 @@
+DELETE FROM wallet_operations WHERE amount < 0;
+
+ALTER TABLE wallet_operations
+    DROP CONSTRAINT wallet_operations_amount_check,
+    ADD CONSTRAINT wallet_operations_amount_check CHECK (amount > 0);
\ No newline at end of file
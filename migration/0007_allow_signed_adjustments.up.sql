@@ -0,0 +1,5 @@
+-- Корректирующие операции сверки могут быть отрицательными, остальные - нет.
+ALTER TABLE wallet_operations
+    DROP CONSTRAINT wallet_operations_amount_check,
+    ADD CONSTRAINT wallet_operations_amount_check
+        CHECK (amount > 0 OR (operation = 'ADJUSTMENT' AND amount <> 0));
\ No newline at end of file
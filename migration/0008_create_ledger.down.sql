@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS ledger_postings;
+DROP TABLE IF EXISTS journal_entries;
+DROP TABLE IF EXISTS ledger_accounts;
+DROP TYPE IF EXISTS ledger_account_kind;
\ No newline at end of file
@@ -0,0 +1,101 @@
+CREATE TYPE ledger_account_kind AS ENUM ('WALLET', 'SYSTEM');
+
+CREATE TABLE ledger_accounts (
+    id UUID PRIMARY KEY,
+    kind ledger_account_kind NOT NULL,
+    name TEXT NOT NULL,
+    balance BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE journal_entries (
+    id UUID PRIMARY KEY,
+    description TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id UUID NOT NULL REFERENCES journal_entries(id),
+    account_id UUID NOT NULL REFERENCES ledger_accounts(id),
+    amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX idx_ledger_postings_entry_id
+    ON ledger_postings(entry_id);
+
+CREATE INDEX idx_ledger_postings_account_id
+    ON ledger_postings(account_id);
+
+INSERT INTO ledger_accounts (id, kind, name) VALUES
+    ('00000000-0000-0000-0000-000000000001', 'SYSTEM', 'external funding'),
+    ('00000000-0000-0000-0000-000000000002', 'SYSTEM', 'fees'),
+    ('00000000-0000-0000-0000-000000000003', 'SYSTEM', 'adjustments');
+
+INSERT INTO ledger_accounts (id, kind, name, created_at)
+SELECT id, 'WALLET', 'wallet ' || id, created_at
+FROM wallets;
+
+-- Одиночные операции: проводка по кошельку и встречная по системному счёту.
+INSERT INTO journal_entries (id, description, created_at)
+SELECT id, operation::text, created_at
+FROM wallet_operations
+WHERE transfer_id IS NULL;
+
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT id, wallet_id,
+       CASE WHEN operation = 'WITHDRAW' THEN -amount ELSE amount END
+FROM wallet_operations
+WHERE transfer_id IS NULL
+UNION ALL
+SELECT id,
+       CASE WHEN operation = 'ADJUSTMENT'
+            THEN '00000000-0000-0000-0000-000000000003'::uuid
+            ELSE '00000000-0000-0000-0000-000000000001'::uuid END,
+       CASE WHEN operation = 'WITHDRAW' THEN amount ELSE -amount END
+FROM wallet_operations
+WHERE transfer_id IS NULL;
+
+-- Переводы: одна запись на transfer_id с проводками по обоим кошелькам.
+INSERT INTO journal_entries (id, description, created_at)
+SELECT transfer_id, 'TRANSFER', MIN(created_at)
+FROM wallet_operations
+WHERE transfer_id IS NOT NULL
+GROUP BY transfer_id;
+
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT transfer_id, wallet_id,
+       CASE WHEN operation = 'TRANSFER_OUT' THEN -amount ELSE amount END
+FROM wallet_operations
+WHERE transfer_id IS NOT NULL;
+
+-- Балансы, не подтверждённые операциями (например, сид-кошелёк),
+-- открываются записью против счёта корректировок.
+CREATE TEMP TABLE ledger_opening ON COMMIT DROP AS
+SELECT gen_random_uuid() AS entry_id,
+       w.id AS wallet_id,
+       w.balance - COALESCE(SUM(p.amount), 0) AS amount
+FROM wallets w
+LEFT JOIN ledger_postings p ON p.account_id = w.id
+GROUP BY w.id, w.balance
+HAVING w.balance - COALESCE(SUM(p.amount), 0) <> 0;
+
+INSERT INTO journal_entries (id, description)
+SELECT entry_id, 'OPENING_BALANCE'
+FROM ledger_opening;
+
+INSERT INTO ledger_postings (entry_id, account_id, amount)
+SELECT entry_id, wallet_id, amount
+FROM ledger_opening
+UNION ALL
+SELECT entry_id, '00000000-0000-0000-0000-000000000003'::uuid, -amount
+FROM ledger_opening;
+
+UPDATE ledger_accounts a
+SET balance = s.sum
+FROM (
+    SELECT account_id, SUM(amount) AS sum
+    FROM ledger_postings
+    GROUP BY account_id
+) s
+WHERE a.id = s.account_id;
\ No newline at end of file
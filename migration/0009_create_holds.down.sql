@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS holds;
+DROP TYPE IF EXISTS hold_status;
\ No newline at end of file
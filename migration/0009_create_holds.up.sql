@@ -0,0 +1,17 @@
+CREATE TYPE hold_status AS ENUM ('ACTIVE', 'CAPTURED', 'VOIDED');
+
+CREATE TABLE holds (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    status hold_status NOT NULL DEFAULT 'ACTIVE',
+    captured_amount BIGINT NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    operation_id UUID,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_holds_wallet_id_active
+    ON holds(wallet_id, expires_at)
+    WHERE status = 'ACTIVE';
\ No newline at end of file
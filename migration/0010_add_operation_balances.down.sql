@@ -0,0 +1,3 @@
+ALTER TABLE wallet_operations
+    DROP COLUMN balance_after,
+    DROP COLUMN balance_before;
\ No newline at end of file
@@ -0,0 +1,39 @@
+ALTER TABLE wallet_operations
+    ADD COLUMN balance_before BIGINT,
+    ADD COLUMN balance_after BIGINT;
+
+-- Баланс после последней операции совпадает с текущим балансом кошелька,
+-- для более ранних операций он восстанавливается вычитанием всех последующих.
+-- ADJUSTMENT баланс кошелька не меняет, поэтому его вклад нулевой.
+WITH signed AS (
+    SELECT o.id,
+           w.balance AS current_balance,
+           o.wallet_id,
+           o.created_at,
+           CASE
+               WHEN o.operation IN ('WITHDRAW', 'TRANSFER_OUT') THEN -o.amount
+               WHEN o.operation = 'ADJUSTMENT' THEN 0
+               ELSE o.amount
+           END AS delta
+    FROM wallet_operations o
+    JOIN wallets w ON w.id = o.wallet_id
+),
+running AS (
+    SELECT id,
+           delta,
+           current_balance - COALESCE(SUM(delta) OVER (
+               PARTITION BY wallet_id
+               ORDER BY created_at DESC, id DESC
+               ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING
+           ), 0) AS balance_after
+    FROM signed
+)
+UPDATE wallet_operations o
+SET balance_after = r.balance_after,
+    balance_before = r.balance_after - r.delta
+FROM running r
+WHERE o.id = r.id;
+
+ALTER TABLE wallet_operations
+    ALTER COLUMN balance_before SET NOT NULL,
+    ALTER COLUMN balance_after SET NOT NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_outbox_wallet_id;
\ No newline at end of file
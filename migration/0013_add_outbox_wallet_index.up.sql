@@ -0,0 +1 @@
+CREATE INDEX idx_outbox_wallet_id ON outbox(wallet_id, id);
\ No newline at end of file
@@ -0,0 +1,6 @@
+DROP INDEX idx_wallet_operations_wallet_id_created_at;
+
+CREATE INDEX idx_wallet_operations_wallet_id_created_at
+    ON wallet_operations(wallet_id, created_at DESC, id DESC);
+
+ALTER TABLE wallet_operations DROP COLUMN seq;
@@ -0,0 +1,24 @@
+-- seq задаёт порядок операций с одинаковым created_at: все операции одной
+-- транзакции получают общий NOW(). Существующие операции нумеруются в
+-- прежнем порядке истории.
+ALTER TABLE wallet_operations ADD COLUMN seq BIGINT;
+
+UPDATE wallet_operations o
+SET seq = n.seq
+FROM (
+    SELECT id, row_number() OVER (ORDER BY created_at, id) AS seq
+    FROM wallet_operations
+) n
+WHERE o.id = n.id;
+
+CREATE SEQUENCE wallet_operations_seq_seq OWNED BY wallet_operations.seq;
+SELECT setval('wallet_operations_seq_seq', COALESCE(MAX(seq), 0) + 1, false) FROM wallet_operations;
+
+ALTER TABLE wallet_operations
+    ALTER COLUMN seq SET DEFAULT nextval('wallet_operations_seq_seq'),
+    ALTER COLUMN seq SET NOT NULL;
+
+DROP INDEX idx_wallet_operations_wallet_id_created_at;
+
+CREATE INDEX idx_wallet_operations_wallet_id_created_at
+    ON wallet_operations(wallet_id, created_at DESC, seq DESC);
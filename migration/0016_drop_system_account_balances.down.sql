@@ -0,0 +1,7 @@
+UPDATE ledger_accounts a
+SET balance = COALESCE((
+    SELECT SUM(p.amount)
+    FROM ledger_postings p
+    WHERE p.account_id = a.id
+), 0)
+WHERE a.kind = 'SYSTEM';
@@ -0,0 +1,5 @@
+-- Балансы системных счетов больше не ведутся в ledger_accounts: каждое
+-- пополнение и списание обновляло бы строку внешнего счёта и выстраивало все
+-- движения денег в очередь за её блокировкой. Баланс системного счёта -
+-- сумма его проводок в ledger_postings.
+UPDATE ledger_accounts SET balance = 0 WHERE kind = 'SYSTEM';
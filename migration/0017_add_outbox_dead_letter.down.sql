@@ -0,0 +1,11 @@
+DROP INDEX idx_outbox_delivered_at;
+DROP INDEX idx_outbox_webhooks_pending;
+DROP INDEX idx_outbox_pending;
+
+CREATE INDEX idx_outbox_pending
+    ON outbox(id)
+    WHERE delivered_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN webhooks_enqueued_at,
+    DROP COLUMN failed_at;
@@ -0,0 +1,23 @@
+-- failed_at отмечает событие, исчерпавшее попытки: оно больше не держит
+-- очередь. webhooks_enqueued_at - отдельный курсор рассылки подпискам
+-- вебхуков, чтобы сбой внешнего publisher их не задерживал. Доставленные
+-- раньше события уже разосланы.
+ALTER TABLE outbox
+    ADD COLUMN failed_at TIMESTAMP,
+    ADD COLUMN webhooks_enqueued_at TIMESTAMP;
+
+UPDATE outbox SET webhooks_enqueued_at = delivered_at WHERE delivered_at IS NOT NULL;
+
+DROP INDEX idx_outbox_pending;
+
+CREATE INDEX idx_outbox_pending
+    ON outbox(id)
+    WHERE delivered_at IS NULL AND failed_at IS NULL;
+
+CREATE INDEX idx_outbox_webhooks_pending
+    ON outbox(id)
+    WHERE webhooks_enqueued_at IS NULL;
+
+CREATE INDEX idx_outbox_delivered_at
+    ON outbox(delivered_at)
+    WHERE delivered_at IS NOT NULL;
@@ -0,0 +1,9 @@
+// Package migration ...
+package migration
+
+import "embed"
+
+// FS ...
+//
+//go:embed *.sql
+var FS embed.FS